@@ -3,9 +3,20 @@
 package wallet
 
 import (
+	"errors"
+
 	"git.zzdats.lv/edim/api-wallet/attestation"
+	"git.zzdats.lv/edim/api-wallet/credential"
+	"git.zzdats.lv/edim/api-wallet/credential/age"
+	"git.zzdats.lv/edim/api-wallet/credential/ehic"
+	"git.zzdats.lv/edim/api-wallet/credential/mdl"
+	"git.zzdats.lv/edim/api-wallet/credential/mdl/portrait"
+	"git.zzdats.lv/edim/api-wallet/credential/pda1"
+	"git.zzdats.lv/edim/api-wallet/credential/pid"
+	"git.zzdats.lv/edim/api-wallet/credential/rtu"
 	"git.zzdats.lv/edim/api-wallet/issuer"
 	"git.zzdats.lv/edim/api-wallet/openid4vci"
+	"git.zzdats.lv/edim/api-wallet/qr"
 	"git.zzdats.lv/edim/api-wallet/tasks"
 	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
 
@@ -27,15 +38,19 @@ type App struct {
 	issuer      *issuer.Issuer
 	attestation *attestation.Service
 	idauth      *idauth.Client
+	qr          *qr.Renderer
+	credentials *credential.Registry
 
-	fprisAPIClient   *FprisAPIClient
-	rtuAPIClient     *RTUAPIClient
-	mdlAPIClient     *MDLAPIClient
 	simpleSignClient *SimpleSignClient
 }
 
 // New returns a new application instance.
 func New(cmd *cobra.Command, version string) (*App, error) {
+	return newApp(cmd, version, nil)
+}
+
+// newApp returns a new application instance using the store or PostgreSQL store if nil.
+func newApp(cmd *cobra.Command, version string, store jsondb.Store) (*App, error) {
 	config := NewConfiguration()
 
 	a, err := server.New(cmd, server.Options{
@@ -47,9 +62,10 @@ func New(cmd *cobra.Command, version string) (*App, error) {
 		return nil, err
 	}
 
-	store, _, err := jsondb.New(a.App, config.Postgres)
-	if err != nil {
-		return nil, err
+	if store == nil {
+		if store, _, err = jsondb.New(a.App, config.Postgres); err != nil {
+			return nil, err
+		}
 	}
 
 	idauth, err := idauth.NewClient(config.IDAuth)
@@ -62,7 +78,19 @@ func New(cmd *cobra.Command, version string) (*App, error) {
 		return nil, err
 	}
 
-	att, err := attestation.New(a)
+	var attOpts []attestation.Option
+
+	if rootCA := config.AttestationTestRootCA; rootCA != "" {
+		if a.Env().IsProduction() {
+			return nil, errors.New("attestation test root CA must not be trusted in production environment")
+		}
+
+		a.Log().Warn("trusting test root CA for key attestations in addition to Google and Apple roots, do not use in production")
+
+		attOpts = append(attOpts, attestation.WithTestRootCA(rootCA))
+	}
+
+	att, err := attestation.New(a, attOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -76,16 +104,61 @@ func New(cmd *cobra.Command, version string) (*App, error) {
 		attestation: att,
 	}
 
-	instance.issuer, err = issuer.NewIssuer(instance, instance.Config().Issuer.TxCodeCacheTTL, instance.Config().WalletPublicURL)
+	instance.issuer, err = issuer.NewIssuer(instance, store, instance.Config().Issuer, instance.Config().WalletPublicURL)
+	if err != nil {
+		return nil, err
+	}
+
+	instance.qr, err = qr.New(instance.Config().QRCodeSize, instance.Config().QRCodeLevel, instance.Config().QRCodeLogoFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ageThresholds, err := age.ParseThresholds(instance.Config().AgeOverThresholds)
 	if err != nil {
 		return nil, err
 	}
 
-	instance.fprisAPIClient = NewFprisAPIClient(instance.Config().FprisAPIURL)
+	upstream := instance.Config().Upstream
+
+	pidSource := pid.New(credential.NewClient("FPRIS", instance.Config().FprisAPIURL, upstream.ClientOptions(upstream.FprisTimeout)))
 
-	instance.rtuAPIClient = NewRTUAPIClient(instance.Config().RTUAPIURL)
+	instance.credentials, err = credential.NewRegistry(
+		pidSource,
+		rtu.New(credential.NewClient("RTU", instance.Config().RTUAPIURL, upstream.ClientOptions(upstream.RTUTimeout))),
+		mdl.New(credential.NewClient("MDL", instance.Config().MDLAPIURL, upstream.ClientOptions(upstream.MDLTimeout)), portrait.Options{
+			MaxSize:      instance.Config().MDLPortraitMaxSize,
+			MaxDimension: instance.Config().MDLPortraitMaxDim,
+		}),
+		age.New(pidSource, ageThresholds),
+	)
+	if err != nil {
+		return nil, err
+	}
 
-	instance.mdlAPIClient = NewMDLAPIClient(instance.Config().MDLAPIURL)
+	// EHIC is issued only when national health service API is configured
+	if instance.Config().EHICAPIURL != "" {
+		if err := instance.credentials.Register(ehic.New(credential.NewClient("EHIC", instance.Config().EHICAPIURL, upstream.ClientOptions(upstream.EHICTimeout)))); err != nil {
+			return nil, err
+		}
+	}
+
+	// PDA1 is issued only when social security API is configured
+	if instance.Config().PDA1APIURL != "" {
+		if err := instance.credentials.Register(pda1.New(credential.NewClient("PDA1", instance.Config().PDA1APIURL, upstream.ClientOptions(upstream.PDA1Timeout)))); err != nil {
+			return nil, err
+		}
+	}
+
+	// Caching of the source registry data is opt-in
+	if upstream.CacheTTL > 0 {
+		formCache, err := credential.NewCache(instance.Cache(), upstream.CacheSecret, upstream.CacheTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		instance.credentials.SetCache(formCache)
+	}
 
 	instance.simpleSignClient, err = NewSimpleSignClient(instance, instance.Config().SimpleSignService, instance.Config().SimpleSignPublicURL, instance.Config().SimpleSignAPIKey, instance.Config().SimpleSignCacheTTL)
 	if err != nil {
@@ -137,16 +210,13 @@ func (a *App) OpenID4VCI() *openid4vci.Service {
 	return a.vci
 }
 
-func (a *App) FprisAPIClient() *FprisAPIClient {
-	return a.fprisAPIClient
-}
-
-func (a *App) RTUAPIClient() *RTUAPIClient {
-	return a.rtuAPIClient
+func (a *App) QRCode() *qr.Renderer {
+	return a.qr
 }
 
-func (a *App) MDLAPIClient() *MDLAPIClient {
-	return a.mdlAPIClient
+// Credentials returns registry of the credential sources.
+func (a *App) Credentials() *credential.Registry {
+	return a.credentials
 }
 
 func (a *App) SimpleSignClient() *SimpleSignClient {
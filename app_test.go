@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package wallet
+
+import (
+	"testing"
+
+	"git.zzdats.lv/edim/api-wallet/attestation/attestationtest"
+	"git.zzdats.lv/edim/api-wallet/mock/jsondbtest"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestNewApp_AttestationTestRootCA(t *testing.T) {
+	ca, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	setTestEnv(t)
+	t.Setenv("ATTESTATION_TEST_ROOT_CA", ca.CertificatePEM())
+
+	_, err = newApp(nil, "1.0.0-test", jsondbtest.New())
+	qt.Check(t, qt.IsNil(err))
+
+	// Test root CA must never be trusted in production
+	t.Setenv("ENVIRONMENT", "Production")
+
+	_, err = newApp(nil, "1.0.0-test", jsondbtest.New())
+	qt.Check(t, qt.ErrorMatches(err, "attestation test root CA must not be trusted in production environment"))
+}
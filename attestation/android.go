@@ -10,6 +10,7 @@ import (
 	"encoding/asn1"
 	"encoding/base64"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"time"
 
@@ -54,7 +55,15 @@ func (a *Service) verifyAndroid(att string, challenge []byte, tag string, now ti
 		}
 	}
 
-	cert, interms, err := a.verifyCert(s.AttStmt.X5c, androidAppAttestRootCA, now)
+	if s.AttStmt == nil {
+		return nil, azugo.ParamInvalidError{
+			Name: "key_attestation",
+			Tag:  "invalid",
+			Err:  errors.New("attestation statement missing"),
+		}
+	}
+
+	cert, interms, err := a.verifyCert(s.AttStmt.X5c, a.rootCAs(a.androidRootCA), now)
 	if err != nil {
 		return nil, azugo.ParamInvalidError{
 			Name: "certificate",
@@ -78,8 +78,10 @@ const (
 	Failed
 )
 
+// provisioningInfo is the CBOR map of the Android provisioning information extension.
+// Map keys are integers, so the field must be decoded with `keyasint` as text key "1" never matches.
 type provisioningInfo struct {
-	CertsIssued int `cbor:"1"` // '1' corresponds to the OID field
+	CertsIssued int `cbor:"1,keyasint"` // '1' corresponds to the OID field
 }
 
 type androidAttestation struct {
@@ -3,22 +3,277 @@
 package attestation
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
 	"testing"
 	"time"
 
+	"git.zzdats.lv/edim/api-wallet/attestation/attestationtest"
+
 	"azugo.io/azugo"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/go-quicktest/qt"
 )
 
+// androidDeviceAttestation is the key attestation captured from a real Android device with StrongBox.
+const androidDeviceAttestation = "omNmbXRrYW5kcm9pZC1rZXlnYXR0U3RtdKNjYWxnJmNzaWdYSDBGAiEA_qHxKWVOoDBjn02YZd_qWwbNAWGTwo-AqBcCtAt0SHsCIQC_JfR7MvjNh-re0-cXFA-Wq1AiIWKOcHzP8kd9Vg7vMmN4NWOEWQKzMIICrzCCAlWgAwIBAgIBATAKBggqhkjOPQQDAjA_MRIwEAYDVQQMDAlTdHJvbmdCb3gxKTAnBgNVBAUTIDQ2OTcwMGM3MzBlZGFjNjBjNzI1ZmU4NzU3YmZiODZlMB4XDTI1MDIxODE4NTYxNloXDTI2MDIxODE4NTYxNlowHzEdMBsGA1UEAxMUQW5kcm9pZCBLZXlzdG9yZSBLZXkwWTATBgcqhkjOPQIBBggqhkjOPQMBBwNCAASN6t4YPdQAPWnpaPK1rL31xvp8fbdtJQgsfP200NNoO8wq6N89cfhoyFfsoqr7a5a53vp69GDRFM2w_MUtkvdpo4IBYDCCAVwwDAYDVR0PBAUDAweAADCCAUoGCisGAQQB1nkCAREEggE6MIIBNgIBZAoBAgIBZAoBAgQDYWJjBAAwer-DEAgCBgGVGmsHNL-DEQgCBgGcchwzNL-DEggCBgGcchwzNL-FPQgCBgGVGmsHNb-FRUYERDBCMRwwGgQUbHYubHZydGMuZWRpbS56ei5kZXYCAicQMSIEIHo0xfGXgoc-2NAnhThgQBtIMxheNOtyqMR0ZWXeHs9pMIGkoQgxBgIBAwIBAqIDAgEDowQCAgEApQUxAwIBBKoDAgEBv4N3AgUAv4U-AwIBAL-FQEwwSgQgQkvpeXE1DthTkC9_vIF70MjFxdxsFF0Ixj1AjScZwN4BAf8KAQAEIHRm6oIAiSfd_9lsxMV72Le5ejPSFpaAKB1_y42luH7Iv4VBBQIDAiLgv4VCBQIDAxarv4VOBgIEATTazb-FTwYCBAE02s0wCgYIKoZIzj0EAwIDSAAwRQIgF91CgD5DB-uBJYPFaIspQqKjyTD6vVjXeN7MXVg4r4MCIQCPqhiMHe_ZLFI7LvhOly2x41_hA6M29YdmGxEsdR_IEVkCBDCCAgAwggGGoAMCAQICEQDHsKuYr41erXjsWuGSlbO2MAoGCCqGSM49BAMCMD8xEjAQBgNVBAwMCVN0cm9uZ0JveDEpMCcGA1UEBRMgMTg0ZGQ0YTdkOGE3MTMxZTk4YWFlNGVhN2FmNTEwZGEwHhcNMjEwOTE1MjI1ODE1WhcNMzEwOTEzMjI1ODE1WjA_MRIwEAYDVQQMDAlTdHJvbmdCb3gxKTAnBgNVBAUTIDQ2OTcwMGM3MzBlZGFjNjBjNzI1ZmU4NzU3YmZiODZlMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE_LhLt3OQDb4qMVkhvogX1HmKLKC4LAfi23lS80Q1kZLI3L3d1Nk_hC2guAhpvoNHDcKMDbR_Xx9fbMdld50u7qNjMGEwHQYDVR0OBBYEFHEq3XnTxj2UK1chepWt-5apDceUMB8GA1UdIwQYMBaAFEjpz2kmConExYRJnG262EblomazMA8GA1UdEwEB_wQFMAMBAf8wDgYDVR0PAQH_BAQDAgIEMAoGCCqGSM49BAMCA2gAMGUCMQDh_9Qd6EBNIkF7UDp-hrWe3LuLszfR0jm_-OTiTNDEjY9RmI_QeSFkFdP782gRazQCMCq2jl0hcpL83PxWiBKrABDi-FD_gvmYPFcf92QrY0JoZ2WaekkUQGSNI3zXjve1vVkDnTCCA5kwggGBoAMCAQICEF9kGJe2hh1ZlKIOgLoqewgwDQYJKoZIhvcNAQELBQAwGzEZMBcGA1UEBRMQZjkyMDA5ZTg1M2I2YjA0NTAeFw0yMTA5MTUyMjU3MzBaFw0zMTA5MTMyMjU3MzBaMD8xEjAQBgNVBAwMCVN0cm9uZ0JveDEpMCcGA1UEBRMgMTg0ZGQ0YTdkOGE3MTMxZTk4YWFlNGVhN2FmNTEwZGEwdjAQBgcqhkjOPQIBBgUrgQQAIgNiAATiQmNMO1muExZi4Jn-wnLscg12Qs3MBC9fYFz_3b5zs2gi0hgf0naEr8JbpZmD76kaYPkBoAl1RRY6dlTGYvYukFnZfMFOWS1e_GnsJcrDzL2pA_1RGxxLE4JdLxnpKlmjYzBhMB0GA1UdDgQWBBRI6c9pJgqJxMWESZxtuthG5aJmszAfBgNVHSMEGDAWgBQ2YeEAfIgFCVGLRGxH_xpMyepPEjAPBgNVHRMBAf8EBTADAQH_MA4GA1UdDwEB_wQEAwICBDANBgkqhkiG9w0BAQsFAAOCAgEASTHdEvv2J-ibDM4nLoOwBxygU4tC2AAeNPjdxxD7ojfHBANhUgdp_aEKF1AdtxDcGJTMvx__b0vO3_CncI_mtA-kVrn1KNRtzASiFnt8Ew9kmsHxdWeUQ4sxXRWHf-eQJT8T5AI5ebsGiLxEr58J7u4aTZ8DOfNQj2ibokAmx-WeW1J3QHYvqH521wtqskrU-y8tfdSsTQtpImqaihJKO_5TGfFsUwrsJQhTfexy6nvJ0LdetMzMcIRJEtgImlayHW1koa5Vw65sX5o6i77V0MZFAQcQgvHfM5l7vFvbFGH89e6z_8OfR9FbJNO905BJY7Nxl7Z3PP4xS5SmU_aRulKUz23xELYnEA0Vefe274s7ZD0GBuByhp3nzm1db94f9-5gwlGw9jPLICA_6CQ4V7D7zs_NkvjdbbMapQIsaV-pEvTeF4LixCwyqo579PjSYmzBUTNocf3ln4XOZqGTp6LY41Ke8Doh7kvbwogHcoMc8PpTowHxHvoZUeqspcWndA0ateIGtBGyfyPNk_qxDg7kDAjvYfuNGOUh64Z_wWnmSvi-bnn7WSPHBlQb_kTPdr96Z3TztLnouYduykob2JZJCFb2HxZ40GwXba_CKO63lwUrSyTTIIeeHuUYH3Y6QGHPd_m1A0qM4xfqfOG40QBh0U05rPj9tGPbzUSsVSRZBSAwggUcMIIDBKADAgECAgkA1Q_yW6Py1rMwDQYJKoZIhvcNAQELBQAwGzEZMBcGA1UEBRMQZjkyMDA5ZTg1M2I2YjA0NTAeFw0xOTExMjIyMDM3NThaFw0zNDExMTgyMDM3NThaMBsxGTAXBgNVBAUTEGY5MjAwOWU4NTNiNmIwNDUwggIiMA0GCSqGSIb3DQEBAQUAA4ICDwAwggIKAoICAQCvtseCK7GnAewrtC6LzFQWY6vvmC8yx391MQMMl1JLG1_oCfvHKqlFH3Q8vZpvEzV0SqVed_a2rDU17hfCXmOVF92ckuY3SlPL_iWPj_u2_RKTeKIqTKmcRS1HpZ8yAfRBl8oczX52L7L1MVG2_rL__Stv5P5bxr2ew0v-CCOdqvzrjrWo7Ss6zZxeOneQ4bUUQnkxWYWYEa2esqlrvdelfJOpHEH8zSfWf9b2caoLgVJhrThPo3lEhkYE3bPYxPkgoZsWVsLxStbQPFbsBgiZBBwe0aX-bTRAtVa60dChUlicU-VdNwdi8BIu75GGGxsObEyAknSZwOm-wLg-O8H5PHLASWBLvS8TReYsP44m2-wGyUdm88EoI51PQxL62BI4h-Br7PVnWDv4NVqB_uq6-ZqDyN8-KjIq_Gcr8SCxNRWLaCHOrzCbbu53-YgzsBjaoQ5FHwajdNUHgfNZCClmu3eLkwiUJpjnTgvNJGKKAcLMA-UfCz5bSsHk356vn_akkqd8FIOIKIUBW0Is5nuAuIybSOE7YHq1Rccj_4xE-PLTaLn2Ug0xFF6_noYq1x32o7_SRQlZ1lN0DZehLzaLE-9m1dClSm4vXZpv70RoMrxnhEclhh8JPdDm80BdqJZD7w9NabZCAFH9uTBJZz42lQWA0830-9CLxYSDlSYAYwIDAQABo2MwYTAdBgNVHQ4EFgQUNmHhAHyIBQlRi0RsR_8aTMnqTxIwHwYDVR0jBBgwFoAUNmHhAHyIBQlRi0RsR_8aTMnqTxIwDwYDVR0TAQH_BAUwAwEB_zAOBgNVHQ8BAf8EBAMCAgQwDQYJKoZIhvcNAQELBQADggIBAE4xoFzyi6Zdva-hztcJae5cqEEErd7YowbPf23uUDdddF7ZkssCQsznLcnu1RGR_lrVK61907JcCZ4TpJGjzdSHpazOh2YyTErkYzgkaue3ikGKy7mKBcTJ1pbuqrYJ0LoM4aMb6YSQ3z9MDqndyegv-w_LPp692MuVJ4nysUEfrFbIhkJutylgQnNdpQ4RrHFfGBjPn9xOJUo3YzUbaiRAFQhhJjpuMQvhpQ3lx-juiA_dS-WISjcSjRiDC7NHa_QpHoLVxmpklJOeCEgL-8APfYp01D5zc36-XY5OxRUwLUaJaSeA3HU47X6Rdb5hOedNQ604izBQ_9Wp3lJiAAiYwB9jxT3-IiCRCPpPZboWxJzL3gg318WETVS3OYugEi5QWxVckxPP4m5y2H4iqhYW5r2_VH3f-T3ynjWmO0Vf4fwOyVWB8_T3u-O7goOWo3rjFXWCvDdkuXgKI578D3Wh4ubZQc6rrCfd6wHivYQhApvqNNUa7mxgJx1alevQBRWpwAE92Av4fuomC4HDT2iObrE0ivDY6hysMqy52T-iSv8DCoTI8rD1acyVCAsgrDWs4MbY29T2hHcZUZ0yRQFm60vxW4WQRFAa3q9DY4LDSxXjtUyS5htpwr_HJkWJFys8k9vjXOBtCP1cATIsoId7HRJ0OvH61ZQOobwC3Ykc"
+
 func TestAndroidAttestation(t *testing.T) {
 	app := azugo.NewTestApp()
 
 	s, err := New(app.App)
 	qt.Assert(t, qt.IsNil(err))
 
-	r, err := s.verifyAndroid("omNmbXRrYW5kcm9pZC1rZXlnYXR0U3RtdKNjYWxnJmNzaWdYSDBGAiEA_qHxKWVOoDBjn02YZd_qWwbNAWGTwo-AqBcCtAt0SHsCIQC_JfR7MvjNh-re0-cXFA-Wq1AiIWKOcHzP8kd9Vg7vMmN4NWOEWQKzMIICrzCCAlWgAwIBAgIBATAKBggqhkjOPQQDAjA_MRIwEAYDVQQMDAlTdHJvbmdCb3gxKTAnBgNVBAUTIDQ2OTcwMGM3MzBlZGFjNjBjNzI1ZmU4NzU3YmZiODZlMB4XDTI1MDIxODE4NTYxNloXDTI2MDIxODE4NTYxNlowHzEdMBsGA1UEAxMUQW5kcm9pZCBLZXlzdG9yZSBLZXkwWTATBgcqhkjOPQIBBggqhkjOPQMBBwNCAASN6t4YPdQAPWnpaPK1rL31xvp8fbdtJQgsfP200NNoO8wq6N89cfhoyFfsoqr7a5a53vp69GDRFM2w_MUtkvdpo4IBYDCCAVwwDAYDVR0PBAUDAweAADCCAUoGCisGAQQB1nkCAREEggE6MIIBNgIBZAoBAgIBZAoBAgQDYWJjBAAwer-DEAgCBgGVGmsHNL-DEQgCBgGcchwzNL-DEggCBgGcchwzNL-FPQgCBgGVGmsHNb-FRUYERDBCMRwwGgQUbHYubHZydGMuZWRpbS56ei5kZXYCAicQMSIEIHo0xfGXgoc-2NAnhThgQBtIMxheNOtyqMR0ZWXeHs9pMIGkoQgxBgIBAwIBAqIDAgEDowQCAgEApQUxAwIBBKoDAgEBv4N3AgUAv4U-AwIBAL-FQEwwSgQgQkvpeXE1DthTkC9_vIF70MjFxdxsFF0Ixj1AjScZwN4BAf8KAQAEIHRm6oIAiSfd_9lsxMV72Le5ejPSFpaAKB1_y42luH7Iv4VBBQIDAiLgv4VCBQIDAxarv4VOBgIEATTazb-FTwYCBAE02s0wCgYIKoZIzj0EAwIDSAAwRQIgF91CgD5DB-uBJYPFaIspQqKjyTD6vVjXeN7MXVg4r4MCIQCPqhiMHe_ZLFI7LvhOly2x41_hA6M29YdmGxEsdR_IEVkCBDCCAgAwggGGoAMCAQICEQDHsKuYr41erXjsWuGSlbO2MAoGCCqGSM49BAMCMD8xEjAQBgNVBAwMCVN0cm9uZ0JveDEpMCcGA1UEBRMgMTg0ZGQ0YTdkOGE3MTMxZTk4YWFlNGVhN2FmNTEwZGEwHhcNMjEwOTE1MjI1ODE1WhcNMzEwOTEzMjI1ODE1WjA_MRIwEAYDVQQMDAlTdHJvbmdCb3gxKTAnBgNVBAUTIDQ2OTcwMGM3MzBlZGFjNjBjNzI1ZmU4NzU3YmZiODZlMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE_LhLt3OQDb4qMVkhvogX1HmKLKC4LAfi23lS80Q1kZLI3L3d1Nk_hC2guAhpvoNHDcKMDbR_Xx9fbMdld50u7qNjMGEwHQYDVR0OBBYEFHEq3XnTxj2UK1chepWt-5apDceUMB8GA1UdIwQYMBaAFEjpz2kmConExYRJnG262EblomazMA8GA1UdEwEB_wQFMAMBAf8wDgYDVR0PAQH_BAQDAgIEMAoGCCqGSM49BAMCA2gAMGUCMQDh_9Qd6EBNIkF7UDp-hrWe3LuLszfR0jm_-OTiTNDEjY9RmI_QeSFkFdP782gRazQCMCq2jl0hcpL83PxWiBKrABDi-FD_gvmYPFcf92QrY0JoZ2WaekkUQGSNI3zXjve1vVkDnTCCA5kwggGBoAMCAQICEF9kGJe2hh1ZlKIOgLoqewgwDQYJKoZIhvcNAQELBQAwGzEZMBcGA1UEBRMQZjkyMDA5ZTg1M2I2YjA0NTAeFw0yMTA5MTUyMjU3MzBaFw0zMTA5MTMyMjU3MzBaMD8xEjAQBgNVBAwMCVN0cm9uZ0JveDEpMCcGA1UEBRMgMTg0ZGQ0YTdkOGE3MTMxZTk4YWFlNGVhN2FmNTEwZGEwdjAQBgcqhkjOPQIBBgUrgQQAIgNiAATiQmNMO1muExZi4Jn-wnLscg12Qs3MBC9fYFz_3b5zs2gi0hgf0naEr8JbpZmD76kaYPkBoAl1RRY6dlTGYvYukFnZfMFOWS1e_GnsJcrDzL2pA_1RGxxLE4JdLxnpKlmjYzBhMB0GA1UdDgQWBBRI6c9pJgqJxMWESZxtuthG5aJmszAfBgNVHSMEGDAWgBQ2YeEAfIgFCVGLRGxH_xpMyepPEjAPBgNVHRMBAf8EBTADAQH_MA4GA1UdDwEB_wQEAwICBDANBgkqhkiG9w0BAQsFAAOCAgEASTHdEvv2J-ibDM4nLoOwBxygU4tC2AAeNPjdxxD7ojfHBANhUgdp_aEKF1AdtxDcGJTMvx__b0vO3_CncI_mtA-kVrn1KNRtzASiFnt8Ew9kmsHxdWeUQ4sxXRWHf-eQJT8T5AI5ebsGiLxEr58J7u4aTZ8DOfNQj2ibokAmx-WeW1J3QHYvqH521wtqskrU-y8tfdSsTQtpImqaihJKO_5TGfFsUwrsJQhTfexy6nvJ0LdetMzMcIRJEtgImlayHW1koa5Vw65sX5o6i77V0MZFAQcQgvHfM5l7vFvbFGH89e6z_8OfR9FbJNO905BJY7Nxl7Z3PP4xS5SmU_aRulKUz23xELYnEA0Vefe274s7ZD0GBuByhp3nzm1db94f9-5gwlGw9jPLICA_6CQ4V7D7zs_NkvjdbbMapQIsaV-pEvTeF4LixCwyqo579PjSYmzBUTNocf3ln4XOZqGTp6LY41Ke8Doh7kvbwogHcoMc8PpTowHxHvoZUeqspcWndA0ateIGtBGyfyPNk_qxDg7kDAjvYfuNGOUh64Z_wWnmSvi-bnn7WSPHBlQb_kTPdr96Z3TztLnouYduykob2JZJCFb2HxZ40GwXba_CKO63lwUrSyTTIIeeHuUYH3Y6QGHPd_m1A0qM4xfqfOG40QBh0U05rPj9tGPbzUSsVSRZBSAwggUcMIIDBKADAgECAgkA1Q_yW6Py1rMwDQYJKoZIhvcNAQELBQAwGzEZMBcGA1UEBRMQZjkyMDA5ZTg1M2I2YjA0NTAeFw0xOTExMjIyMDM3NThaFw0zNDExMTgyMDM3NThaMBsxGTAXBgNVBAUTEGY5MjAwOWU4NTNiNmIwNDUwggIiMA0GCSqGSIb3DQEBAQUAA4ICDwAwggIKAoICAQCvtseCK7GnAewrtC6LzFQWY6vvmC8yx391MQMMl1JLG1_oCfvHKqlFH3Q8vZpvEzV0SqVed_a2rDU17hfCXmOVF92ckuY3SlPL_iWPj_u2_RKTeKIqTKmcRS1HpZ8yAfRBl8oczX52L7L1MVG2_rL__Stv5P5bxr2ew0v-CCOdqvzrjrWo7Ss6zZxeOneQ4bUUQnkxWYWYEa2esqlrvdelfJOpHEH8zSfWf9b2caoLgVJhrThPo3lEhkYE3bPYxPkgoZsWVsLxStbQPFbsBgiZBBwe0aX-bTRAtVa60dChUlicU-VdNwdi8BIu75GGGxsObEyAknSZwOm-wLg-O8H5PHLASWBLvS8TReYsP44m2-wGyUdm88EoI51PQxL62BI4h-Br7PVnWDv4NVqB_uq6-ZqDyN8-KjIq_Gcr8SCxNRWLaCHOrzCbbu53-YgzsBjaoQ5FHwajdNUHgfNZCClmu3eLkwiUJpjnTgvNJGKKAcLMA-UfCz5bSsHk356vn_akkqd8FIOIKIUBW0Is5nuAuIybSOE7YHq1Rccj_4xE-PLTaLn2Ug0xFF6_noYq1x32o7_SRQlZ1lN0DZehLzaLE-9m1dClSm4vXZpv70RoMrxnhEclhh8JPdDm80BdqJZD7w9NabZCAFH9uTBJZz42lQWA0830-9CLxYSDlSYAYwIDAQABo2MwYTAdBgNVHQ4EFgQUNmHhAHyIBQlRi0RsR_8aTMnqTxIwHwYDVR0jBBgwFoAUNmHhAHyIBQlRi0RsR_8aTMnqTxIwDwYDVR0TAQH_BAUwAwEB_zAOBgNVHQ8BAf8EBAMCAgQwDQYJKoZIhvcNAQELBQADggIBAE4xoFzyi6Zdva-hztcJae5cqEEErd7YowbPf23uUDdddF7ZkssCQsznLcnu1RGR_lrVK61907JcCZ4TpJGjzdSHpazOh2YyTErkYzgkaue3ikGKy7mKBcTJ1pbuqrYJ0LoM4aMb6YSQ3z9MDqndyegv-w_LPp692MuVJ4nysUEfrFbIhkJutylgQnNdpQ4RrHFfGBjPn9xOJUo3YzUbaiRAFQhhJjpuMQvhpQ3lx-juiA_dS-WISjcSjRiDC7NHa_QpHoLVxmpklJOeCEgL-8APfYp01D5zc36-XY5OxRUwLUaJaSeA3HU47X6Rdb5hOedNQ604izBQ_9Wp3lJiAAiYwB9jxT3-IiCRCPpPZboWxJzL3gg318WETVS3OYugEi5QWxVckxPP4m5y2H4iqhYW5r2_VH3f-T3ynjWmO0Vf4fwOyVWB8_T3u-O7goOWo3rjFXWCvDdkuXgKI578D3Wh4ubZQc6rrCfd6wHivYQhApvqNNUa7mxgJx1alevQBRWpwAE92Av4fuomC4HDT2iObrE0ivDY6hysMqy52T-iSv8DCoTI8rD1acyVCAsgrDWs4MbY29T2hHcZUZ0yRQFm60vxW4WQRFAa3q9DY4LDSxXjtUyS5htpwr_HJkWJFys8k9vjXOBtCP1cATIsoId7HRJ0OvH61ZQOobwC3Ykc", []byte("abc"), "5RZFt5xRDoFXBZEc+pM9aDT7p2kW0VkSWdY4JHyUcG4=", time.Date(2025, 0o2, 20, 10, 0, 0, 0, time.UTC))
+	r, err := s.verifyAndroid(androidDeviceAttestation, []byte("abc"), "5RZFt5xRDoFXBZEc+pM9aDT7p2kW0VkSWdY4JHyUcG4=", time.Date(2025, 0o2, 20, 10, 0, 0, 0, time.UTC))
 	qt.Assert(t, qt.IsNil(err))
 	qt.Check(t, qt.Equals(r.HardwareKeyTag, "5RZFt5xRDoFXBZEc+pM9aDT7p2kW0VkSWdY4JHyUcG4="))
 	qt.Check(t, qt.Equals(r.CertsIssued, 0))
 	qt.Check(t, qt.Equals(r.PublicKey, "-----BEGIN PUBLIC KEY-----\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEjereGD3UAD1p6Wjytay99cb6fH23\nbSUILHz9tNDTaDvMKujfPXH4aMhX7KKq+2uWud76evRg0RTNsPzFLZL3aQ==\n-----END PUBLIC KEY-----\n"))
 }
+
+func testService(t *testing.T, ca *attestationtest.CA) *Service {
+	t.Helper()
+
+	app := azugo.NewTestApp()
+
+	s, err := New(app.App, WithAndroidRootCA(ca.PublicKeyPEM()), WithAppleRootCA(ca.CertificatePEM()))
+	qt.Assert(t, qt.IsNil(err))
+
+	return s
+}
+
+// checkParamInvalid checks that the error is invalid parameter error with the name and tag.
+func checkParamInvalid(t *testing.T, err error, name, tag string) {
+	t.Helper()
+
+	var perr azugo.ParamInvalidError
+
+	qt.Assert(t, qt.IsTrue(errors.As(err, &perr)), qt.Commentf("error: %v", err))
+	qt.Check(t, qt.Equals(perr.Name, name))
+	qt.Check(t, qt.Equals(perr.Tag, tag))
+}
+
+func TestAndroidAttestationGenerated(t *testing.T) {
+	ca, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	other, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	now := time.Now()
+	challenge := []byte("challenge")
+
+	tests := []struct {
+		name        string
+		ca          *attestationtest.CA
+		opts        attestationtest.AndroidOptions
+		challenge   []byte
+		tag         string
+		now         time.Time
+		certsIssued int
+		errName     string
+		errTag      string
+	}{
+		{
+			name: "strongbox",
+		},
+		{
+			name:        "provisioning info",
+			opts:        attestationtest.AndroidOptions{CertsIssued: 3},
+			certsIssued: 3,
+		},
+		{
+			name:    "tee",
+			opts:    attestationtest.AndroidOptions{SecurityLevel: attestationtest.SecurityLevelTEE},
+			errName: "certificate",
+			errTag:  "insecure",
+		},
+		{
+			name:      "challenge mismatch",
+			challenge: []byte("other"),
+			errName:   "challenge",
+			errTag:    "invalid",
+		},
+		{
+			name:    "hardware key tag mismatch",
+			tag:     base64.StdEncoding.EncodeToString(make([]byte, 32)),
+			errName: "hardware_key_tag",
+			errTag:  "invalid",
+		},
+		{
+			name:    "hardware key tag not base64",
+			tag:     "not base64!",
+			errName: "hardware_key_tag",
+			errTag:  "invalid",
+		},
+		{
+			name:    "missing key description",
+			opts:    attestationtest.AndroidOptions{OmitKeyDescription: true},
+			errName: "certificate:extension",
+			errTag:  "not_found",
+		},
+		{
+			name:    "expired certificate",
+			now:     now.Add(48 * time.Hour),
+			errName: "certificate",
+			errTag:  "invalid",
+		},
+		{
+			name:    "untrusted root",
+			ca:      other,
+			errName: "certificate",
+			errTag:  "invalid",
+		},
+	}
+
+	s := testService(t, ca)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.ca == nil {
+				tt.ca = ca
+			}
+
+			if tt.challenge == nil {
+				tt.challenge = challenge
+			}
+
+			if tt.now.IsZero() {
+				tt.now = now
+			}
+
+			opts := tt.opts
+			opts.Challenge = challenge
+
+			att, err := tt.ca.Android(opts)
+			qt.Assert(t, qt.IsNil(err))
+
+			if tt.tag == "" {
+				tt.tag = att.HardwareKeyTag
+			}
+
+			r, err := s.verifyAndroid(att.Attestation, tt.challenge, tt.tag, tt.now)
+			if tt.errName != "" {
+				checkParamInvalid(t, err, tt.errName, tt.errTag)
+
+				return
+			}
+
+			qt.Assert(t, qt.IsNil(err))
+			qt.Check(t, qt.Equals(r.DeviceType, "android"))
+			qt.Check(t, qt.Equals(r.HardwareKeyTag, att.HardwareKeyTag))
+			qt.Check(t, qt.Equals(r.CertsIssued, tt.certsIssued))
+			qt.Check(t, qt.Equals(r.PublicKey, publicKeyPEM(t, &att.Key.PublicKey)))
+		})
+	}
+}
+
+func TestAndroidAttestationKeyDescription(t *testing.T) {
+	ca, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	att, err := ca.Android(attestationtest.AndroidOptions{
+		Challenge:      []byte("challenge"),
+		PackageID:      "lv.lvrtc.edim.test",
+		BootState:      attestationtest.BootUnverified,
+		DeviceUnlocked: true,
+	})
+	qt.Assert(t, qt.IsNil(err))
+
+	buf, err := base64.RawURLEncoding.DecodeString(att.Attestation)
+	qt.Assert(t, qt.IsNil(err))
+
+	a := androidAttestation{}
+	qt.Assert(t, qt.IsNil(cbor.Unmarshal(buf, &a)))
+
+	cert, err := x509.ParseCertificate(a.AttStmt.X5c[0])
+	qt.Assert(t, qt.IsNil(err))
+
+	var ext []byte
+
+	for _, e := range cert.Extensions {
+		if e.Id.Equal([]int{1, 3, 6, 1, 4, 1, 11129, 2, 1, 17}) {
+			ext = e.Value
+		}
+	}
+
+	decoded := keyDescription{}
+
+	_, err = asn1.Unmarshal(ext, &decoded)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(decoded.AttestationChallenge, []byte("challenge")))
+	qt.Check(t, qt.Equals(decoded.AttestationSecurityLevel, asn1.Enumerated(attestationtest.SecurityLevelStrongBox)))
+	qt.Check(t, qt.IsTrue(bytes.Contains(ext, []byte("lv.lvrtc.edim.test"))))
+
+	// Root of trust is decoded separately as it is not used by the verification
+	tee := struct {
+		AttestationVersion       int
+		AttestationSecurityLevel asn1.Enumerated
+		KeymasterVersion         int
+		KeymasterSecurityLevel   asn1.Enumerated
+		AttestationChallenge     []byte
+		UniqueID                 []byte
+		SoftwareEnforced         asn1.RawValue
+		TeeEnforced              struct {
+			Purpose     []int `asn1:"tag:1,explicit,set,optional"`
+			Algorithm   int   `asn1:"tag:2,explicit,optional"`
+			KeySize     int   `asn1:"tag:3,explicit,optional"`
+			EcCurve     int   `asn1:"tag:10,explicit,optional"`
+			Origin      int   `asn1:"tag:702,explicit,optional"`
+			RootOfTrust struct {
+				VerifiedBootKey   []byte
+				DeviceLocked      bool
+				VerifiedBootState asn1.Enumerated
+				VerifiedBootHash  []byte
+			} `asn1:"tag:704,explicit"`
+		}
+	}{}
+
+	_, err = asn1.Unmarshal(ext, &tee)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(tee.TeeEnforced.RootOfTrust.VerifiedBootState, asn1.Enumerated(Unverified)))
+	qt.Check(t, qt.IsFalse(tee.TeeEnforced.RootOfTrust.DeviceLocked))
+}
+
+func publicKeyPEM(t *testing.T, key *ecdsa.PublicKey) string {
+	t.Helper()
+
+	buf, err := x509.MarshalPKIXPublicKey(key)
+	qt.Assert(t, qt.IsNil(err))
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: buf,
+	}))
+}
+
+func TestAndroidProvisioningInfo(t *testing.T) {
+	// Provisioning information uses integer map keys
+	buf, err := cbor.Marshal(map[int]int{1: 5})
+	qt.Assert(t, qt.IsNil(err))
+
+	prov := provisioningInfo{}
+	qt.Assert(t, qt.IsNil(cbor.Unmarshal(buf, &prov)))
+	qt.Check(t, qt.Equals(prov.CertsIssued, 5))
+
+	ca, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	s := testService(t, ca)
+
+	att, err := ca.Android(attestationtest.AndroidOptions{
+		Challenge:   []byte("challenge"),
+		CertsIssued: 2,
+	})
+	qt.Assert(t, qt.IsNil(err))
+
+	r, err := s.verifyAndroid(att.Attestation, []byte("challenge"), att.HardwareKeyTag, time.Now())
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.IsTrue(r.CertsIssued > 0))
+	qt.Check(t, qt.Equals(r.CertsIssued, 2))
+}
@@ -14,12 +14,58 @@ import (
 
 type Service struct {
 	app *azugo.App
+
+	androidRootCA string
+	appleRootCA   string
+	testRootCA    string
+}
+
+// Option configures attestation service.
+type Option func(s *Service)
+
+// WithAndroidRootCA overrides Android key attestation root public key or certificate in PEM format.
+func WithAndroidRootCA(rootCA string) Option {
+	return func(s *Service) {
+		s.androidRootCA = rootCA
+	}
 }
 
-func New(app *azugo.App) (*Service, error) {
-	return &Service{
-		app: app,
-	}, nil
+// WithAppleRootCA overrides Apple App Attest root certificate in PEM format.
+func WithAppleRootCA(rootCA string) Option {
+	return func(s *Service) {
+		s.appleRootCA = rootCA
+	}
+}
+
+// WithTestRootCA adds test root certificate in PEM format that is trusted for both Android and Apple
+// attestations in addition to the platform root certificates.
+func WithTestRootCA(rootCA string) Option {
+	return func(s *Service) {
+		s.testRootCA = rootCA
+	}
+}
+
+// rootCAs returns trusted root certificates or public keys of the platform.
+func (a *Service) rootCAs(platformRootCA string) []string {
+	if a.testRootCA == "" {
+		return []string{platformRootCA}
+	}
+
+	return []string{platformRootCA, a.testRootCA}
+}
+
+func New(app *azugo.App, opts ...Option) (*Service, error) {
+	s := &Service{
+		app:           app,
+		androidRootCA: androidAppAttestRootCA,
+		appleRootCA:   appleAppAttestRootCA,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
 }
 
 type Result struct {
@@ -30,6 +76,10 @@ type Result struct {
 }
 
 func (a *Service) Verify(att string, challenge []byte, tag string) (*Result, error) {
+	return a.verify(att, challenge, tag, time.Now())
+}
+
+func (a *Service) verify(att string, challenge []byte, tag string, now time.Time) (*Result, error) {
 	buf, err := base64.RawURLEncoding.DecodeString(att)
 	if err != nil {
 		return nil, err
@@ -46,9 +96,9 @@ func (a *Service) Verify(att string, challenge []byte, tag string) (*Result, err
 
 	switch af.Format {
 	case "android-key", "android":
-		return a.verifyAndroid(att, challenge, tag, time.Now())
+		return a.verifyAndroid(att, challenge, tag, now)
 	case "apple-appattest", "apple":
-		return a.verifyIOS(att, challenge, tag, time.Now())
+		return a.verifyIOS(att, challenge, tag, now)
 	default:
 		return nil, fmt.Errorf("unknown attestation format: %s", af.Format)
 	}
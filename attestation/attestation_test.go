@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package attestation
+
+import (
+	"testing"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/attestation/attestationtest"
+
+	"azugo.io/azugo"
+	"github.com/go-quicktest/qt"
+)
+
+func TestTestRootCA(t *testing.T) {
+	ca, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	app := azugo.NewTestApp()
+
+	s, err := New(app.App, WithTestRootCA(ca.CertificatePEM()))
+	qt.Assert(t, qt.IsNil(err))
+
+	platform, err := New(app.App)
+	qt.Assert(t, qt.IsNil(err))
+
+	// Platform roots are still trusted
+	device := time.Date(2025, 0o2, 20, 10, 0, 0, 0, time.UTC)
+
+	_, err = s.verifyAndroid(androidDeviceAttestation, []byte("abc"), "5RZFt5xRDoFXBZEc+pM9aDT7p2kW0VkSWdY4JHyUcG4=", device)
+	qt.Check(t, qt.IsNil(err))
+
+	_, err = s.verifyIOS(appleDeviceAttestation, []byte("abc"), "qjxtadpTcA30U86wPVpYRAAH7PZyWCBBTar+6C/TwzE=", device)
+	qt.Check(t, qt.IsNil(err))
+
+	// Test root CA is trusted in addition to the platform roots
+	challenge := []byte("challenge")
+
+	android, err := ca.Android(attestationtest.AndroidOptions{Challenge: challenge})
+	qt.Assert(t, qt.IsNil(err))
+
+	_, err = s.verifyAndroid(android.Attestation, challenge, android.HardwareKeyTag, time.Now())
+	qt.Check(t, qt.IsNil(err))
+
+	_, err = platform.verifyAndroid(android.Attestation, challenge, android.HardwareKeyTag, time.Now())
+	qt.Check(t, qt.IsNotNil(err))
+
+	apple, err := ca.Apple(attestationtest.AppleOptions{Challenge: challenge})
+	qt.Assert(t, qt.IsNil(err))
+
+	_, err = s.verifyIOS(apple.Attestation, challenge, apple.HardwareKeyTag, time.Now())
+	qt.Check(t, qt.IsNil(err))
+
+	_, err = platform.verifyIOS(apple.Attestation, challenge, apple.HardwareKeyTag, time.Now())
+	qt.Check(t, qt.IsNotNil(err))
+}
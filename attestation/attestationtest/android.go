@@ -0,0 +1,267 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package attestationtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// BootState is Android verified boot state.
+type BootState int
+
+const (
+	// BootVerified is the state of the device with fully verified boot chain.
+	BootVerified BootState = iota
+	// BootSelfSigned is the state of the device with boot chain verified using user installed key.
+	BootSelfSigned
+	// BootUnverified is the state of the device with unlocked bootloader.
+	BootUnverified
+	// BootFailed is the state of the device that failed boot verification.
+	BootFailed
+)
+
+// SecurityLevel is Android keystore security level.
+type SecurityLevel int
+
+const (
+	// SecurityLevelTEE is the key stored in the trusted execution environment.
+	SecurityLevelTEE SecurityLevel = 1
+	// SecurityLevelStrongBox is the key stored in the StrongBox hardware security module.
+	SecurityLevelStrongBox SecurityLevel = 2
+)
+
+// DefaultPackageID is the default Android application package ID of the attestation.
+const DefaultPackageID = "lv.lvrtc.edim"
+
+var (
+	oidAndroidKeyDescription   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 1, 17}
+	oidAndroidProvisioningInfo = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 1, 30}
+	oidAttributeTitle          = asn1.ObjectIdentifier{2, 5, 4, 12}
+)
+
+// AndroidOptions controls the generated Android key attestation.
+type AndroidOptions struct {
+	// Challenge is the attestation challenge. Required.
+	Challenge []byte
+	// PackageID is the application package ID. Defaults to DefaultPackageID.
+	PackageID string
+	// BootState is the verified boot state of the device. Defaults to BootVerified.
+	BootState BootState
+	// DeviceUnlocked marks bootloader as unlocked.
+	DeviceUnlocked bool
+	// SecurityLevel is the keystore security level. Defaults to SecurityLevelStrongBox.
+	SecurityLevel SecurityLevel
+	// CertsIssued is the count of certificates issued by the device for the provisioning information extension.
+	// Extension is omitted when zero.
+	CertsIssued int
+	// NotBefore is the start of the certificate validity. Defaults to one hour before the current time.
+	NotBefore time.Time
+	// NotAfter is the end of the certificate validity. Defaults to one day after NotBefore.
+	NotAfter time.Time
+	// Key is the attested key. New key is generated if nil.
+	Key *ecdsa.PrivateKey
+	// OmitKeyDescription leaves out the key description extension from the attestation certificate.
+	OmitKeyDescription bool
+}
+
+type androidKeyDescription struct {
+	AttestationVersion       int
+	AttestationSecurityLevel asn1.Enumerated
+	KeymasterVersion         int
+	KeymasterSecurityLevel   asn1.Enumerated
+	AttestationChallenge     []byte
+	UniqueID                 []byte
+	SoftwareEnforced         androidSoftwareEnforced
+	TeeEnforced              androidTeeEnforced
+}
+
+type androidSoftwareEnforced struct {
+	CreationDateTime         int    `asn1:"tag:701,explicit,optional"`
+	AttestationApplicationID []byte `asn1:"tag:709,explicit,optional"`
+}
+
+type androidTeeEnforced struct {
+	Purpose     []int              `asn1:"tag:1,explicit,set,optional"`
+	Algorithm   int                `asn1:"tag:2,explicit,optional"`
+	KeySize     int                `asn1:"tag:3,explicit,optional"`
+	EcCurve     int                `asn1:"tag:10,explicit,optional"`
+	Origin      int                `asn1:"tag:702,explicit"`
+	RootOfTrust androidRootOfTrust `asn1:"tag:704,explicit"`
+	OsVersion   int                `asn1:"tag:705,explicit,optional"`
+}
+
+type androidRootOfTrust struct {
+	VerifiedBootKey   []byte
+	DeviceLocked      bool
+	VerifiedBootState asn1.Enumerated
+	VerifiedBootHash  []byte
+}
+
+type androidApplicationID struct {
+	PackageInfos     []androidPackageInfo `asn1:"set"`
+	SignatureDigests [][]byte             `asn1:"set"`
+}
+
+type androidPackageInfo struct {
+	PackageName []byte
+	Version     int
+}
+
+type androidStatement struct {
+	Alg int      `cbor:"alg"`
+	Sig []byte   `cbor:"sig"`
+	X5c [][]byte `cbor:"x5c"`
+}
+
+type androidAttestation struct {
+	Format  string           `cbor:"fmt"`
+	AttStmt androidStatement `cbor:"attStmt"`
+}
+
+// Android generates Android key attestation object.
+//
+// Certificate chain contains attested key certificate, StrongBox or TEE intermediate and the root CA.
+func (ca *CA) Android(opts AndroidOptions) (*Attestation, error) {
+	if len(opts.Challenge) == 0 {
+		return nil, errChallengeRequired
+	}
+
+	if opts.PackageID == "" {
+		opts.PackageID = DefaultPackageID
+	}
+
+	if opts.SecurityLevel == 0 {
+		opts.SecurityLevel = SecurityLevelStrongBox
+	}
+
+	key, err := keyOrGenerate(opts.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	notBefore, notAfter := validity(opts.NotBefore, opts.NotAfter)
+
+	title := "TEE"
+	if opts.SecurityLevel == SecurityLevelStrongBox {
+		title = "StrongBox"
+	}
+
+	interm, intermKey, err := ca.intermediate(pkix.Name{
+		SerialNumber: fmt.Sprintf("%x", serialNumber()),
+		ExtraNames: []pkix.AttributeTypeAndValue{
+			{Type: oidAttributeTitle, Value: title},
+		},
+	}, notBefore, notAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	exts := make([]pkix.Extension, 0, 2)
+
+	if !opts.OmitKeyDescription {
+		ext, err := androidKeyDescriptionExtension(opts, notBefore)
+		if err != nil {
+			return nil, err
+		}
+
+		exts = append(exts, ext)
+	}
+
+	if opts.CertsIssued > 0 {
+		buf, err := cbor.Marshal(map[int]int{1: opts.CertsIssued})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode provisioning information: %w", err)
+		}
+
+		exts = append(exts, pkix.Extension{
+			Id:    oidAndroidProvisioningInfo,
+			Value: buf,
+		})
+	}
+
+	cert, err := leaf(pkix.Name{CommonName: "Android Keystore Key"}, key, interm, intermKey, notBefore, notAfter, exts...)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.Sum256(opts.Challenge)
+
+	sig, err := ecdsa.SignASN1(rand.Reader, key, h[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign attestation: %w", err)
+	}
+
+	buf, err := cbor.Marshal(androidAttestation{
+		Format: "android-key",
+		AttStmt: androidStatement{
+			Alg: -7,
+			Sig: sig,
+			X5c: [][]byte{cert, interm.Raw, ca.cert.Raw},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode attestation: %w", err)
+	}
+
+	tag, err := HardwareKeyTag(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Attestation{
+		Attestation:    base64.RawURLEncoding.EncodeToString(buf),
+		HardwareKeyTag: tag,
+		Key:            key,
+	}, nil
+}
+
+func androidKeyDescriptionExtension(opts AndroidOptions, created time.Time) (pkix.Extension, error) {
+	appID, err := asn1.Marshal(androidApplicationID{
+		PackageInfos: []androidPackageInfo{
+			{PackageName: []byte(opts.PackageID), Version: 1},
+		},
+		SignatureDigests: [][]byte{make([]byte, sha256.Size)},
+	})
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("failed to encode attestation application ID: %w", err)
+	}
+
+	bootKey := sha256.Sum256([]byte("verified boot key"))
+	bootHash := sha256.Sum256([]byte("verified boot hash"))
+
+	return extension(oidAndroidKeyDescription, androidKeyDescription{
+		AttestationVersion:       100,
+		AttestationSecurityLevel: asn1.Enumerated(opts.SecurityLevel),
+		KeymasterVersion:         100,
+		KeymasterSecurityLevel:   asn1.Enumerated(opts.SecurityLevel),
+		AttestationChallenge:     opts.Challenge,
+		UniqueID:                 []byte{},
+		SoftwareEnforced: androidSoftwareEnforced{
+			CreationDateTime:         int(created.UnixMilli()),
+			AttestationApplicationID: appID,
+		},
+		TeeEnforced: androidTeeEnforced{
+			Purpose:   []int{2, 3},
+			Algorithm: 3,
+			KeySize:   256,
+			EcCurve:   1,
+			Origin:    0,
+			RootOfTrust: androidRootOfTrust{
+				VerifiedBootKey:   bootKey[:],
+				DeviceLocked:      !opts.DeviceUnlocked,
+				VerifiedBootState: asn1.Enumerated(opts.BootState),
+				VerifiedBootHash:  bootHash[:],
+			},
+			OsVersion: 150000,
+		},
+	})
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package attestationtest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// DefaultAppID is the default Apple application identifier (team ID and bundle ID) used as relying party ID.
+const DefaultAppID = "FJFSUVZ3GH.lv.zzdats.edim"
+
+var (
+	oidAppleNonce     = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 8, 2}
+	aaguidDevelopment = []byte("appattestdevelop")
+	aaguidProduction  = append([]byte("appattest"), make([]byte, 7)...)
+)
+
+// AppleOptions controls the generated Apple App Attest attestation.
+type AppleOptions struct {
+	// Challenge is the attestation challenge. Required.
+	Challenge []byte
+	// RPID is the relying party ID which is hashed into authenticator data. Defaults to DefaultAppID.
+	RPID string
+	// Counter is the sign counter of the authenticator data. Real attestation always has zero counter.
+	Counter uint32
+	// Production uses production App Attest environment AAGUID instead of development.
+	Production bool
+	// NonceChallenge overrides challenge used for calculating nonce in the attestation certificate
+	// to produce attestation with mismatching nonce.
+	NonceChallenge []byte
+	// NotBefore is the start of the certificate validity. Defaults to one hour before the current time.
+	NotBefore time.Time
+	// NotAfter is the end of the certificate validity. Defaults to one day after NotBefore.
+	NotAfter time.Time
+	// Key is the attested key. New key is generated if nil.
+	Key *ecdsa.PrivateKey
+}
+
+type appleNonce struct {
+	Nonce []byte `asn1:"tag:1,explicit"`
+}
+
+type appleStatement struct {
+	X5c     [][]byte `cbor:"x5c"`
+	Receipt []byte   `cbor:"receipt"`
+}
+
+type appleAttestation struct {
+	Format   string         `cbor:"fmt"`
+	AttStmt  appleStatement `cbor:"attStmt"`
+	AuthData []byte         `cbor:"authData"`
+}
+
+// Apple generates Apple App Attest attestation object.
+//
+// Certificate chain contains attested key certificate and intermediate, root CA certificate is not included.
+func (ca *CA) Apple(opts AppleOptions) (*Attestation, error) {
+	if len(opts.Challenge) == 0 {
+		return nil, errChallengeRequired
+	}
+
+	if opts.RPID == "" {
+		opts.RPID = DefaultAppID
+	}
+
+	if opts.NonceChallenge == nil {
+		opts.NonceChallenge = opts.Challenge
+	}
+
+	key, err := keyOrGenerate(opts.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	notBefore, notAfter := validity(opts.NotBefore, opts.NotAfter)
+
+	interm, intermKey, err := ca.intermediate(pkix.Name{
+		CommonName:   "EDIM Test App Attestation CA 1",
+		Organization: []string{"EDIM Test"},
+	}, notBefore, notAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	authData, err := appleAuthData(opts, &key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeHash := sha256.Sum256(opts.NonceChallenge)
+	nonce := sha256.Sum256(append(bytes.Clone(authData), challengeHash[:]...))
+
+	ext, err := extension(oidAppleNonce, appleNonce{Nonce: nonce[:]})
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := keyID(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := leaf(pkix.Name{
+		CommonName:         fmt.Sprintf("%x", id),
+		OrganizationalUnit: []string{"AAA Certification"},
+		Organization:       []string{"EDIM Test"},
+	}, key, interm, intermKey, notBefore, notAfter, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := cbor.Marshal(appleAttestation{
+		Format: "apple-appattest",
+		AttStmt: appleStatement{
+			X5c:     [][]byte{cert, interm.Raw},
+			Receipt: []byte("receipt"),
+		},
+		AuthData: authData,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode attestation: %w", err)
+	}
+
+	return &Attestation{
+		Attestation:    base64.RawURLEncoding.EncodeToString(buf),
+		HardwareKeyTag: base64.StdEncoding.EncodeToString(id),
+		Key:            key,
+	}, nil
+}
+
+// appleAuthData builds WebAuthn authenticator data with attested credential data.
+func appleAuthData(opts AppleOptions, pub *ecdsa.PublicKey) ([]byte, error) {
+	id, err := keyID(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	coseKey, err := cbor.Marshal(map[int]any{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: pub.X.FillBytes(make([]byte, 32)),
+		-3: pub.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode credential public key: %w", err)
+	}
+
+	aaguid := aaguidDevelopment
+	if opts.Production {
+		aaguid = aaguidProduction
+	}
+
+	rpIDHash := sha256.Sum256([]byte(opts.RPID))
+
+	var buf bytes.Buffer
+
+	buf.Write(rpIDHash[:])
+	// Flags: user present and attested credential data included
+	buf.WriteByte(0x41)
+	_ = binary.Write(&buf, binary.BigEndian, opts.Counter)
+	buf.Write(aaguid)
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(id)))
+	buf.Write(id)
+	buf.Write(coseKey)
+
+	return buf.Bytes(), nil
+}
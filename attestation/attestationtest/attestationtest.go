@@ -0,0 +1,283 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package attestationtest synthesizes Android key attestation and Apple App Attest objects
+// signed by a test root certificate authority.
+//
+// Generated attestations are accepted by the attestation service only when it is configured
+// with the test root CA using attestation.WithAndroidRootCA and attestation.WithAppleRootCA.
+package attestationtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var errChallengeRequired = errors.New("attestation challenge is required")
+
+// Attestation is the generated attestation object.
+type Attestation struct {
+	// Attestation is base64url encoded CBOR attestation object.
+	Attestation string
+	// HardwareKeyTag is base64 encoded SHA-256 hash of the attested public key.
+	HardwareKeyTag string
+	// Key is the attested private key.
+	Key *ecdsa.PrivateKey
+}
+
+// CA is the test root certificate authority.
+type CA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// NewCA creates new self-signed test root certificate authority.
+func NewCA() (*CA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate root CA key: %w", err)
+	}
+
+	now := time.Now()
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serialNumber(),
+		Subject: pkix.Name{
+			CommonName:   "EDIM Test Attestation Root CA",
+			Organization: []string{"EDIM Test"},
+		},
+		NotBefore:             now.AddDate(-10, 0, 0),
+		NotAfter:              now.AddDate(20, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create root CA certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CA{
+		cert: cert,
+		key:  key,
+	}, nil
+}
+
+// Certificate returns root CA certificate.
+func (ca *CA) Certificate() *x509.Certificate {
+	return ca.cert
+}
+
+// CertificatePEM returns root CA certificate in PEM format as used for Apple App Attest root.
+func (ca *CA) CertificatePEM() string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: ca.cert.Raw,
+	}))
+}
+
+// PublicKeyPEM returns root CA public key in PEM format as used for Android key attestation root.
+func (ca *CA) PublicKeyPEM() string {
+	buf, _ := x509.MarshalPKIXPublicKey(&ca.key.PublicKey)
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: buf,
+	}))
+}
+
+// PEM returns root CA certificate and private key in PEM format that can be loaded using ParseCA.
+func (ca *CA) PEM() ([]byte, error) {
+	key, err := x509.MarshalECPrivateKey(ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode root CA key: %w", err)
+	}
+
+	buf := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: ca.cert.Raw,
+	})
+
+	return append(buf, pem.EncodeToMemory(&pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: key,
+	})...), nil
+}
+
+// ParseCA loads root certificate authority from certificate and private key in PEM format.
+func ParseCA(data []byte) (*CA, error) {
+	ca := &CA{}
+
+	for {
+		var block *pem.Block
+
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var err error
+
+		switch block.Type {
+		case "CERTIFICATE":
+			ca.cert, err = x509.ParseCertificate(block.Bytes)
+		case "EC PRIVATE KEY":
+			ca.key, err = x509.ParseECPrivateKey(block.Bytes)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse root CA %s: %w", strings.ToLower(block.Type), err)
+		}
+	}
+
+	if ca.cert == nil || ca.key == nil {
+		return nil, errors.New("root CA certificate and private key are required")
+	}
+
+	if !ca.key.PublicKey.Equal(ca.cert.PublicKey) {
+		return nil, errors.New("root CA private key does not match certificate")
+	}
+
+	return ca, nil
+}
+
+// intermediate issues intermediate CA certificate.
+func (ca *CA) intermediate(subject pkix.Name, notBefore, notAfter time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate intermediate CA key: %w", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serialNumber(),
+		Subject:               subject,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create intermediate CA certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cert, key, nil
+}
+
+// leaf issues attested key certificate with the extensions.
+func leaf(
+	subject pkix.Name,
+	key *ecdsa.PrivateKey,
+	parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey,
+	notBefore, notAfter time.Time,
+	extensions ...pkix.Extension,
+) ([]byte, error) {
+	tmpl := &x509.Certificate{
+		SerialNumber:    serialNumber(),
+		Subject:         subject,
+		NotBefore:       notBefore,
+		NotAfter:        notAfter,
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtraExtensions: extensions,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attestation certificate: %w", err)
+	}
+
+	return der, nil
+}
+
+// keyOrGenerate returns the key or generates new P-256 key if nil.
+func keyOrGenerate(key *ecdsa.PrivateKey) (*ecdsa.PrivateKey, error) {
+	if key != nil {
+		return key, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate attested key: %w", err)
+	}
+
+	return key, nil
+}
+
+// validity returns certificate validity period defaulting to one day around the current time.
+func validity(notBefore, notAfter time.Time) (time.Time, time.Time) {
+	if notBefore.IsZero() {
+		notBefore = time.Now().Add(-time.Hour)
+	}
+
+	if notAfter.IsZero() {
+		notAfter = notBefore.Add(25 * time.Hour)
+	}
+
+	return notBefore, notAfter
+}
+
+// HardwareKeyTag returns hardware key tag of the public key.
+func HardwareKeyTag(key *ecdsa.PublicKey) (string, error) {
+	id, err := keyID(key)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(id), nil
+}
+
+// keyID returns SHA-256 hash of the public key in X9.62 uncompressed format.
+func keyID(key *ecdsa.PublicKey) ([]byte, error) {
+	pub, err := key.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert public key: %w", err)
+	}
+
+	h := sha256.Sum256(pub.Bytes())
+
+	return h[:], nil
+}
+
+func serialNumber() *big.Int {
+	n, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+
+	return n
+}
+
+// extension returns certificate extension with DER encoded value.
+func extension(id asn1.ObjectIdentifier, v any) (pkix.Extension, error) {
+	buf, err := asn1.Marshal(v)
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("failed to encode extension %s: %w", id, err)
+	}
+
+	return pkix.Extension{
+		Id:    id,
+		Value: buf,
+	}, nil
+}
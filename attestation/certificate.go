@@ -11,25 +11,37 @@ import (
 	"time"
 )
 
-func (a *Service) verifyCert(chain [][]byte, rootCA string, now time.Time) (*x509.Certificate, []*x509.Certificate, error) {
+func (a *Service) verifyCert(chain [][]byte, rootCAs []string, now time.Time) (*x509.Certificate, []*x509.Certificate, error) {
 	roots := x509.NewCertPool()
 
-	var pub any
+	// Root public keys are trusted only for self-signed certificates in the chain
+	pubs := make([]any, 0, len(rootCAs))
 
-	if strings.HasPrefix(rootCA, "-----BEGIN PUBLIC KEY-----") {
-		var err error
+	for _, rootCA := range rootCAs {
+		if !strings.HasPrefix(rootCA, "-----BEGIN PUBLIC KEY-----") {
+			roots.AppendCertsFromPEM([]byte(rootCA))
+
+			continue
+		}
 
 		block, _ := pem.Decode([]byte(rootCA))
+		if block == nil {
+			return nil, nil, errors.New("failed to parse root public key")
+		}
 
-		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
 		if err != nil {
 			return nil, nil, err
 		}
-	} else {
-		roots.AppendCertsFromPEM([]byte(rootCA))
+
+		pubs = append(pubs, pub)
+	}
+
+	if len(chain) == 0 {
+		return nil, nil, errors.New("attestation certificate chain is empty")
 	}
 
-	interms := make([]*x509.Certificate, 0, len(chain)-2)
+	interms := make([]*x509.Certificate, 0, len(chain))
 
 	var cert *x509.Certificate
 
@@ -42,8 +54,10 @@ func (a *Service) verifyCert(chain [][]byte, rootCA string, now time.Time) (*x50
 		// TODO check CRLs
 
 		if c.Subject.String() == c.Issuer.String() {
-			if pub != nil && equalKeys(c.PublicKey, pub) {
-				roots.AddCert(c)
+			for _, pub := range pubs {
+				if equalKeys(c.PublicKey, pub) {
+					roots.AddCert(c)
+				}
 			}
 
 			continue
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package attestation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"azugo.io/azugo"
+)
+
+// FuzzVerify feeds CBOR attestation objects to the verifier. Golden fixtures are used both as seeds
+// and as the trusted roots so that mutated attestations can pass certificate verification.
+func FuzzVerify(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.golden.json"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	opts := make([]Option, 0, 2)
+
+	for _, file := range files {
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		g := &goldenFixture{}
+		if err := json.Unmarshal(buf, g); err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(seed(f, g.Attestation), []byte(g.Challenge), g.HardwareKeyTag)
+
+		if strings.HasPrefix(g.RootCA, "-----BEGIN PUBLIC KEY-----") {
+			opts = append(opts, WithAndroidRootCA(g.RootCA))
+		} else {
+			opts = append(opts, WithAppleRootCA(g.RootCA))
+		}
+	}
+
+	f.Add(seed(f, androidDeviceAttestation), []byte("abc"), "5RZFt5xRDoFXBZEc+pM9aDT7p2kW0VkSWdY4JHyUcG4=")
+	f.Add(seed(f, appleDeviceAttestation), []byte("abc"), "qjxtadpTcA30U86wPVpYRAAH7PZyWCBBTar+6C/TwzE=")
+
+	s, err := New(azugo.NewTestApp().App, opts...)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, att, challenge []byte, tag string) {
+		r, err := s.verify(base64.RawURLEncoding.EncodeToString(att), challenge, tag, goldenTime)
+		if err != nil {
+			return
+		}
+
+		if r == nil {
+			t.Fatal("nil result without error")
+		}
+
+		if r.HardwareKeyTag != tag {
+			t.Fatalf("hardware key tag %q does not match requested %q", r.HardwareKeyTag, tag)
+		}
+	})
+}
+
+// seed decodes base64url encoded attestation object.
+func seed(f *testing.F, att string) []byte {
+	f.Helper()
+
+	buf, err := base64.RawURLEncoding.DecodeString(att)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	return buf
+}
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package attestation
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/attestation/attestationtest"
+
+	"azugo.io/azugo"
+	"github.com/go-quicktest/qt"
+)
+
+var update = flag.Bool("update", false, "regenerate golden attestation fixtures")
+
+// goldenTime is the verification time of the golden attestation fixtures.
+var goldenTime = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+type goldenFixture struct {
+	RootCA         string  `json:"rootCA"`
+	Attestation    string  `json:"attestation"`
+	Challenge      string  `json:"challenge"`
+	HardwareKeyTag string  `json:"hardwareKeyTag"`
+	Time           string  `json:"time"`
+	Result         *Result `json:"result,omitempty"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// goldenError returns name and tag of the invalid parameter error or error message.
+func goldenError(err error) string {
+	var perr azugo.ParamInvalidError
+	if errors.As(err, &perr) {
+		return perr.Name + ":" + perr.Tag
+	}
+
+	return err.Error()
+}
+
+func generateGolden(t *testing.T) {
+	t.Helper()
+
+	ca, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	s := testService(t, ca)
+
+	notBefore := goldenTime.AddDate(0, -1, 0)
+	notAfter := goldenTime.AddDate(10, 0, 0)
+
+	generators := map[string]func(challenge []byte) (*attestationtest.Attestation, string, error){
+		"android_strongbox": func(challenge []byte) (*attestationtest.Attestation, string, error) {
+			att, err := ca.Android(attestationtest.AndroidOptions{Challenge: challenge, CertsIssued: 1, NotBefore: notBefore, NotAfter: notAfter})
+
+			return att, ca.PublicKeyPEM(), err
+		},
+		"android_tee": func(challenge []byte) (*attestationtest.Attestation, string, error) {
+			att, err := ca.Android(attestationtest.AndroidOptions{Challenge: challenge, SecurityLevel: attestationtest.SecurityLevelTEE, NotBefore: notBefore, NotAfter: notAfter})
+
+			return att, ca.PublicKeyPEM(), err
+		},
+		"android_unverified_boot": func(challenge []byte) (*attestationtest.Attestation, string, error) {
+			att, err := ca.Android(attestationtest.AndroidOptions{Challenge: challenge, BootState: attestationtest.BootUnverified, DeviceUnlocked: true, NotBefore: notBefore, NotAfter: notAfter})
+
+			return att, ca.PublicKeyPEM(), err
+		},
+		"apple_development": func(challenge []byte) (*attestationtest.Attestation, string, error) {
+			att, err := ca.Apple(attestationtest.AppleOptions{Challenge: challenge, NotBefore: notBefore, NotAfter: notAfter})
+
+			return att, ca.CertificatePEM(), err
+		},
+		"apple_production": func(challenge []byte) (*attestationtest.Attestation, string, error) {
+			att, err := ca.Apple(attestationtest.AppleOptions{Challenge: challenge, Production: true, NotBefore: notBefore, NotAfter: notAfter})
+
+			return att, ca.CertificatePEM(), err
+		},
+	}
+
+	for name, gen := range generators {
+		challenge := "golden-" + name
+
+		att, rootCA, err := gen([]byte(challenge))
+		qt.Assert(t, qt.IsNil(err))
+
+		f := &goldenFixture{
+			RootCA:         rootCA,
+			Attestation:    att.Attestation,
+			Challenge:      challenge,
+			HardwareKeyTag: att.HardwareKeyTag,
+			Time:           goldenTime.Format(time.RFC3339),
+		}
+
+		f.Result, err = s.verify(att.Attestation, []byte(challenge), att.HardwareKeyTag, goldenTime)
+		if err != nil {
+			f.Error = goldenError(err)
+		}
+
+		buf, err := json.MarshalIndent(f, "", "  ")
+		qt.Assert(t, qt.IsNil(err))
+
+		err = os.WriteFile(filepath.Join("testdata", name+".golden.json"), append(buf, '\n'), 0o600)
+		qt.Assert(t, qt.IsNil(err))
+	}
+}
+
+func TestGoldenAttestation(t *testing.T) {
+	if *update {
+		generateGolden(t)
+	}
+
+	files, err := filepath.Glob(filepath.Join("testdata", "*.golden.json"))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Not(qt.HasLen(files, 0)))
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			buf, err := os.ReadFile(file)
+			qt.Assert(t, qt.IsNil(err))
+
+			f := &goldenFixture{}
+			qt.Assert(t, qt.IsNil(json.Unmarshal(buf, f)))
+
+			now, err := time.Parse(time.RFC3339, f.Time)
+			qt.Assert(t, qt.IsNil(err))
+
+			s, err := New(azugo.NewTestApp().App, WithAndroidRootCA(f.RootCA), WithAppleRootCA(f.RootCA))
+			qt.Assert(t, qt.IsNil(err))
+
+			r, err := s.verify(f.Attestation, []byte(f.Challenge), f.HardwareKeyTag, now)
+			if f.Error != "" {
+				qt.Assert(t, qt.IsNotNil(err))
+				qt.Check(t, qt.Equals(goldenError(err), f.Error))
+
+				return
+			}
+
+			qt.Assert(t, qt.IsNil(err))
+			qt.Check(t, qt.DeepEquals(r, f.Result))
+		})
+	}
+}
@@ -46,7 +46,11 @@ func (a *Service) verifyIOS(att string, challenge []byte, tag string, now time.T
 		return nil, err
 	}
 
-	cert, _, err := a.verifyCert(s.AttStmt.X5c, appleAppAttestRootCA, now)
+	if s.AttStmt == nil {
+		return nil, errors.New("attestation statement missing")
+	}
+
+	cert, _, err := a.verifyCert(s.AttStmt.X5c, a.rootCAs(a.appleRootCA), now)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"git.zzdats.lv/edim/api-wallet/mock"
+
+	"github.com/spf13/cobra"
+)
+
+// mockUpstreamsCmd represents the mock-upstreams command.
+var mockUpstreamsCmd = &cobra.Command{
+	Use:   "mock-upstreams",
+	Short: "Start fakes of the upstream services",
+	Long: `Starts in-process fakes of IDAuth, FPRIS, RTU, MDL, EHIC, PDA1, issuer and SimpleSign
+services with fixture persons for local development and CI. Prints environment variables
+that point the web server to the fakes. Person code of the fixture person is used as access token.`,
+	RunE: runMockUpstreams,
+}
+
+func runMockUpstreams(cmd *cobra.Command, _ []string) error {
+	listen, err := cmd.Flags().GetString("listen")
+	if err != nil {
+		return err
+	}
+
+	s := mock.New()
+
+	if err := s.Start(listen); err != nil {
+		return err
+	}
+
+	env := s.Env()
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		cmd.Printf("%s=%s\n", k, env[k])
+	}
+
+	cmd.Println()
+	cmd.Printf("Fixture persons: %s (all credentials), %s (two diplomas), %s (deceased)\n",
+		mock.PersonCodeFull, mock.PersonCodeDiplomas, mock.PersonCodeDeceased)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	return s.Close()
+}
+
+func init() {
+	initRootCmd()
+
+	mockUpstreamsCmd.Flags().String("listen", "127.0.0.1:9090", "Address to listen on")
+
+	RootCmd.AddCommand(mockUpstreamsCmd)
+}
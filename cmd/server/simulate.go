@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/attestation/attestationtest"
+	"git.zzdats.lv/edim/api-wallet/simulator"
+
+	"github.com/spf13/cobra"
+)
+
+// simulateWalletCmd represents the simulate-wallet command.
+var simulateWalletCmd = &cobra.Command{
+	Use:   "simulate-wallet",
+	Short: "Simulate mobile wallet against the wallet API",
+	Long: `Registers wallet instance with synthesized key attestation, requests wallet attestation
+and redeems credential offer the same way as the mobile wallet does. Credential offer is either
+passed with --offer or created for the person identified by --token and --credential.
+
+Wallet API must trust the test root CA set in ATTESTATION_TEST_ROOT_CA. Root CA is generated
+and stored in --ca file if it does not exist.`,
+	RunE: runSimulateWallet,
+}
+
+// loadCA loads test root CA from the file or generates and stores new one if the file does not exist.
+func loadCA(cmd *cobra.Command, path string) (*attestationtest.CA, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return attestationtest.ParseCA(data)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	ca, err := attestationtest.NewCA()
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = ca.PEM(); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to store test root CA: %w", err)
+	}
+
+	cmd.Printf("Generated test root CA in %s, configure wallet API to trust it:\n\n", path)
+	cmd.Printf("ATTESTATION_TEST_ROOT_CA=\"%s\"\n\n", ca.CertificatePEM())
+
+	return ca, nil
+}
+
+func runSimulateWallet(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+
+	opts := simulator.Options{
+		Logf: func(format string, args ...any) {
+			cmd.Printf(format+"\n", args...)
+		},
+	}
+
+	var err error
+
+	if opts.URL, err = flags.GetString("url"); err != nil {
+		return err
+	}
+
+	if opts.PublicURL, err = flags.GetString("public-url"); err != nil {
+		return err
+	}
+
+	if opts.Platform, err = flags.GetString("platform"); err != nil {
+		return err
+	}
+
+	if opts.AccessToken, err = flags.GetString("token"); err != nil {
+		return err
+	}
+
+	if opts.ClientID, err = flags.GetString("client-id"); err != nil {
+		return err
+	}
+
+	if opts.Timeout, err = flags.GetDuration("timeout"); err != nil {
+		return err
+	}
+
+	caFile, err := flags.GetString("ca")
+	if err != nil {
+		return err
+	}
+
+	offerURL, err := flags.GetString("offer")
+	if err != nil {
+		return err
+	}
+
+	txCode, err := flags.GetString("tx-code")
+	if err != nil {
+		return err
+	}
+
+	credentialType, err := flags.GetString("credential")
+	if err != nil {
+		return err
+	}
+
+	if offerURL == "" && credentialType != "" && opts.AccessToken == "" {
+		return errors.New("--token is required to create credential offer")
+	}
+
+	if opts.CA, err = loadCA(cmd, caFile); err != nil {
+		return err
+	}
+
+	w, err := simulator.New(opts)
+	if err != nil {
+		return err
+	}
+
+	if err := w.Register(); err != nil {
+		return err
+	}
+
+	if _, err := w.WalletAttestation(); err != nil {
+		return err
+	}
+
+	if offerURL == "" {
+		if credentialType == "" {
+			return nil
+		}
+
+		offer, err := w.CreateOffer(credentialType)
+		if err != nil {
+			return err
+		}
+
+		offerURL, txCode = offer.URL, offer.TXCode
+	}
+
+	creds, err := w.Redeem(offerURL, txCode)
+	if err != nil {
+		return err
+	}
+
+	for _, cred := range creds {
+		for _, c := range cred.Credentials {
+			cmd.Printf("%s: %s\n", cred.ConfigurationID, c)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	initRootCmd()
+
+	simulateWalletCmd.Flags().String("url", "http://localhost:8080", "Wallet API URL")
+	simulateWalletCmd.Flags().String("public-url", "", "Wallet API public URL as configured by WALLET_API_PUBLIC_URL (defaults to --url)")
+	simulateWalletCmd.Flags().String("ca", "attestation-test-ca.pem", "Test root CA certificate and private key file")
+	simulateWalletCmd.Flags().String("platform", simulator.PlatformAndroid, "Simulated device platform (android or ios)")
+	simulateWalletCmd.Flags().String("token", "", "IDAuth access token of the person")
+	simulateWalletCmd.Flags().String("credential", "", "Credential type to create offer for (e.g. pid, mdl, rtu)")
+	simulateWalletCmd.Flags().String("offer", "", "Credential offer URL to redeem")
+	simulateWalletCmd.Flags().String("tx-code", "", "Transaction code of the credential offer")
+	simulateWalletCmd.Flags().String("client-id", simulator.DefaultClientID, "OAuth client ID of the wallet")
+	simulateWalletCmd.Flags().Duration("timeout", 30*time.Second, "Timeout of a single request")
+
+	RootCmd.AddCommand(simulateWalletCmd)
+}
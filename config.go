@@ -5,6 +5,7 @@ package wallet
 import (
 	"time"
 
+	"git.zzdats.lv/edim/api-wallet/credential"
 	"git.zzdats.lv/edim/api-wallet/issuer"
 	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
 
@@ -19,14 +20,20 @@ import (
 type Configuration struct {
 	*config.Configuration `mapstructure:",squash"`
 
-	Postgres *jsondb.Configuration `mapstructure:"postgres"`
-	IDAuth   *idauth.Configuration `mapstruct:"idauth"`
-	Issuer   *issuer.Configuration `mapstruct:"issuer"`
+	Postgres *jsondb.Configuration     `mapstructure:"postgres"`
+	IDAuth   *idauth.Configuration     `mapstruct:"idauth"`
+	Issuer   *issuer.Configuration     `mapstruct:"issuer"`
+	Upstream *credential.Configuration `mapstruct:"upstream"`
 
 	QRAPIDeepLink       string        `mapstructure:"qr_api_deep_link" validate:"required"`
 	FprisAPIURL         string        `mapstructure:"fpris_api_url" validate:"required,url"`
 	RTUAPIURL           string        `mapstructure:"rtu_api_url" validate:"required,url"`
 	MDLAPIURL           string        `mapstructure:"mdl_api_url" validate:"required,url"`
+	EHICAPIURL          string        `mapstructure:"ehic_api_url" validate:"omitempty,url"`
+	PDA1APIURL          string        `mapstructure:"pda1_api_url" validate:"omitempty,url"`
+	AgeOverThresholds   string        `mapstructure:"age_over_thresholds" validate:"required"`
+	MDLPortraitMaxSize  int           `mapstructure:"mdl_portrait_max_size" validate:"required,gt=0"`
+	MDLPortraitMaxDim   int           `mapstructure:"mdl_portrait_max_dimension" validate:"required,gt=0"`
 	SimpleSignService   string        `mapstructure:"simple_sign_service" validate:"required,url"`
 	SimpleSignPublicURL string        `mapstructure:"simple_sign_public_url" validate:"required,url"`
 	SimpleSignAPIKey    string        `mapstructure:"simple_sign_api_key" validate:"required"`
@@ -34,6 +41,11 @@ type Configuration struct {
 	WalletCheckInterval time.Duration `mapstructure:"wallet_check_interval" validate:"required"`
 	WalletOlderThan     time.Duration `mapstructure:"wallet_older_than" validate:"required"`
 	WalletPublicURL     string        `mapstructure:"wallet_api_public_url" validate:"required,url"`
+	QRCodeSize          int           `mapstructure:"qr_code_size" validate:"required,gt=0"`
+	QRCodeLevel         string        `mapstructure:"qr_code_recovery_level" validate:"required,oneof=L M Q H"`
+	QRCodeLogoFile      string        `mapstructure:"qr_code_logo_file"`
+	// Test root CA certificate trusted for key attestations in addition to Google and Apple roots, not allowed in production
+	AttestationTestRootCA string `mapstructure:"attestation_test_root_ca"`
 }
 
 // NewConfiguration returns a new configuration.
@@ -55,15 +67,26 @@ func (c *Configuration) Bind(_ string, v *viper.Viper) {
 	c.Postgres = config.Bind(c.Postgres, "postgres", v)
 	c.Issuer = config.Bind(c.Issuer, "issuer", v)
 	c.IDAuth = config.Bind(c.IDAuth, "idauth", v)
+	c.Upstream = config.Bind(c.Upstream, "upstream", v)
 
 	v.SetDefault("wallet_check_interval", 30*time.Minute)
 	v.SetDefault("wallet_older_than", 1*time.Hour)
 	v.SetDefault("simple_sign_cache_ttl", 10*time.Minute)
+	v.SetDefault("qr_code_size", 512)
+	v.SetDefault("qr_code_recovery_level", "M")
+	v.SetDefault("age_over_thresholds", "18")
+	v.SetDefault("mdl_portrait_max_size", 100000)
+	v.SetDefault("mdl_portrait_max_dimension", 640)
 
 	_ = v.BindEnv("qr_api_deep_link", "QR_API_DEEP_LINK")
 	_ = v.BindEnv("fpris_api_url", "FPRIS_API_URL")
 	_ = v.BindEnv("rtu_api_url", "RTU_API_URL")
 	_ = v.BindEnv("mdl_api_url", "MDL_API_URL")
+	_ = v.BindEnv("ehic_api_url", "EHIC_API_URL")
+	_ = v.BindEnv("pda1_api_url", "PDA1_API_URL")
+	_ = v.BindEnv("age_over_thresholds", "AGE_OVER_THRESHOLDS")
+	_ = v.BindEnv("mdl_portrait_max_size", "MDL_PORTRAIT_MAX_SIZE")
+	_ = v.BindEnv("mdl_portrait_max_dimension", "MDL_PORTRAIT_MAX_DIMENSION")
 	_ = v.BindEnv("wallet_api_public_url", "WALLET_API_PUBLIC_URL")
 	_ = v.BindEnv("simple_sign_service", "SIMPLE_SIGN_SERVICE")
 	_ = v.BindEnv("simple_sign_public_url", "SIMPLE_SIGN_PUBLIC_URL")
@@ -74,6 +97,10 @@ func (c *Configuration) Bind(_ string, v *viper.Viper) {
 	_ = v.BindEnv("simple_sign_api_key", "SIMPLE_SIGN_API_KEY")
 	_ = v.BindEnv("wallet_check_interval", "WALLET_CHECK_INTERVAL")
 	_ = v.BindEnv("wallet_older_than", "WALLET_OLDER_THAN")
+	_ = v.BindEnv("qr_code_size", "QR_CODE_SIZE")
+	_ = v.BindEnv("qr_code_recovery_level", "QR_CODE_RECOVERY_LEVEL")
+	_ = v.BindEnv("qr_code_logo_file", "QR_CODE_LOGO_FILE")
+	_ = v.BindEnv("attestation_test_root_ca", "ATTESTATION_TEST_ROOT_CA")
 }
 
 // Validate application configuration.
@@ -94,5 +121,9 @@ func (c *Configuration) Validate(validate *validation.Validate) error {
 		return err
 	}
 
+	if err := c.Upstream.Validate(validate); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package age
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/credential"
+	"git.zzdats.lv/edim/api-wallet/credential/pid"
+
+	"azugo.io/azugo"
+)
+
+// Source of the age verification credential. Credential contains only age over flags
+// derived from the FPRIS data without disclosing person identity.
+type Source struct {
+	pid        *pid.Source
+	thresholds []int
+}
+
+// New creates age verification credential source using PID data.
+func New(pid *pid.Source, thresholds []int) *Source {
+	return &Source{
+		pid:        pid,
+		thresholds: thresholds,
+	}
+}
+
+// ParseThresholds parses age thresholds separated by `;`.
+func ParseThresholds(thresholds string) ([]int, error) {
+	res := make([]int, 0)
+
+	for _, t := range strings.Split(thresholds, ";") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(t)
+		if err != nil || n <= 0 || n > 150 {
+			return nil, fmt.Errorf("invalid age threshold: %s", t)
+		}
+
+		if !slices.Contains(res, n) {
+			res = append(res, n)
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, errors.New("at least one age threshold is required")
+	}
+
+	slices.Sort(res)
+
+	return res, nil
+}
+
+func (s *Source) Type() string {
+	return "age"
+}
+
+func (s *Source) ConfigurationIDs() []string {
+	return []string{"eu.europa.ec.eudi.age_verification_mdoc"}
+}
+
+func (s *Source) Errors() credential.Errors {
+	return credential.Errors{
+		NotFound: "Data about person not found",
+		Failed:   "failed to call PID",
+	}
+}
+
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return s.pid.HealthCheck(ctx)
+}
+
+func (s *Source) BreakerState() credential.BreakerState {
+	return s.pid.BreakerState()
+}
+
+func (s *Source) Form(ctx *azugo.Context) (any, error) {
+	res, err := s.pid.Data(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Birth date has already been validated by PID source
+	birthDate, err := time.Parse(time.DateOnly, res.BirthDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid birth date: %w", err)
+	}
+
+	form := Form(birthDate, s.thresholds, time.Now())
+	form["issuing_country"] = res.IssuingCountry
+	form["issuing_authority"] = res.IssuingAuthority
+	form["estimated_issuance_date"] = res.IssuanceDate
+	form["estimated_expiry_date"] = res.ExpiryDate
+
+	return form, nil
+}
+
+// Form returns age over flags for all thresholds at the provided time.
+func Form(birthDate time.Time, thresholds []int, now time.Time) map[string]any {
+	form := make(map[string]any, len(thresholds)+4)
+
+	for _, t := range thresholds {
+		// Person reaches the age at the start of the birthday
+		form["age_over_"+strconv.Itoa(t)] = !now.Before(birthDate.AddDate(t, 0, 0))
+	}
+
+	return form
+}
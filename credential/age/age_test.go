@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package age
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestParseThresholds(t *testing.T) {
+	res, err := ParseThresholds("21; 18;65;18")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(res, []int{18, 21, 65}))
+
+	_, err = ParseThresholds("18;abc")
+	qt.Check(t, qt.IsNotNil(err))
+
+	_, err = ParseThresholds("")
+	qt.Check(t, qt.IsNotNil(err))
+}
+
+func TestForm(t *testing.T) {
+	birthDate := time.Date(2007, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	form := Form(birthDate, []int{16, 18, 21}, time.Date(2025, 6, 14, 12, 0, 0, 0, time.UTC))
+	qt.Check(t, qt.DeepEquals(form, map[string]any{
+		"age_over_16": true,
+		"age_over_18": false,
+		"age_over_21": false,
+	}))
+
+	form = Form(birthDate, []int{18}, time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC))
+	qt.Check(t, qt.DeepEquals(form, map[string]any{
+		"age_over_18": true,
+	}))
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package credential
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of the circuit breaker.
+type BreakerState string
+
+const (
+	// BreakerClosed allows all calls to the upstream registry.
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen fails all calls fast until the cooldown passes.
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen allows single probe call to check if the upstream registry has recovered.
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// Breaker is a circuit breaker that opens after a number of consecutive failed calls.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// NewBreaker creates circuit breaker. Threshold of zero disables the breaker.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow checks if the call is allowed. If not, returns duration after which the call can be retried.
+func (b *Breaker) Allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state() {
+	case BreakerOpen:
+		return false, b.cooldown - b.now().Sub(b.openedAt)
+	case BreakerHalfOpen:
+		// Only one probe call is allowed at a time
+		if b.probing {
+			return false, b.cooldown
+		}
+
+		b.probing = true
+	case BreakerClosed:
+	}
+
+	return true, 0
+}
+
+// Success registers successful call and closes the breaker.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure registers failed call and opens the breaker if the threshold is reached.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
+
+// State returns current state of the breaker.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state()
+}
+
+func (b *Breaker) state() BreakerState {
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return BreakerClosed
+	}
+
+	if b.now().Sub(b.openedAt) < b.cooldown {
+		return BreakerOpen
+	}
+
+	return BreakerHalfOpen
+}
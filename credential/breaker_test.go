@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package credential
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	b := NewBreaker(2, 30*time.Second)
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	qt.Check(t, qt.Equals(b.State(), BreakerClosed))
+
+	b.Failure()
+	qt.Check(t, qt.Equals(b.State(), BreakerOpen))
+
+	ok, retryAfter := b.Allow()
+	qt.Check(t, qt.IsFalse(ok))
+	qt.Check(t, qt.Equals(retryAfter, 30*time.Second))
+
+	now = now.Add(30 * time.Second)
+	qt.Check(t, qt.Equals(b.State(), BreakerHalfOpen))
+
+	ok, _ = b.Allow()
+	qt.Check(t, qt.IsTrue(ok))
+
+	// Only single probe is allowed
+	ok, _ = b.Allow()
+	qt.Check(t, qt.IsFalse(ok))
+
+	// Failed probe opens breaker again
+	b.Failure()
+	qt.Check(t, qt.Equals(b.State(), BreakerOpen))
+
+	now = now.Add(30 * time.Second)
+	ok, _ = b.Allow()
+	qt.Check(t, qt.IsTrue(ok))
+
+	b.Success()
+	qt.Check(t, qt.Equals(b.State(), BreakerClosed))
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := NewBreaker(0, time.Second)
+
+	for range 10 {
+		b.Failure()
+	}
+
+	ok, _ := b.Allow()
+	qt.Check(t, qt.IsTrue(ok))
+	qt.Check(t, qt.Equals(b.State(), BreakerClosed))
+}
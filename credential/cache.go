@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package credential
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"azugo.io/azugo"
+	"azugo.io/core/cache"
+)
+
+const (
+	formCache = "edim-wallet-api-source-form"
+	// formLocks is the number of locks that serialize updates of the cached forms.
+	formLocks = 64
+)
+
+// Cache is the short-lived cache of the credential offer forms built from the source registry data.
+// Forms are encrypted at rest and cache keys are derived from the person code so that personal data
+// is never stored in the cache as plain text.
+type Cache struct {
+	ch    cache.Instance[string]
+	key   paseto.V4SymmetricKey
+	ttl   time.Duration
+	locks [formLocks]sync.Mutex
+}
+
+// NewCache creates credential offer form cache using base64 encoded 256-bit secret.
+func NewCache(c *cache.Cache, secret string, ttl time.Duration) (*Cache, error) {
+	b, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := paseto.V4SymmetricKeyFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := cache.Create[string](c, formCache, cache.DefaultTTL(ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache{
+		ch:  ch,
+		key: key,
+		ttl: ttl,
+	}, nil
+}
+
+// cacheKey returns keyed hash of the person code and the credential request type.
+func (c *Cache) cacheKey(personCode, requestType string) string {
+	mac := hmac.New(sha256.New, c.key.ExportBytes())
+	mac.Write([]byte(personCode))
+	mac.Write([]byte{0})
+	mac.Write([]byte(requestType))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// get returns cached forms of the person credential type by form variant and their expiration time.
+func (c *Cache) get(ctx *azugo.Context, key string) (map[string]json.RawMessage, time.Time, error) {
+	enc, err := c.ch.Get(ctx, key)
+	if err != nil || enc == "" {
+		return nil, time.Time{}, err
+	}
+
+	parser := paseto.NewParser()
+	parser.AddRule(paseto.ValidAt(time.Now()))
+
+	t, err := parser.ParseV4Local(c.key, enc, []byte(key))
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to decrypt cached form: %w", err)
+	}
+
+	exp, err := t.GetExpiration()
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to parse cached form: %w", err)
+	}
+
+	forms := make(map[string]json.RawMessage)
+	if err := t.Get("forms", &forms); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to parse cached form: %w", err)
+	}
+
+	return forms, exp, nil
+}
+
+// set stores forms of the person credential type until the expiration time. Cache key is bound
+// to the encrypted value so that the entries can not be swapped.
+func (c *Cache) set(ctx *azugo.Context, key string, forms map[string]json.RawMessage, exp time.Time) error {
+	ttl := time.Until(exp)
+	if ttl <= 0 {
+		return nil
+	}
+
+	t := paseto.NewToken()
+	t.SetIssuedAt(time.Now())
+	t.SetExpiration(exp)
+
+	if err := t.Set("forms", forms); err != nil {
+		return err
+	}
+
+	return c.ch.Set(ctx, key, t.V4Encrypt(c.key, []byte(key)), cache.TTL[string](ttl))
+}
+
+// lock returns the lock of the cache entry.
+func (c *Cache) lock(key string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+
+	return &c.locks[h.Sum32()%formLocks]
+}
+
+// add stores form variant of the person credential type. Cached forms are read again under the lock
+// so that concurrent requests of other form variants are not lost. Other form variants are kept only
+// until the original expiration time. Unreadable cache entry is replaced.
+func (c *Cache) add(ctx *azugo.Context, key, variant string, form json.RawMessage) error {
+	mu := c.lock(key)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	forms, exp, err := c.get(ctx, key)
+	if err != nil || forms == nil {
+		forms = make(map[string]json.RawMessage, 1)
+		exp = time.Now().Add(c.ttl)
+	}
+
+	forms[variant] = form
+
+	return c.set(ctx, key, forms, exp)
+}
+
+// Invalidate removes cached forms of the person credential type.
+func (c *Cache) Invalidate(ctx *azugo.Context, personCode, requestType string) error {
+	key := c.cacheKey(personCode, requestType)
+
+	mu := c.lock(key)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	return c.ch.Delete(ctx, key)
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package credential
+
+import (
+	"strings"
+	"testing"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/go-quicktest/qt"
+)
+
+func TestCacheKey(t *testing.T) {
+	c := &Cache{key: paseto.NewV4SymmetricKey()}
+
+	key := c.cacheKey("32345678901", "pid")
+
+	qt.Check(t, qt.Equals(c.cacheKey("32345678901", "pid"), key))
+	qt.Check(t, qt.Not(qt.Equals(c.cacheKey("32345678901", "mdl"), key)))
+	qt.Check(t, qt.Not(qt.Equals(c.cacheKey("3234567890", "1pid"), key)))
+	qt.Check(t, qt.IsFalse(strings.Contains(key, "32345678901")))
+
+	other := &Cache{key: paseto.NewV4SymmetricKey()}
+	qt.Check(t, qt.Not(qt.Equals(other.cacheKey("32345678901", "pid"), key)))
+}
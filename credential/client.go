@@ -0,0 +1,254 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package credential
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"azugo.io/azugo"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// HealthCheckTimeout is the maximum duration of the source registry health check request.
+	HealthCheckTimeout = 2 * time.Second
+	// HealthCheckCacheTTL is the duration the source registry health check result is reused, so that
+	// calls of the unauthenticated health endpoint do not load the source registry.
+	HealthCheckCacheTTL = 10 * time.Second
+)
+
+// ClientOptions are the resilience options of the source registry API client.
+type ClientOptions struct {
+	// Timeout of a single request.
+	Timeout time.Duration
+	// Retries is the number of times failed idempotent request is retried.
+	Retries int
+	// RetryBackoff is the base delay between retries that is doubled after each retry.
+	RetryBackoff time.Duration
+	// BreakerThreshold is the number of consecutive failed calls after which the circuit breaker opens.
+	BreakerThreshold int
+	// BreakerCooldown is the time the circuit breaker stays open before allowing a probe call.
+	BreakerCooldown time.Duration
+}
+
+// Client calls source registry API on behalf of the user.
+type Client struct {
+	name    string
+	url     string
+	opts    ClientOptions
+	breaker *Breaker
+
+	health       singleflight.Group
+	healthClient fasthttp.Client
+	healthMu     sync.Mutex
+	healthAt     time.Time
+	healthErr    error
+
+	now func() time.Time
+}
+
+// NewClient creates source registry API client.
+func NewClient(name, url string, opts ClientOptions) *Client {
+	return &Client{
+		name:    name,
+		url:     strings.TrimSuffix(url, "/"),
+		opts:    opts,
+		breaker: NewBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+		now:     time.Now,
+	}
+}
+
+// BreakerState returns the state of the source registry circuit breaker.
+func (c *Client) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+// GetJSON calls source registry API passing user authorization and parses JSON response.
+// Failed calls are retried and fail fast with UnavailableError while the circuit breaker is open.
+// Source registry error responses are mapped to typed errors:
+//   - 404 Not Found to NotFoundError
+//   - 410 Gone to PersonInactiveError with ReasonDeceased
+//   - 423 Locked to PersonInactiveError with ReasonBlocked
+//   - 401 Unauthorized and 403 Forbidden to UnauthorizedError
+//   - transport errors, 429 Too Many Requests and 5xx after all retries to UnavailableError
+func (c *Client) GetJSON(ctx *azugo.Context, path string, v any) error {
+	ok, retryAfter := c.breaker.Allow()
+	if !ok {
+		return UnavailableError{
+			Source:     c.name,
+			RetryAfter: retryAfter,
+		}
+	}
+
+	var (
+		body  []byte
+		retry bool
+		err   error
+	)
+
+	for attempt := 0; ; attempt++ {
+		body, retry, err = c.get(ctx, path)
+		if err == nil || !retry || attempt >= c.opts.Retries {
+			break
+		}
+
+		if !c.wait(ctx, attempt) {
+			break
+		}
+	}
+
+	// Client errors mean that registry is available
+	if retry {
+		c.breaker.Failure()
+
+		return UnavailableError{
+			Source: c.name,
+			Err:    err,
+		}
+	}
+
+	c.breaker.Success()
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", c.name, err)
+	}
+
+	return nil
+}
+
+// get does a single GET request. Returns if the failed request can be retried.
+func (c *Client) get(ctx *azugo.Context, path string) ([]byte, bool, error) {
+	client := ctx.HTTPClient()
+
+	req := client.NewRequest()
+	defer client.ReleaseRequest(req)
+
+	if err := req.SetRequestURL(c.url + path); err != nil {
+		return nil, false, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodGet)
+	req.Header.Set(fasthttp.HeaderAccept, "application/json")
+
+	if auth := ctx.Header.Get(fasthttp.HeaderAuthorization); auth != "" {
+		req.Header.Set(fasthttp.HeaderAuthorization, auth)
+	}
+
+	if c.opts.Timeout > 0 {
+		req.SetTimeout(c.opts.Timeout)
+	}
+
+	resp := client.NewResponse()
+	defer client.ReleaseResponse(resp)
+
+	if err := client.Do(req, resp); err != nil {
+		return nil, true, fmt.Errorf("failed to call %s: %w", c.name, err)
+	}
+
+	switch status := resp.StatusCode(); {
+	case status == fasthttp.StatusNotFound:
+		return nil, false, NotFoundError{Source: c.name}
+	case status == fasthttp.StatusGone:
+		return nil, false, PersonInactiveError{Source: c.name, Reason: ReasonDeceased}
+	case status == fasthttp.StatusLocked:
+		return nil, false, PersonInactiveError{Source: c.name, Reason: ReasonBlocked}
+	case status == fasthttp.StatusUnauthorized || status == fasthttp.StatusForbidden:
+		return nil, false, UnauthorizedError{Source: c.name, Status: status}
+	case status >= fasthttp.StatusInternalServerError || status == fasthttp.StatusTooManyRequests:
+		return nil, true, fmt.Errorf("%s responded with status code %d", c.name, status)
+	case status < fasthttp.StatusOK || status >= fasthttp.StatusMultipleChoices:
+		return nil, false, fmt.Errorf("%s responded with status code %d", c.name, status)
+	}
+
+	return append([]byte(nil), resp.Body()...), false, nil
+}
+
+// wait sleeps before the next retry using exponential backoff with full jitter.
+// Returns false if the request has been cancelled.
+func (c *Client) wait(ctx *azugo.Context, attempt int) bool {
+	backoff := c.opts.RetryBackoff << attempt
+	if backoff <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(rand.N(backoff) + 1)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// HealthCheck checks source registry API health endpoint. Result of the check is reused for HealthCheckCacheTTL
+// and concurrent checks of the credential sources that share the client make a single request.
+func (c *Client) HealthCheck(ctx context.Context) error {
+	c.healthMu.Lock()
+
+	if !c.healthAt.IsZero() && c.now().Sub(c.healthAt) < HealthCheckCacheTTL {
+		err := c.healthErr
+		c.healthMu.Unlock()
+
+		return err
+	}
+
+	c.healthMu.Unlock()
+
+	_, err, _ := c.health.Do("healthz", func() (any, error) {
+		err := c.healthCheck(ctx)
+
+		c.healthMu.Lock()
+		c.healthAt, c.healthErr = c.now(), err
+		c.healthMu.Unlock()
+
+		return nil, err
+	})
+
+	return err
+}
+
+// healthCheck does a single health check request within the context deadline and HealthCheckTimeout.
+// Health check is not made on behalf of the user, so it does not use the request context.
+func (c *Client) healthCheck(ctx context.Context) error {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.SetRequestURI(c.url + "/healthz")
+	req.Header.SetMethod(fasthttp.MethodGet)
+
+	timeout := HealthCheckTimeout
+	if c.opts.Timeout > 0 {
+		timeout = min(timeout, c.opts.Timeout)
+	}
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.healthClient.DoDeadline(req, resp, deadline); err != nil {
+		return err
+	}
+
+	if resp.StatusCode() >= fasthttp.StatusMultipleChoices {
+		return fmt.Errorf("%s health check responded with status code %d", c.name, resp.StatusCode())
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package credential
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
+)
+
+// healthServer starts source registry fake that responds to the health check with the status after the delay.
+func healthServer(t *testing.T, status *atomic.Int32, delay time.Duration) (string, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	qt.Assert(t, qt.IsNil(err))
+
+	srv := &fasthttp.Server{
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			calls.Add(1)
+			time.Sleep(delay)
+			ctx.SetStatusCode(int(status.Load()))
+		},
+	}
+
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+
+	t.Cleanup(func() {
+		_ = srv.Shutdown()
+	})
+
+	return "http://" + ln.Addr().String(), &calls
+}
+
+func TestClientHealthCheck(t *testing.T) {
+	var status atomic.Int32
+
+	status.Store(fasthttp.StatusOK)
+
+	url, calls := healthServer(t, &status, 0)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := NewClient("FPRIS", url, ClientOptions{})
+	c.now = func() time.Time { return now }
+
+	qt.Check(t, qt.IsNil(c.HealthCheck(context.Background())))
+	qt.Check(t, qt.Equals(calls.Load(), int32(1)))
+
+	// Result is reused while cached
+	status.Store(fasthttp.StatusServiceUnavailable)
+
+	qt.Check(t, qt.IsNil(c.HealthCheck(context.Background())))
+	qt.Check(t, qt.Equals(calls.Load(), int32(1)))
+
+	now = now.Add(HealthCheckCacheTTL)
+
+	qt.Check(t, qt.ErrorMatches(c.HealthCheck(context.Background()), "FPRIS health check responded with status code 503"))
+	qt.Check(t, qt.Equals(calls.Load(), int32(2)))
+
+	// Failed result is cached as well
+	qt.Check(t, qt.ErrorMatches(c.HealthCheck(context.Background()), "FPRIS health check responded with status code 503"))
+	qt.Check(t, qt.Equals(calls.Load(), int32(2)))
+}
+
+func TestClientHealthCheck_Deadline(t *testing.T) {
+	var status atomic.Int32
+
+	status.Store(fasthttp.StatusOK)
+
+	url, _ := healthServer(t, &status, time.Second)
+
+	c := NewClient("FPRIS", url, ClientOptions{Timeout: 10 * time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	// Context deadline is shorter than the health check timeout
+	qt.Check(t, qt.IsNotNil(c.HealthCheck(ctx)))
+	qt.Check(t, qt.IsTrue(time.Since(start) < 500*time.Millisecond), qt.Commentf("health check took %s", time.Since(start)))
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package credential
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"azugo.io/core/config"
+	"azugo.io/core/validation"
+	"github.com/spf13/viper"
+)
+
+// maxCacheTTL is the maximum time the source registry data can be cached.
+const maxCacheTTL = 10 * time.Minute
+
+// Configuration of the source registry API clients.
+type Configuration struct {
+	Retries          int           `mapstructure:"retries" validate:"gte=0"`
+	RetryBackoff     time.Duration `mapstructure:"retry_backoff" validate:"gte=0"`
+	BreakerThreshold int           `mapstructure:"breaker_threshold" validate:"gte=0"`
+	BreakerCooldown  time.Duration `mapstructure:"breaker_cooldown" validate:"required,gt=0"`
+	FprisTimeout     time.Duration `mapstructure:"fpris_timeout" validate:"required,gt=0"`
+	RTUTimeout       time.Duration `mapstructure:"rtu_timeout" validate:"required,gt=0"`
+	MDLTimeout       time.Duration `mapstructure:"mdl_timeout" validate:"required,gt=0"`
+	EHICTimeout      time.Duration `mapstructure:"ehic_timeout" validate:"required,gt=0"`
+	PDA1Timeout      time.Duration `mapstructure:"pda1_timeout" validate:"required,gt=0"`
+	CacheTTL         time.Duration `mapstructure:"cache_ttl" validate:"gte=0"`
+	CacheSecret      string        `mapstructure:"cache_secret" validate:"omitempty,base64"`
+}
+
+func (c *Configuration) Bind(prefix string, v *viper.Viper) {
+	v.SetDefault(prefix+".retries", 2)
+	v.SetDefault(prefix+".retry_backoff", 200*time.Millisecond)
+	v.SetDefault(prefix+".breaker_threshold", 5)
+	v.SetDefault(prefix+".breaker_cooldown", 30*time.Second)
+	v.SetDefault(prefix+".fpris_timeout", 10*time.Second)
+	v.SetDefault(prefix+".rtu_timeout", 10*time.Second)
+	v.SetDefault(prefix+".mdl_timeout", 10*time.Second)
+	v.SetDefault(prefix+".ehic_timeout", 10*time.Second)
+	v.SetDefault(prefix+".pda1_timeout", 10*time.Second)
+
+	cacheSecret, _ := config.LoadRemoteSecret("UPSTREAM_CACHE_SECRET")
+	v.SetDefault(prefix+".cache_secret", cacheSecret)
+
+	_ = v.BindEnv(prefix+".retries", "UPSTREAM_RETRIES")
+	_ = v.BindEnv(prefix+".retry_backoff", "UPSTREAM_RETRY_BACKOFF")
+	_ = v.BindEnv(prefix+".breaker_threshold", "UPSTREAM_BREAKER_THRESHOLD")
+	_ = v.BindEnv(prefix+".breaker_cooldown", "UPSTREAM_BREAKER_COOLDOWN")
+	_ = v.BindEnv(prefix+".fpris_timeout", "FPRIS_API_TIMEOUT")
+	_ = v.BindEnv(prefix+".rtu_timeout", "RTU_API_TIMEOUT")
+	_ = v.BindEnv(prefix+".mdl_timeout", "MDL_API_TIMEOUT")
+	_ = v.BindEnv(prefix+".ehic_timeout", "EHIC_API_TIMEOUT")
+	_ = v.BindEnv(prefix+".pda1_timeout", "PDA1_API_TIMEOUT")
+	_ = v.BindEnv(prefix+".cache_ttl", "UPSTREAM_CACHE_TTL")
+	_ = v.BindEnv(prefix+".cache_secret", "UPSTREAM_CACHE_SECRET")
+}
+
+// Validate source registry API clients configuration section.
+func (c *Configuration) Validate(valid *validation.Validate) error {
+	if err := valid.Struct(c); err != nil {
+		return err
+	}
+
+	// Cache is disabled by default
+	if c.CacheTTL == 0 {
+		return nil
+	}
+
+	// Personal data must not be kept longer than needed to serve repeated requests
+	if c.CacheTTL > maxCacheTTL {
+		return fmt.Errorf("cache_ttl must not exceed %s", maxCacheTTL)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(c.CacheSecret)
+	if err != nil {
+		return errors.New("cache_secret must be a valid base64 encoded string")
+	}
+
+	if len(b) != 32 {
+		return errors.New("cache_secret must be exactly 32 bytes long")
+	}
+
+	return nil
+}
+
+// ClientOptions returns source registry API client options with the timeout.
+func (c *Configuration) ClientOptions(timeout time.Duration) ClientOptions {
+	return ClientOptions{
+		Timeout:          timeout,
+		Retries:          c.Retries,
+		RetryBackoff:     c.RetryBackoff,
+		BreakerThreshold: c.BreakerThreshold,
+		BreakerCooldown:  c.BreakerCooldown,
+	}
+}
@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package credential
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"azugo.io/azugo"
+	"go.uber.org/zap"
+)
+
+// Source provides person data for the credential type from the source registry.
+type Source interface {
+	// Type returns credential request type used in the API path.
+	Type() string
+	// ConfigurationIDs returns upstream issuer credential configuration IDs offered for the credential type.
+	ConfigurationIDs() []string
+	// Form fetches person data from the source registry and maps it to the upstream issuer credential offer form.
+	// Returns http.NotFoundError if the source registry has no data about the person.
+	Form(ctx *azugo.Context) (any, error)
+	// Errors returns error messages of the credential type.
+	Errors() Errors
+	// HealthCheck checks if the source registry is available. Check is not made on behalf of the user,
+	// so it can be called concurrently with the context that is not bound to the request.
+	HealthCheck(ctx context.Context) error
+	// BreakerState returns the state of the source registry circuit breaker.
+	BreakerState() BreakerState
+}
+
+// Variant is implemented by credential sources whose form depends on the request parameters.
+type Variant interface {
+	// FormVariant returns the form variant selected by the request parameters.
+	FormVariant(ctx *azugo.Context) string
+}
+
+// DiplomaLister is implemented by credential sources that issue one of the person diplomas selected by the user.
+type DiplomaLister interface {
+	// ListDiplomas returns diplomas of the person that can be selected for the credential offer.
+	ListDiplomas(ctx *azugo.Context) ([]Diploma, error)
+}
+
+// Diploma is the summary of the diploma that can be selected for the credential offer.
+type Diploma struct {
+	// ID is the diploma identifier in the source registry
+	ID string
+	// Type is the type of the diploma
+	Type string
+	// Title is the title of the diploma
+	Title string
+	// AwardingBody is the legal name of the awarding body
+	AwardingBody string
+	// AwardingDate is the awarding date of the diploma
+	AwardingDate time.Time
+}
+
+// Errors contains error messages of the credential type.
+type Errors struct {
+	// NotFound is returned to the user when the source registry has no data about the person.
+	NotFound string
+	// Failed is used to wrap errors when the source registry call fails.
+	Failed string
+}
+
+// Registry maps credential request types to their sources.
+type Registry struct {
+	sources map[string]Source
+	types   []string
+	cache   *Cache
+}
+
+// NewRegistry creates credential source registry.
+func NewRegistry(sources ...Source) (*Registry, error) {
+	r := &Registry{
+		sources: make(map[string]Source, len(sources)),
+		types:   make([]string, 0, len(sources)),
+	}
+
+	for _, s := range sources {
+		if err := r.Register(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Register adds credential source to the registry.
+func (r *Registry) Register(s Source) error {
+	if _, ok := r.sources[s.Type()]; ok {
+		return fmt.Errorf("credential source %s is already registered", s.Type())
+	}
+
+	r.sources[s.Type()] = s
+	r.types = append(r.types, s.Type())
+
+	return nil
+}
+
+// SetCache enables caching of the credential offer forms.
+func (r *Registry) SetCache(c *Cache) {
+	r.cache = c
+}
+
+// Form returns credential offer form of the person from the cache or the source registry. Form is always
+// returned as JSON so that the result does not depend on whether it was cached.
+// Cache failures are logged and the form is fetched from the source registry. Errors are not cached.
+func (r *Registry) Form(ctx *azugo.Context, source Source, personCode string) (json.RawMessage, error) {
+	if r.cache == nil {
+		return sourceForm(ctx, source)
+	}
+
+	var variant string
+	if v, ok := source.(Variant); ok {
+		variant = v.FormVariant(ctx)
+	}
+
+	key := r.cache.cacheKey(personCode, source.Type())
+
+	forms, _, err := r.cache.get(ctx, key)
+	if err != nil {
+		ctx.Log().Warn("failed to get cached credential offer form", zap.Error(err))
+	}
+
+	if form, ok := forms[variant]; ok {
+		return form, nil
+	}
+
+	form, err := sourceForm(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.cache.add(ctx, key, variant, form); err != nil {
+		ctx.Log().Warn("failed to cache credential offer form", zap.Error(err))
+	}
+
+	return form, nil
+}
+
+// sourceForm fetches credential offer form from the source registry.
+func sourceForm(ctx *azugo.Context, source Source) (json.RawMessage, error) {
+	form, err := source.Form(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(form)
+}
+
+// Invalidate removes cached credential offer forms of the person credential type.
+func (r *Registry) Invalidate(ctx *azugo.Context, personCode, requestType string) error {
+	if r.cache == nil {
+		return nil
+	}
+
+	return r.cache.Invalidate(ctx, personCode, requestType)
+}
+
+// Source returns credential source for the request type.
+func (r *Registry) Source(requestType string) (Source, bool) {
+	s, ok := r.sources[requestType]
+
+	return s, ok
+}
+
+// Types returns registered credential request types in the order of registration.
+func (r *Registry) Types() []string {
+	return append([]string(nil), r.types...)
+}
+
+// Sources returns registered credential sources in the order of registration.
+func (r *Registry) Sources() []Source {
+	res := make([]Source, 0, len(r.types))
+	for _, t := range r.types {
+		res = append(res, r.sources[t])
+	}
+
+	return res
+}
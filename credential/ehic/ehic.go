@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+//nolint:tagliatelle
+package ehic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/credential"
+	"git.zzdats.lv/edim/api-wallet/credential/validation"
+	"git.zzdats.lv/edim/api-wallet/util"
+
+	"azugo.io/azugo"
+)
+
+// Card defines the response structure for the national health service EHIC data.
+type Card struct {
+	// PersonalAdministrativeNumber represents card holder's personal identification number
+	PersonalAdministrativeNumber string `json:"personal_administrative_number"`
+	// GivenName represents card holder's name
+	GivenName string `json:"given_name"`
+	// FamilyName represents card holder's surname
+	FamilyName string `json:"family_name"`
+	// BirthDate represents card holder's date of birth
+	BirthDate util.Date `json:"birth_date"`
+	// DocumentNumber represents card identification number
+	DocumentNumber string `json:"document_number"`
+	// InstitutionID represents competent institution identification number
+	InstitutionID string `json:"institution_id"`
+	// InstitutionName represents competent institution name
+	InstitutionName string `json:"institution_name"`
+	// IssuingCountry represents card issuing country code (ISO 3166-1 alpha-2)
+	IssuingCountry string `json:"issuing_country"`
+	// IssuingAuthority represents issuing authority
+	IssuingAuthority string `json:"issuing_authority"`
+	// IssueDate represents card start date of validity
+	IssueDate util.Date `json:"issue_date"`
+	// ExpiryDate represents card expiration date
+	ExpiryDate util.Date `json:"expiry_date"`
+}
+
+// Validate checks and normalizes EHIC data.
+func (c *Card) Validate() error {
+	v := validation.New("ehic")
+	v.Required("personal_administrative_number", &c.PersonalAdministrativeNumber)
+	v.Required("given_name", &c.GivenName)
+	v.Required("family_name", &c.FamilyName)
+	v.Date("birth_date", time.Time(c.BirthDate))
+	v.Required("document_number", &c.DocumentNumber)
+	v.Required("institution_id", &c.InstitutionID)
+	v.Country("issuing_country", &c.IssuingCountry)
+	v.DateRange("issue_date", time.Time(c.IssueDate), "expiry_date", time.Time(c.ExpiryDate))
+
+	return v.Err()
+}
+
+// Form is the upstream issuer EHIC credential offer form.
+type Form struct {
+	PersonalAdministrativeNumber string    `json:"personal_administrative_number"`
+	GivenName                    string    `json:"given_name"`
+	FamilyName                   string    `json:"family_name"`
+	BirthDate                    util.Date `json:"birth_date"`
+	DocumentNumber               string    `json:"document_number"`
+	CompetentInstitutionID       string    `json:"competent_institution_id"`
+	CompetentInstitutionName     string    `json:"competent_institution_name"`
+	IssuingCountry               string    `json:"issuing_country"`
+	IssuingAuthority             string    `json:"issuing_authority"`
+	StartingDate                 util.Date `json:"starting_date"`
+	EndingDate                   util.Date `json:"ending_date"`
+}
+
+// Source of the European Health Insurance Card (EHIC) credential.
+type Source struct {
+	client *credential.Client
+}
+
+// New creates EHIC credential source using national health service API.
+func New(client *credential.Client) *Source {
+	return &Source{
+		client: client,
+	}
+}
+
+func (s *Source) Type() string {
+	return "ehic"
+}
+
+func (s *Source) ConfigurationIDs() []string {
+	return []string{"eu.europa.ec.eudi.ehic_sd_jwt_vc"}
+}
+
+func (s *Source) Errors() credential.Errors {
+	return credential.Errors{
+		NotFound: "Data about health insurance card not found",
+		Failed:   "failed to call EHIC",
+	}
+}
+
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return s.client.HealthCheck(ctx)
+}
+
+func (s *Source) BreakerState() credential.BreakerState {
+	return s.client.BreakerState()
+}
+
+// Data returns EHIC data from the national health service.
+func (s *Source) Data(ctx *azugo.Context) (*Card, error) {
+	res := &Card{}
+
+	if err := s.client.GetJSON(ctx, "/1.0/ehic", res); err != nil {
+		return nil, fmt.Errorf("failed to call EHIC: %w", err)
+	}
+
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := credential.CheckExpiry("EHIC", time.Time(res.ExpiryDate), time.Now()); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s *Source) Form(ctx *azugo.Context) (any, error) {
+	res, err := s.Data(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Form{
+		PersonalAdministrativeNumber: res.PersonalAdministrativeNumber,
+		GivenName:                    res.GivenName,
+		FamilyName:                   res.FamilyName,
+		BirthDate:                    res.BirthDate,
+		DocumentNumber:               res.DocumentNumber,
+		CompetentInstitutionID:       res.InstitutionID,
+		CompetentInstitutionName:     res.InstitutionName,
+		IssuingCountry:               res.IssuingCountry,
+		IssuingAuthority:             res.IssuingAuthority,
+		StartingDate:                 res.IssueDate,
+		EndingDate:                   res.ExpiryDate,
+	}, nil
+}
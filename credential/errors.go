@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package credential
+
+import (
+	"strconv"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/credential/validation"
+
+	"azugo.io/core/http"
+	"github.com/valyala/fasthttp"
+)
+
+// Stable error codes returned to the wallet app when credential can not be issued from the source registry data.
+const (
+	// CodePersonNotFound is returned when the source registry has no data about the person.
+	CodePersonNotFound = "person_not_found"
+	// CodePersonInactive is returned when the person is deceased or blocked in the source registry.
+	CodePersonInactive = "person_inactive"
+	// CodeDocumentExpired is returned when the document in the source registry has expired.
+	CodeDocumentExpired = "document_expired"
+	// CodeUpstreamUnauthorized is returned when the source registry rejects the call.
+	CodeUpstreamUnauthorized = "upstream_unauthorized"
+	// CodeUpstreamUnavailable is returned when the source registry is unavailable.
+	CodeUpstreamUnavailable = "upstream_unavailable"
+	// CodeDataInvalid is returned when the source registry data fails validation.
+	CodeDataInvalid = validation.CodeDataInvalid
+)
+
+// Reasons why the person is inactive in the source registry.
+const (
+	ReasonDeceased = "deceased"
+	ReasonBlocked  = "blocked"
+)
+
+// CodedError is the source registry error that has a stable error code.
+type CodedError interface {
+	error
+	StatusCode() int
+	ErrorCode() string
+}
+
+// NotFoundError is returned when the source registry has no data about the person.
+type NotFoundError struct {
+	// Source is the source registry name
+	Source string
+}
+
+func (e NotFoundError) Error() string {
+	return e.Source + " has no data about the person"
+}
+
+func (NotFoundError) StatusCode() int {
+	return fasthttp.StatusNotFound
+}
+
+func (NotFoundError) ErrorCode() string {
+	return CodePersonNotFound
+}
+
+// Is allows NotFoundError to be handled as http.NotFoundError.
+func (NotFoundError) Is(target error) bool {
+	_, ok := target.(http.NotFoundError)
+
+	return ok
+}
+
+// PersonInactiveError is returned when the person is deceased or blocked in the source registry.
+type PersonInactiveError struct {
+	// Source is the source registry name
+	Source string
+	// Reason is ReasonDeceased or ReasonBlocked
+	Reason string
+}
+
+func (e PersonInactiveError) Error() string {
+	return "person is " + e.Reason + " in " + e.Source
+}
+
+func (PersonInactiveError) StatusCode() int {
+	return fasthttp.StatusForbidden
+}
+
+func (PersonInactiveError) ErrorCode() string {
+	return CodePersonInactive
+}
+
+// DocumentExpiredError is returned when the document in the source registry has expired.
+type DocumentExpiredError struct {
+	// Source is the source registry name
+	Source string
+	// ExpiryDate is the expiry date of the document
+	ExpiryDate time.Time
+}
+
+func (e DocumentExpiredError) Error() string {
+	return e.Source + " document expired on " + e.ExpiryDate.Format(time.DateOnly)
+}
+
+func (DocumentExpiredError) StatusCode() int {
+	return fasthttp.StatusUnprocessableEntity
+}
+
+func (DocumentExpiredError) ErrorCode() string {
+	return CodeDocumentExpired
+}
+
+// CheckExpiry returns DocumentExpiredError if the document has expired before the provided time.
+// Document is valid until the end of the expiry date.
+func CheckExpiry(source string, expiryDate, now time.Time) error {
+	if expiryDate.IsZero() || now.Before(expiryDate.AddDate(0, 0, 1)) {
+		return nil
+	}
+
+	return DocumentExpiredError{
+		Source:     source,
+		ExpiryDate: expiryDate,
+	}
+}
+
+// UnauthorizedError is returned when the source registry rejects the call with 401 or 403 status code.
+type UnauthorizedError struct {
+	// Source is the source registry name
+	Source string
+	// Status is the status code returned by the source registry
+	Status int
+}
+
+func (e UnauthorizedError) Error() string {
+	return e.Source + " rejected the call with status code " + strconv.Itoa(e.Status)
+}
+
+// StatusCode is 502 as the wallet API and not the user is not authorized to call the source registry.
+func (UnauthorizedError) StatusCode() int {
+	return fasthttp.StatusBadGateway
+}
+
+func (UnauthorizedError) ErrorCode() string {
+	return CodeUpstreamUnauthorized
+}
+
+// UnavailableError is returned when the source registry is unavailable or its circuit breaker is open.
+type UnavailableError struct {
+	// Source is the source registry name
+	Source string
+	// RetryAfter is the time after which the call can be retried, zero if unknown
+	RetryAfter time.Duration
+	// Err is the last failed call error, nil if the circuit breaker is open
+	Err error
+}
+
+func (e UnavailableError) Error() string {
+	if e.Err != nil {
+		return e.Source + " is temporarily unavailable: " + e.Err.Error()
+	}
+
+	return e.Source + " is temporarily unavailable"
+}
+
+func (e UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+func (UnavailableError) StatusCode() int {
+	return fasthttp.StatusServiceUnavailable
+}
+
+func (UnavailableError) ErrorCode() string {
+	return CodeUpstreamUnavailable
+}
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package credential
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"azugo.io/core/http"
+	"github.com/go-quicktest/qt"
+)
+
+func TestCheckExpiry(t *testing.T) {
+	expiry := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	qt.Check(t, qt.IsNil(CheckExpiry("MDL", time.Time{}, time.Now())))
+	qt.Check(t, qt.IsNil(CheckExpiry("MDL", expiry, expiry.Add(23*time.Hour))))
+
+	err := CheckExpiry("MDL", expiry, expiry.AddDate(0, 0, 1))
+	qt.Check(t, qt.ErrorAs(err, new(DocumentExpiredError)))
+	qt.Check(t, qt.Equals(err.(CodedError).ErrorCode(), CodeDocumentExpired))
+}
+
+func TestNotFoundErrorIsHTTPNotFound(t *testing.T) {
+	err := fmt.Errorf("failed to call FPRIS: %w", NotFoundError{Source: "FPRIS"})
+
+	qt.Check(t, qt.IsTrue(errors.Is(err, http.NotFoundError{})))
+}
+
+func TestUnavailableErrorUnwrap(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := UnavailableError{Source: "RTU", Err: cause}
+
+	qt.Check(t, qt.ErrorIs(err, cause))
+	qt.Check(t, qt.Equals(err.ErrorCode(), CodeUpstreamUnavailable))
+}
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+//nolint:tagliatelle
+package mdl
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/credential"
+	"git.zzdats.lv/edim/api-wallet/credential/mdl/portrait"
+	"git.zzdats.lv/edim/api-wallet/credential/validation"
+	"git.zzdats.lv/edim/api-wallet/util"
+
+	"azugo.io/azugo"
+)
+
+// CategoryRestriction defines the driving privilege category restriction.
+type CategoryRestriction struct {
+	// Sign as per ISO/IEC 18013-2 Annex A
+	Sign string `json:"sign"`
+	// Value as per ISO/IEC 18013-2 Annex A
+	Value string `json:"value"`
+}
+
+// DrivingPrivilege defines driving license categories.
+type DrivingPrivilege struct {
+	// Driver category code
+	VehicleCategoryCode string `json:"vehicle_category_code"`
+	// Starting date of validity of the driver category
+	IssueDate util.Date `json:"issue_date"`
+	// Driver category expiry date
+	ExpiryDate util.Date `json:"expiry_date"`
+	// Category restrictions
+	Code []CategoryRestriction `json:"code"`
+}
+
+// DrivingLicence defines the response structure for the CSDD data.
+type DrivingLicence struct {
+	// PersonalAdministrativeNumber represents driver's administrative number
+	PersonalAdministrativeNumber string `json:"personal_administrative_number"`
+	// DocumentNumber represents document certificate number
+	DocumentNumber string `json:"document_number"`
+	// BirthData represents driver's date of birth
+	BirthDate util.Date `json:"birth_date"`
+	// GivenName represents driver's name
+	GivenName string `json:"given_name"`
+	// FamilyName represents driver's surname
+	FamilyName string `json:"family_name"`
+	// IssueDate represents driver's license start date of validity
+	IssueDate util.Date `json:"issue_date"`
+	// ExpireDate reprsents driver's license expiration date
+	ExpiryDate util.Date `json:"expiry_date"`
+	// IssuingCountry represents driving license issuing country code (ISO 3166-1 alpha-2)
+	IssuingCountry string `json:"issuing_country"`
+	// IssuingAuthority represents issuing authority
+	IssuingAuthority string `json:"issuing_authority"`
+	// DrivingPrivileges represents driving license categories
+	DrivingPrivileges []DrivingPrivilege `json:"driving_privileges"`
+	// UnDistinguishingSign represents distinguishing sign of the issuing country according to ISO/IEC 18013-1:2018
+	UnDistinguishingSign string `json:"un_distinguishing_sign"`
+	// Portrait represent photo of the driver of the vehicle
+	Portrait string `json:"portrait"`
+	// PortraitCaptureDate represents date when the portrait was taken
+	PortraitCaptureDate *util.Date `json:"portrait_capture_date,omitempty"`
+}
+
+// Validate checks and normalizes CSDD data.
+func (d *DrivingLicence) Validate() error {
+	v := validation.New("mdl")
+	v.Required("given_name", &d.GivenName)
+	v.Required("family_name", &d.FamilyName)
+	v.Date("birth_date", time.Time(d.BirthDate))
+	v.Required("document_number", &d.DocumentNumber)
+	v.DateRange("issue_date", time.Time(d.IssueDate), "expiry_date", time.Time(d.ExpiryDate))
+	v.Country("issuing_country", &d.IssuingCountry)
+	v.Required("issuing_authority", &d.IssuingAuthority)
+	v.DistinguishingSign("un_distinguishing_sign", &d.UnDistinguishingSign)
+	v.Required("portrait", &d.Portrait)
+
+	if len(d.DrivingPrivileges) == 0 {
+		v.Fail("driving_privileges", validation.TagRequired)
+	}
+
+	for i := range d.DrivingPrivileges {
+		p := &d.DrivingPrivileges[i]
+		field := "driving_privileges[" + strconv.Itoa(i) + "]."
+
+		v.VehicleCategory(field+"vehicle_category_code", &p.VehicleCategoryCode)
+
+		// Category dates are optional, but must be a valid range if both are present
+		if !time.Time(p.IssueDate).IsZero() && !time.Time(p.ExpiryDate).IsZero() {
+			v.DateRange(field+"issue_date", time.Time(p.IssueDate), field+"expiry_date", time.Time(p.ExpiryDate))
+		}
+	}
+
+	return v.Err()
+}
+
+// Source of the mobile driving licence (mDL) credential.
+type Source struct {
+	client   *credential.Client
+	portrait portrait.Options
+}
+
+// New creates mDL credential source using MDL API.
+func New(client *credential.Client, portraitOpts portrait.Options) *Source {
+	return &Source{
+		client:   client,
+		portrait: portraitOpts,
+	}
+}
+
+func (s *Source) Type() string {
+	return "mdl"
+}
+
+func (s *Source) ConfigurationIDs() []string {
+	return []string{"eu.europa.ec.eudi.mdl_mdoc"}
+}
+
+func (s *Source) Errors() credential.Errors {
+	return credential.Errors{
+		NotFound: "Data about drivers licence not found",
+		Failed:   "failed to call MDL",
+	}
+}
+
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return s.client.HealthCheck(ctx)
+}
+
+func (s *Source) BreakerState() credential.BreakerState {
+	return s.client.BreakerState()
+}
+
+func (s *Source) Form(ctx *azugo.Context) (any, error) {
+	res := &DrivingLicence{}
+
+	if err := s.client.GetJSON(ctx, "/1.0/mdl", res); err != nil {
+		return nil, fmt.Errorf("failed to call MDL: %w", err)
+	}
+
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := credential.CheckExpiry("MDL", time.Time(res.ExpiryDate), time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.processPortrait(res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// processPortrait checks portrait format and re-encodes it to fit the configured limits without metadata.
+func (s *Source) processPortrait(d *DrivingLicence) error {
+	v := validation.New("mdl")
+
+	data, err := base64.StdEncoding.DecodeString(d.Portrait)
+	if err != nil {
+		v.Fail("portrait", validation.TagPortrait)
+
+		return v.Err()
+	}
+
+	p, err := portrait.Process(data, s.portrait)
+	if err != nil {
+		v.Fail("portrait", validation.TagPortrait)
+
+		return fmt.Errorf("%w: %w", v.Err(), err)
+	}
+
+	d.Portrait = base64.StdEncoding.EncodeToString(p.Data)
+
+	if d.PortraitCaptureDate == nil && !p.CaptureDate.IsZero() {
+		date := util.Date(p.CaptureDate)
+		d.PortraitCaptureDate = &date
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package portrait
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+const (
+	exifTagDateTime         = 0x0132
+	exifTagExifIFD          = 0x8769
+	exifTagDateTimeOriginal = 0x9003
+	exifTypeASCII           = 2
+	exifTypeLong            = 4
+	exifDateFormat          = "2006:01:02 15:04:05"
+)
+
+// exifCaptureDate returns date the photo was taken from the EXIF metadata. Original date is preferred over
+// the modification date. Zero time is returned if date is not present or metadata is malformed.
+func exifCaptureDate(exif []byte) time.Time {
+	if len(exif) < 8 {
+		return time.Time{}
+	}
+
+	var order binary.ByteOrder
+
+	switch {
+	case bytes.HasPrefix(exif, []byte("II*\x00")):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(exif, []byte("MM\x00*")):
+		order = binary.BigEndian
+	default:
+		return time.Time{}
+	}
+
+	tags := readIFD(exif, order, order.Uint32(exif[4:8]))
+
+	if offset, ok := tags[exifTagExifIFD]; ok {
+		if t := parseExifDate(exif, readIFD(exif, order, offset)[exifTagDateTimeOriginal]); !t.IsZero() {
+			return t
+		}
+	}
+
+	return parseExifDate(exif, tags[exifTagDateTime])
+}
+
+// readIFD returns offsets of the ASCII values and values of the LONG entries in the image file directory.
+func readIFD(exif []byte, order binary.ByteOrder, offset uint32) map[uint16]uint32 {
+	res := make(map[uint16]uint32)
+
+	if uint64(offset)+2 > uint64(len(exif)) {
+		return res
+	}
+
+	n := int(order.Uint16(exif[offset:]))
+
+	for i := range n {
+		p := int(offset) + 2 + i*12
+		if p+12 > len(exif) {
+			break
+		}
+
+		tag := order.Uint16(exif[p:])
+		typ := order.Uint16(exif[p+2:])
+		count := order.Uint32(exif[p+4:])
+
+		switch {
+		case typ == exifTypeLong && count == 1:
+			res[tag] = order.Uint32(exif[p+8:])
+		case typ == exifTypeASCII && count == 20:
+			// Date value does not fit into the entry and is stored at the offset
+			res[tag] = order.Uint32(exif[p+8:])
+		}
+	}
+
+	return res
+}
+
+func parseExifDate(exif []byte, offset uint32) time.Time {
+	if offset == 0 || uint64(offset)+19 > uint64(len(exif)) {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(exifDateFormat, string(exif[offset:offset+19]))
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
@@ -0,0 +1,378 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package portrait
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+// Portrait image formats allowed by ISO/IEC 18013-5.
+const (
+	FormatJPEG     = "jpeg"
+	FormatJPEG2000 = "jp2"
+)
+
+const (
+	// minDimension is the smallest portrait width or height that downscaling may produce.
+	minDimension = 64
+	// minQuality is the lowest JPEG quality used when re-encoding the portrait.
+	minQuality = 50
+)
+
+var (
+	// ErrUnsupportedFormat is returned when portrait is neither JPEG nor JPEG2000 image.
+	ErrUnsupportedFormat = errors.New("unsupported portrait image format")
+	// ErrTooLarge is returned when portrait can not be reduced to the maximum size.
+	ErrTooLarge = errors.New("portrait image is too large")
+)
+
+var (
+	jp2Signature = []byte{0x00, 0x00, 0x00, 0x0C, 'j', 'P', ' ', ' ', 0x0D, 0x0A, 0x87, 0x0A}
+	j2kSignature = []byte{0xFF, 0x4F, 0xFF, 0x51}
+	// jp2ExifUUID is the UUID of the JP2 box that contains EXIF metadata
+	jp2ExifUUID = []byte("JpgTiffExif->JP2")
+)
+
+// Options of the portrait processing.
+type Options struct {
+	// MaxSize is the maximum size of the encoded portrait in bytes.
+	MaxSize int
+	// MaxDimension is the maximum width and height of the portrait in pixels.
+	MaxDimension int
+}
+
+// Portrait is the processed portrait image.
+type Portrait struct {
+	// Data is the encoded image without metadata.
+	Data []byte
+	// Format is the image format.
+	Format string
+	// CaptureDate is the date the photo was taken if it was present in the image metadata.
+	CaptureDate time.Time
+}
+
+// Format detects portrait image format by its signature.
+func Format(data []byte) (string, error) {
+	switch {
+	case len(data) > 3 && data[0] == 0xFF && data[1] == 0xD8 && data[2] == 0xFF:
+		return FormatJPEG, nil
+	case bytes.HasPrefix(data, jp2Signature), bytes.HasPrefix(data, j2kSignature):
+		return FormatJPEG2000, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// Process checks portrait format, strips metadata and downscales or re-encodes JPEG portrait
+// that exceeds the limits. JPEG2000 portrait can not be decoded, so after removing metadata
+// it is only checked against the size limit.
+func Process(data []byte, opts Options) (*Portrait, error) {
+	format, err := Format(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == FormatJPEG2000 {
+		stripped, exif, err := stripJPEG2000(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.MaxSize > 0 && len(stripped) > opts.MaxSize {
+			return nil, ErrTooLarge
+		}
+
+		return &Portrait{
+			Data:        stripped,
+			Format:      format,
+			CaptureDate: exifCaptureDate(exif),
+		}, nil
+	}
+
+	stripped, exif, err := stripJPEG(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(stripped))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	res := &Portrait{
+		Data:        stripped,
+		Format:      format,
+		CaptureDate: exifCaptureDate(exif),
+	}
+
+	if fits(cfg.Width, cfg.Height, len(stripped), opts) {
+		return res, nil
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	res.Data, err = reencode(img, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func fits(width, height, size int, opts Options) bool {
+	if opts.MaxDimension > 0 && (width > opts.MaxDimension || height > opts.MaxDimension) {
+		return false
+	}
+
+	return opts.MaxSize <= 0 || size <= opts.MaxSize
+}
+
+// reencode downscales image to the maximum dimension and lowers quality or dimensions until it fits the maximum size.
+func reencode(img image.Image, opts Options) ([]byte, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	if opts.MaxDimension > 0 && (w > opts.MaxDimension || h > opts.MaxDimension) {
+		w, h = scale(w, h, opts.MaxDimension)
+	}
+
+	for {
+		dst := img
+		if w != b.Dx() || h != b.Dy() {
+			rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+			draw.CatmullRom.Scale(rgba, rgba.Bounds(), img, b, draw.Src, nil)
+			dst = rgba
+		}
+
+		for q := 90; q >= minQuality; q -= 10 {
+			var buf bytes.Buffer
+
+			if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: q}); err != nil {
+				return nil, err
+			}
+
+			if opts.MaxSize <= 0 || buf.Len() <= opts.MaxSize {
+				return buf.Bytes(), nil
+			}
+		}
+
+		// Do not shrink portrait below the usable size
+		if w*3/4 < minDimension || h*3/4 < minDimension {
+			return nil, ErrTooLarge
+		}
+
+		w, h = w*3/4, h*3/4
+	}
+}
+
+// scale returns dimensions that fit into the maximum dimension keeping aspect ratio.
+func scale(w, h, limit int) (int, int) {
+	if w >= h {
+		return limit, max(h*limit/w, 1)
+	}
+
+	return max(w*limit/h, 1), limit
+}
+
+// stripJPEG removes metadata segments from JPEG image without re-encoding it. JFIF, ICC profile and Adobe
+// segments are kept as they affect how the image is decoded. Returns EXIF segment payload if present.
+func stripJPEG(data []byte) ([]byte, []byte, error) {
+	var (
+		out  bytes.Buffer
+		exif []byte
+	)
+
+	out.Write(data[:2])
+
+	for i := 2; i < len(data); {
+		if data[i] != 0xFF {
+			return nil, nil, ErrUnsupportedFormat
+		}
+
+		// Skip fill bytes
+		for i < len(data) && data[i] == 0xFF {
+			i++
+		}
+
+		if i >= len(data) {
+			return nil, nil, ErrUnsupportedFormat
+		}
+
+		marker := data[i]
+		start := i - 1
+
+		// Start of scan is followed by entropy-coded image data
+		if marker == 0xDA {
+			out.Write(data[start:])
+
+			return out.Bytes(), exif, nil
+		}
+
+		// Standalone markers have no length
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			out.Write(data[start : i+1])
+			i++
+
+			continue
+		}
+
+		if i+3 > len(data) {
+			return nil, nil, ErrUnsupportedFormat
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+1 : i+3]))
+
+		end := i + 1 + length
+		if length < 2 || end > len(data) {
+			return nil, nil, ErrUnsupportedFormat
+		}
+
+		payload := data[i+3 : end]
+
+		switch {
+		case marker == 0xE1:
+			if bytes.HasPrefix(payload, []byte("Exif\x00\x00")) && exif == nil {
+				exif = payload[6:]
+			}
+		case marker == 0xFE, marker >= 0xE3 && marker <= 0xED, marker == 0xEF:
+			// Drop comments and application specific metadata
+		default:
+			out.Write(data[start:end])
+		}
+
+		i = end
+	}
+
+	return nil, nil, ErrUnsupportedFormat
+}
+
+// stripJPEG2000 removes metadata from JPEG2000 image without re-encoding it. XML, UUID, UUID info and
+// intellectual property boxes are removed from the JP2 container and comments from the codestream main header,
+// other boxes are kept as is. Returns EXIF payload if present.
+func stripJPEG2000(data []byte) ([]byte, []byte, error) {
+	if bytes.HasPrefix(data, j2kSignature) {
+		cs, err := stripCodestream(data)
+
+		return cs, nil, err
+	}
+
+	var (
+		out  bytes.Buffer
+		exif []byte
+	)
+
+	for i := 0; i < len(data); {
+		if i+8 > len(data) {
+			return nil, nil, ErrUnsupportedFormat
+		}
+
+		length, header := uint64(binary.BigEndian.Uint32(data[i:])), 8
+
+		switch length {
+		case 0:
+			// Last box extends to the end of the file
+			length = uint64(len(data) - i)
+		case 1:
+			if i+16 > len(data) {
+				return nil, nil, ErrUnsupportedFormat
+			}
+
+			length, header = binary.BigEndian.Uint64(data[i+8:]), 16
+		}
+
+		if length < uint64(header) || length > uint64(len(data)-i) {
+			return nil, nil, ErrUnsupportedFormat
+		}
+
+		end := i + int(length) //nolint:gosec
+		payload := data[i+header : end]
+
+		switch string(data[i+4 : i+8]) {
+		case "uuid":
+			if bytes.HasPrefix(payload, jp2ExifUUID) && exif == nil {
+				exif = bytes.TrimPrefix(payload[len(jp2ExifUUID):], []byte("Exif\x00\x00"))
+			}
+		case "xml ", "uinf", "jp2i":
+			// Drop XML (including XMP) and intellectual property metadata
+		case "jp2c":
+			cs, err := stripCodestream(payload)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			writeBox(&out, data[i:i+header], cs)
+		default:
+			out.Write(data[i:end])
+		}
+
+		i = end
+	}
+
+	return out.Bytes(), exif, nil
+}
+
+// writeBox writes JP2 box with the new payload keeping the length encoding of the original box header.
+func writeBox(out *bytes.Buffer, header, payload []byte) {
+	h := bytes.Clone(header)
+
+	switch {
+	case len(h) == 16:
+		binary.BigEndian.PutUint64(h[8:], uint64(len(h)+len(payload)))
+	case binary.BigEndian.Uint32(h) != 0:
+		binary.BigEndian.PutUint32(h, uint32(len(h)+len(payload))) //nolint:gosec
+	}
+
+	out.Write(h)
+	out.Write(payload)
+}
+
+// stripCodestream removes comment marker segments from the main header of JPEG2000 codestream.
+// Tile-parts are kept as is.
+func stripCodestream(cs []byte) ([]byte, error) {
+	if !bytes.HasPrefix(cs, j2kSignature[:2]) {
+		return nil, ErrUnsupportedFormat
+	}
+
+	var out bytes.Buffer
+
+	out.Write(cs[:2])
+
+	for i := 2; ; {
+		if i+4 > len(cs) || cs[i] != 0xFF {
+			return nil, ErrUnsupportedFormat
+		}
+
+		marker := cs[i+1]
+
+		// Main header ends with the start of the first tile-part
+		if marker == 0x90 {
+			out.Write(cs[i:])
+
+			return out.Bytes(), nil
+		}
+
+		length := int(binary.BigEndian.Uint16(cs[i+2 : i+4]))
+
+		end := i + 2 + length
+		if length < 2 || end > len(cs) {
+			return nil, ErrUnsupportedFormat
+		}
+
+		// Drop comments
+		if marker != 0x64 {
+			out.Write(cs[i:end])
+		}
+
+		i = end
+	}
+}
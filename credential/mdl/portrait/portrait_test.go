@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package portrait
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+	"time"
+
+	"github.com/go-quicktest/qt"
+)
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{uint8(x * y), uint8(x + y), uint8(x ^ y), 0xFF})
+		}
+	}
+
+	var buf bytes.Buffer
+
+	qt.Assert(t, qt.IsNil(jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95})))
+
+	return buf.Bytes()
+}
+
+// testEXIF builds little endian EXIF payload with DateTimeOriginal in the EXIF sub-directory.
+func testEXIF(date string) []byte {
+	var b bytes.Buffer
+
+	b.WriteString("II*\x00")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(8))
+
+	// IFD0 with pointer to the EXIF IFD at offset 26
+	_ = binary.Write(&b, binary.LittleEndian, uint16(1))
+	_ = binary.Write(&b, binary.LittleEndian, []uint16{exifTagExifIFD, exifTypeLong})
+	_ = binary.Write(&b, binary.LittleEndian, []uint32{1, 26})
+	_ = binary.Write(&b, binary.LittleEndian, uint32(0))
+
+	// EXIF IFD with DateTimeOriginal value at offset 44
+	_ = binary.Write(&b, binary.LittleEndian, uint16(1))
+	_ = binary.Write(&b, binary.LittleEndian, []uint16{exifTagDateTimeOriginal, exifTypeASCII})
+	_ = binary.Write(&b, binary.LittleEndian, []uint32{20, 44})
+	_ = binary.Write(&b, binary.LittleEndian, uint32(0))
+
+	b.WriteString(date + "\x00")
+
+	return b.Bytes()
+}
+
+func withSegments(data []byte, segments ...[]byte) []byte {
+	var b bytes.Buffer
+
+	b.Write(data[:2])
+
+	for _, s := range segments {
+		b.Write(s)
+	}
+
+	b.Write(data[2:])
+
+	return b.Bytes()
+}
+
+func segment(marker byte, payload []byte) []byte {
+	s := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(s[2:], uint16(len(payload)+2))
+
+	return append(s, payload...)
+}
+
+func TestFormat(t *testing.T) {
+	f, err := Format(testJPEG(t, 8, 8))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(f, FormatJPEG))
+
+	f, err = Format(append(jp2Signature, 0, 0))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(f, FormatJPEG2000))
+
+	_, err = Format([]byte("\x89PNG\r\n\x1a\n"))
+	qt.Check(t, qt.ErrorIs(err, ErrUnsupportedFormat))
+}
+
+func TestProcessStripsMetadata(t *testing.T) {
+	exif := append([]byte("Exif\x00\x00"), testEXIF("2024:03:05 10:11:12")...)
+	data := withSegments(testJPEG(t, 32, 40), segment(0xE1, exif), segment(0xFE, []byte("comment")))
+
+	p, err := Process(data, Options{MaxSize: len(data), MaxDimension: 100})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(p.Format, FormatJPEG))
+	qt.Check(t, qt.Equals(p.CaptureDate, time.Date(2024, 3, 5, 10, 11, 12, 0, time.UTC)))
+	qt.Check(t, qt.IsFalse(bytes.Contains(p.Data, []byte("Exif"))))
+	qt.Check(t, qt.IsFalse(bytes.Contains(p.Data, []byte("comment"))))
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(p.Data))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(cfg.Width, 32))
+	qt.Check(t, qt.Equals(cfg.Height, 40))
+}
+
+func TestProcessDownscales(t *testing.T) {
+	data := testJPEG(t, 400, 200)
+
+	p, err := Process(data, Options{MaxSize: len(data), MaxDimension: 100})
+	qt.Assert(t, qt.IsNil(err))
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(p.Data))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(cfg.Width, 100))
+	qt.Check(t, qt.Equals(cfg.Height, 50))
+	qt.Check(t, qt.IsTrue(p.CaptureDate.IsZero()))
+}
+
+func TestProcessReducesSize(t *testing.T) {
+	data := testJPEG(t, 300, 300)
+
+	p, err := Process(data, Options{MaxSize: len(data) / 4})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.IsTrue(len(p.Data) <= len(data)/4))
+
+	_, err = Process(data, Options{MaxSize: 100})
+	qt.Check(t, qt.ErrorIs(err, ErrTooLarge))
+}
+
+func TestProcessJPEG2000(t *testing.T) {
+	data := append(append([]byte{}, jp2Signature...), make([]byte, 100)...)
+
+	p, err := Process(data, Options{MaxSize: 200})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(p.Format, FormatJPEG2000))
+	qt.Check(t, qt.DeepEquals(p.Data, data))
+
+	_, err = Process(data, Options{MaxSize: 50})
+	qt.Check(t, qt.ErrorIs(err, ErrTooLarge))
+}
+
+// box builds JP2 box with 4 byte length.
+func box(typ string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(data)+8))
+	b = append(b, typ...)
+
+	return append(b, data...)
+}
+
+// testCodestream builds JPEG2000 codestream with a single tile-part. Comment is added to the main header if not empty.
+func testCodestream(comment string) []byte {
+	var b bytes.Buffer
+
+	b.Write([]byte{0xFF, 0x4F})
+	b.Write(marker(0x51, make([]byte, 38)))
+
+	if comment != "" {
+		b.Write(marker(0x64, append([]byte{0x00, 0x01}, comment...)))
+	}
+
+	b.Write(marker(0x52, []byte{0x00, 0x00, 0x00, 0x01, 0x00, 0x05, 0x04, 0x04, 0x00, 0x01}))
+	b.Write([]byte{0xFF, 0x90, 0x00, 0x0A, 0x00, 0x00, 0x00, 0x00, 0x00, 0x14, 0x00, 0x01})
+	b.Write([]byte{0xFF, 0x93, 0x01, 0x02, 0x03, 0x04})
+	b.Write([]byte{0xFF, 0xD9})
+
+	return b.Bytes()
+}
+
+func marker(code byte, payload []byte) []byte {
+	m := []byte{0xFF, code, 0, 0}
+	binary.BigEndian.PutUint16(m[2:], uint16(len(payload)+2))
+
+	return append(m, payload...)
+}
+
+func TestProcessJPEG2000StripsMetadata(t *testing.T) {
+	ftyp := box("ftyp", []byte("jp2 \x00\x00\x00\x00jp2 "))
+	jp2h := box("jp2h", box("ihdr", make([]byte, 14)), box("colr", []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10}))
+	xmp := box("uuid", []byte{0xBE, 0x7A, 0xCF, 0xCB, 0x97, 0xA9, 0x42, 0xE8, 0x9C, 0x71, 0x99, 0x94, 0x91, 0xE3, 0xAF, 0xAC}, []byte("<x:xmpmeta/>"))
+	exif := box("uuid", jp2ExifUUID, []byte("Exif\x00\x00"), testEXIF("2024:03:05 10:11:12"))
+	codestream := testCodestream("Created by scanner")
+
+	data := bytes.Join([][]byte{jp2Signature, ftyp, jp2h, box("xml ", []byte("<metadata/>")), xmp, exif, box("jp2c", codestream)}, nil)
+
+	p, err := Process(data, Options{MaxSize: len(data)})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(p.Format, FormatJPEG2000))
+	qt.Check(t, qt.Equals(p.CaptureDate, time.Date(2024, 3, 5, 10, 11, 12, 0, time.UTC)))
+
+	// Image boxes are kept as is and codestream box length matches the stripped codestream
+	qt.Check(t, qt.DeepEquals(p.Data, bytes.Join([][]byte{jp2Signature, ftyp, jp2h, box("jp2c", testCodestream(""))}, nil)))
+
+	for _, metadata := range []string{"metadata", "xmpmeta", "JpgTiffExif", "Created by scanner"} {
+		qt.Check(t, qt.IsFalse(bytes.Contains(p.Data, []byte(metadata))), qt.Commentf("%s", metadata))
+	}
+
+	// Stripping metadata is idempotent
+	again, err := Process(p.Data, Options{})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(again.Data, p.Data))
+}
+
+func TestProcessJPEG2000BoxLengths(t *testing.T) {
+	codestream := testCodestream("comment")
+
+	// Codestream box extends to the end of the file
+	toEnd := append([]byte{0, 0, 0, 0}, "jp2c"...)
+
+	p, err := Process(bytes.Join([][]byte{jp2Signature, toEnd, codestream}, nil), Options{})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.IsTrue(bytes.HasPrefix(p.Data[len(jp2Signature):], toEnd)))
+	qt.Check(t, qt.IsFalse(bytes.Contains(p.Data, []byte("comment"))))
+
+	// Codestream box with extended length
+	xl := append([]byte{0, 0, 0, 1}, "jp2c"...)
+	xl = binary.BigEndian.AppendUint64(xl, uint64(len(codestream)+16))
+
+	p, err = Process(bytes.Join([][]byte{jp2Signature, xl, codestream}, nil), Options{})
+	qt.Assert(t, qt.IsNil(err))
+
+	header := p.Data[len(jp2Signature):]
+	qt.Check(t, qt.Equals(binary.BigEndian.Uint64(header[8:16]), uint64(len(header))))
+	qt.Check(t, qt.IsFalse(bytes.Contains(p.Data, []byte("comment"))))
+
+	// Raw codestream without JP2 container
+	p, err = Process(codestream, Options{})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(p.Format, FormatJPEG2000))
+	qt.Check(t, qt.IsFalse(bytes.Contains(p.Data, []byte("comment"))))
+	qt.Check(t, qt.IsTrue(bytes.HasSuffix(p.Data, []byte{0xFF, 0xD9})))
+
+	// Truncated box
+	_, err = Process(bytes.Join([][]byte{jp2Signature, box("jp2c", codestream)[:20]}, nil), Options{})
+	qt.Check(t, qt.ErrorIs(err, ErrUnsupportedFormat))
+}
+
+func TestProcessJPEG2000Size(t *testing.T) {
+	codestream := box("jp2c", testCodestream(""))
+	metadata := box("xml ", bytes.Repeat([]byte("<metadata/>"), 100))
+	data := bytes.Join([][]byte{jp2Signature, metadata, codestream}, nil)
+
+	// Portrait that exceeds the size limit only because of metadata is accepted
+	p, err := Process(data, Options{MaxSize: len(jp2Signature) + len(codestream)})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.HasLen(p.Data, len(jp2Signature)+len(codestream)))
+
+	// Codestream itself can not be reduced without decoding
+	_, err = Process(data, Options{MaxSize: len(codestream)})
+	qt.Check(t, qt.ErrorIs(err, ErrTooLarge))
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+//nolint:tagliatelle
+package pda1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/credential"
+	"git.zzdats.lv/edim/api-wallet/credential/validation"
+	"git.zzdats.lv/edim/api-wallet/util"
+
+	"azugo.io/azugo"
+	"azugo.io/core/http"
+	"github.com/valyala/fasthttp"
+)
+
+// NoActiveCertificateError is returned when the person has no A1 certificate valid at the moment.
+type NoActiveCertificateError struct{}
+
+func (NoActiveCertificateError) Error() string {
+	return "no active A1 certificate"
+}
+
+func (NoActiveCertificateError) StatusCode() int {
+	return fasthttp.StatusNotFound
+}
+
+// Is allows NoActiveCertificateError to be handled as http.NotFoundError.
+func (NoActiveCertificateError) Is(target error) bool {
+	_, ok := target.(http.NotFoundError)
+
+	return ok
+}
+
+// Employer represents employer of the posted worker.
+type Employer struct {
+	// ID represents employer registration number
+	ID string `json:"id"`
+	// Name represents employer legal name
+	Name string `json:"name"`
+	// CountryCode represents employer registration country code (ISO 3166-1 alpha-2)
+	CountryCode string `json:"country_code"`
+}
+
+// Certificate defines the response structure for the social security PDA1 data.
+type Certificate struct {
+	// PersonalAdministrativeNumber represents person's personal identification number
+	PersonalAdministrativeNumber string `json:"personal_administrative_number"`
+	// GivenName represents person's name
+	GivenName string `json:"given_name"`
+	// FamilyName represents person's surname
+	FamilyName string `json:"family_name"`
+	// BirthDate represents person's date of birth
+	BirthDate util.Date `json:"birth_date"`
+	// Nationality represents person's nationality country code (ISO 3166-1 alpha-2)
+	Nationality string `json:"nationality"`
+	// DocumentNumber represents A1 certificate number
+	DocumentNumber string `json:"document_number"`
+	// Employer represents employer of the person
+	Employer Employer `json:"employer"`
+	// WorkCountryCode represents country code of the place of work (ISO 3166-1 alpha-2)
+	WorkCountryCode string `json:"work_country_code"`
+	// MemberStateOfLegislation represents country code whose legislation applies (ISO 3166-1 alpha-2)
+	MemberStateOfLegislation string `json:"member_state_of_legislation"`
+	// StartingDate represents start date of the applicable legislation
+	StartingDate util.Date `json:"starting_date"`
+	// EndingDate represents end date of the applicable legislation
+	EndingDate util.Date `json:"ending_date"`
+	// IssuingCountry represents certificate issuing country code (ISO 3166-1 alpha-2)
+	IssuingCountry string `json:"issuing_country"`
+	// IssuingAuthority represents issuing authority
+	IssuingAuthority string `json:"issuing_authority"`
+}
+
+// Active checks if the certificate is valid at the provided time.
+func (c *Certificate) Active(now time.Time) bool {
+	start, end := time.Time(c.StartingDate), time.Time(c.EndingDate)
+
+	if !start.IsZero() && now.Before(start) {
+		return false
+	}
+
+	// Certificate is valid until the end of the ending date
+	return end.IsZero() || now.Before(end.AddDate(0, 0, 1))
+}
+
+// Validate checks and normalizes PDA1 data.
+func (c *Certificate) Validate() error {
+	v := validation.New("pda1")
+	v.Required("personal_administrative_number", &c.PersonalAdministrativeNumber)
+	v.Required("given_name", &c.GivenName)
+	v.Required("family_name", &c.FamilyName)
+	v.Date("birth_date", time.Time(c.BirthDate))
+	v.OptionalCountry("nationality", &c.Nationality)
+	v.Required("document_number", &c.DocumentNumber)
+	v.Required("employer.name", &c.Employer.Name)
+	v.OptionalCountry("employer.country_code", &c.Employer.CountryCode)
+	v.Country("work_country_code", &c.WorkCountryCode)
+	v.Country("member_state_of_legislation", &c.MemberStateOfLegislation)
+	v.Country("issuing_country", &c.IssuingCountry)
+
+	// Legislation can apply for an indefinite period
+	if v.Date("starting_date", time.Time(c.StartingDate)) && !time.Time(c.EndingDate).IsZero() {
+		v.DateRange("starting_date", time.Time(c.StartingDate), "ending_date", time.Time(c.EndingDate))
+	}
+
+	return v.Err()
+}
+
+// Source of the Portable Document A1 (PDA1) credential.
+type Source struct {
+	client *credential.Client
+}
+
+// New creates PDA1 credential source using social security API.
+func New(client *credential.Client) *Source {
+	return &Source{
+		client: client,
+	}
+}
+
+func (s *Source) Type() string {
+	return "pda1"
+}
+
+func (s *Source) ConfigurationIDs() []string {
+	return []string{"eu.europa.ec.eudi.pda1_sd_jwt_vc"}
+}
+
+func (s *Source) Errors() credential.Errors {
+	return credential.Errors{
+		NotFound: "No active A1 certificate found",
+		Failed:   "failed to call PDA1",
+	}
+}
+
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return s.client.HealthCheck(ctx)
+}
+
+func (s *Source) BreakerState() credential.BreakerState {
+	return s.client.BreakerState()
+}
+
+func (s *Source) Form(ctx *azugo.Context) (any, error) {
+	res := &Certificate{}
+
+	if err := s.client.GetJSON(ctx, "/1.0/pda1", res); err != nil {
+		if errors.Is(err, http.NotFoundError{}) {
+			return nil, NoActiveCertificateError{}
+		}
+
+		return nil, fmt.Errorf("failed to call PDA1: %w", err)
+	}
+
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if err := credential.CheckExpiry("PDA1", time.Time(res.EndingDate), now); err != nil {
+		return nil, err
+	}
+
+	if !res.Active(now) {
+		return nil, NoActiveCertificateError{}
+	}
+
+	return res, nil
+}
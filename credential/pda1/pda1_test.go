@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package pda1
+
+import (
+	"testing"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/util"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestCertificateActive(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		start  time.Time
+		end    time.Time
+		active bool
+	}{
+		{"no period", time.Time{}, time.Time{}, true},
+		{"within period", now.AddDate(0, -1, 0), now.AddDate(0, 1, 0), true},
+		{"ends today", now.AddDate(0, -1, 0), time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC), true},
+		{"expired", now.AddDate(0, -2, 0), now.AddDate(0, -1, 0), false},
+		{"not started", now.AddDate(0, 0, 1), now.AddDate(0, 1, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Certificate{
+				StartingDate: util.Date(tt.start),
+				EndingDate:   util.Date(tt.end),
+			}
+
+			qt.Check(t, qt.Equals(c.Active(now), tt.active))
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+//nolint:tagliatelle
+package pid
+
+import (
+	"context"
+	"fmt"
+
+	"git.zzdats.lv/edim/api-wallet/credential"
+	"git.zzdats.lv/edim/api-wallet/credential/validation"
+
+	"azugo.io/azugo"
+)
+
+// Person contains person data from the FPRIS registry.
+type Person struct {
+	GivenName                    string `json:"given_name"`
+	FamilyName                   string `json:"family_name"`
+	Nationality                  string `json:"nationality"`
+	BirthDate                    string `json:"birth_date"`
+	PersonalAdministrativeNumber string `json:"personal_administrative_number"`
+	BirthPlace                   string `json:"birth_place"`
+	BirthCountry                 string `json:"birth_country"`
+	IssuingAuthority             string `json:"issuing_authority"`
+	IssuingCountry               string `json:"issuing_country"`
+	AgeOver18                    bool   `json:"age_over_18"`
+}
+
+// Response is the FPRIS registry person identification data response.
+type Response struct {
+	Person
+	IssuanceDate string `json:"issuance_date"`
+	ExpiryDate   string `json:"expiry_date"`
+}
+
+// Validate checks and normalizes FPRIS data.
+func (r *Response) Validate() error {
+	v := validation.New("pid")
+	v.Required("given_name", &r.GivenName)
+	v.Required("family_name", &r.FamilyName)
+	v.DateString("birth_date", &r.BirthDate)
+	v.Required("personal_administrative_number", &r.PersonalAdministrativeNumber)
+	v.Country("nationality", &r.Nationality)
+	v.OptionalCountry("birth_country", &r.BirthCountry)
+	v.Country("issuing_country", &r.IssuingCountry)
+	v.Required("issuing_authority", &r.IssuingAuthority)
+
+	issuanceDate, issuanceOK := v.DateString("issuance_date", &r.IssuanceDate)
+	expiryDate, expiryOK := v.DateString("expiry_date", &r.ExpiryDate)
+
+	if issuanceOK && expiryOK {
+		v.DateRange("issuance_date", issuanceDate, "expiry_date", expiryDate)
+	}
+
+	return v.Err()
+}
+
+// Form is the upstream issuer PID credential offer form.
+type Form struct {
+	Person
+	EstimatedIssuanceDate string `json:"estimated_issuance_date"`
+	EstimatedExpiryDate   string `json:"estimated_expiry_date"`
+}
+
+// Source of the person identification data (PID) credential.
+type Source struct {
+	client *credential.Client
+}
+
+// New creates PID credential source using FPRIS API.
+func New(client *credential.Client) *Source {
+	return &Source{
+		client: client,
+	}
+}
+
+func (s *Source) Type() string {
+	return "pid"
+}
+
+func (s *Source) ConfigurationIDs() []string {
+	return []string{"eu.europa.ec.eudi.pid_mdoc"}
+}
+
+func (s *Source) Errors() credential.Errors {
+	return credential.Errors{
+		NotFound: "Data about person not found",
+		Failed:   "failed to call PID",
+	}
+}
+
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return s.client.HealthCheck(ctx)
+}
+
+func (s *Source) BreakerState() credential.BreakerState {
+	return s.client.BreakerState()
+}
+
+// Data returns person data from the FPRIS registry.
+func (s *Source) Data(ctx *azugo.Context) (*Response, error) {
+	res := &Response{}
+
+	if err := s.client.GetJSON(ctx, "/1.0/pid", res); err != nil {
+		return nil, fmt.Errorf("failed to call FPRIS: %w", err)
+	}
+
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s *Source) Form(ctx *azugo.Context) (any, error) {
+	res, err := s.Data(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Form{
+		Person:                res.Person,
+		EstimatedIssuanceDate: res.IssuanceDate,
+		EstimatedExpiryDate:   res.ExpiryDate,
+	}, nil
+}
@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+//nolint:tagliatelle
+package rtu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/credential"
+	"git.zzdats.lv/edim/api-wallet/credential/validation"
+
+	"azugo.io/azugo"
+	"azugo.io/core/http"
+)
+
+// Diploma contains the data of the diploma.
+type Diploma struct {
+	// ID represents the diploma identifier in the RTU registry
+	ID string `json:"id,omitempty"`
+	// GivenName represents the person given name
+	GivenName string `json:"givenName"`
+	// FamilyName represents the person family name
+	FamilyName string `json:"familyName"`
+	// NationalID represents the person national ID
+	NationalID string `json:"nationalID"`
+	// CitizenshipCountryCode represents the citizenship country code
+	CitizenshipCountryCode string `json:"citizenshipCountryCode"`
+	// Type represents the type of the diploma
+	Type string `json:"type"`
+	// Title represents the title
+	Title string `json:"title"`
+	// AwardingDate  represents the awarding date of the diploma
+	AwardingDate time.Time `json:"awardingDate"`
+	// AwardingBodyLegalName represents the awarding body legal name
+	AwardingBodyLegalName string `json:"awardingBody_legalName"`
+	// AwardingBodyRegistration represents the awarding body registration
+	AwardingBodyRegistration string `json:"awardingBody_registration"`
+	// AwardingBodyCountryCode represents the awarding body country code
+	AwardingBodyCountryCode string `json:"awardingBody_countryCode"`
+	// CreditPoint represents the credit point
+	CreditPoint string `json:"creditPoint"`
+	// CreditValue represents the credit value
+	CreditValue string `json:"creditValue"`
+	// MaximumDuration represents the maximum duration
+	MaximumDuration string `json:"maximumDuration"`
+	// ThematicArea represents the thematic area
+	ThematicArea string `json:"thematicArea"`
+	// NqfLevel reprsents the nqf level
+	NqfLevel string `json:"nqfLevel"`
+	// EqfLevel reprsents the nqf level
+	EqfLevel string `json:"eqfLevel"`
+	// ValidFrom reprsents the date the diploma is valid from
+	ValidFrom time.Time `json:"validFrom"`
+	// IssuanceDate reprsents the date of the diploma issuance
+	IssuanceDate time.Time `json:"issuanceDate"`
+	// Issued reprsents the date the diploma was issued
+	Issued time.Time `json:"issued"`
+}
+
+// Validate checks and normalizes RTU diploma data.
+func (d *Diploma) Validate() error {
+	v := validation.New("rtu")
+	v.Required("givenName", &d.GivenName)
+	v.Required("familyName", &d.FamilyName)
+	v.OptionalCountry("citizenshipCountryCode", &d.CitizenshipCountryCode)
+	v.Required("title", &d.Title)
+	v.Date("awardingDate", d.AwardingDate)
+	v.Required("awardingBody_legalName", &d.AwardingBodyLegalName)
+	v.Country("awardingBody_countryCode", &d.AwardingBodyCountryCode)
+
+	return v.Err()
+}
+
+// Source of the RTU diploma credential.
+type Source struct {
+	client *credential.Client
+}
+
+// New creates RTU diploma credential source using RTU API.
+func New(client *credential.Client) *Source {
+	return &Source{
+		client: client,
+	}
+}
+
+func (s *Source) Type() string {
+	return "rtu"
+}
+
+func (s *Source) ConfigurationIDs() []string {
+	return []string{"eu.europa.ec.eudi.rtu_diploma_mdoc"}
+}
+
+func (s *Source) Errors() credential.Errors {
+	return credential.Errors{
+		NotFound: "Data about education not found",
+		Failed:   "failed to call RTU",
+	}
+}
+
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return s.client.HealthCheck(ctx)
+}
+
+func (s *Source) BreakerState() credential.BreakerState {
+	return s.client.BreakerState()
+}
+
+// Diplomas returns all diplomas of the person. Upstream can return a single diploma or a list of diplomas.
+func (s *Source) Diplomas(ctx *azugo.Context) ([]*Diploma, error) {
+	var raw json.RawMessage
+
+	if err := s.client.GetJSON(ctx, "/1.0/diploma", &raw); err != nil {
+		return nil, err
+	}
+
+	return parseDiplomas(raw)
+}
+
+// ListDiplomas returns summaries of all diplomas of the person.
+func (s *Source) ListDiplomas(ctx *azugo.Context) ([]credential.Diploma, error) {
+	diplomas, err := s.Diplomas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]credential.Diploma, 0, len(diplomas))
+	for _, d := range diplomas {
+		res = append(res, credential.Diploma{
+			ID:           d.ID,
+			Type:         d.Type,
+			Title:        d.Title,
+			AwardingBody: d.AwardingBodyLegalName,
+			AwardingDate: d.AwardingDate,
+		})
+	}
+
+	return res, nil
+}
+
+func parseDiplomas(raw json.RawMessage) ([]*Diploma, error) {
+	raw = bytes.TrimSpace(raw)
+
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return []*Diploma{}, nil
+	}
+
+	if raw[0] != '[' {
+		d := &Diploma{}
+		if err := json.Unmarshal(raw, d); err != nil {
+			return nil, fmt.Errorf("failed to parse RTU diploma: %w", err)
+		}
+
+		return []*Diploma{d}, nil
+	}
+
+	res := make([]*Diploma, 0)
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to parse RTU diplomas: %w", err)
+	}
+
+	return res, nil
+}
+
+// FormVariant returns diploma selected by `diploma` query parameter.
+func (s *Source) FormVariant(ctx *azugo.Context) string {
+	if id := ctx.Query.StringOptional("diploma"); id != nil {
+		return *id
+	}
+
+	return ""
+}
+
+// Form returns diploma selected by `diploma` query parameter. Selection can be omitted if the person has only one diploma.
+// Returns azugo.ParamInvalidError if the diploma is not selected or the person has no such diploma.
+func (s *Source) Form(ctx *azugo.Context) (any, error) {
+	diplomas, err := s.Diplomas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := selectDiploma(diplomas, ctx.Query.StringOptional("diploma"))
+	if err != nil {
+		return nil, err
+	}
+
+	return d, d.Validate()
+}
+
+func selectDiploma(diplomas []*Diploma, id *string) (*Diploma, error) {
+	if len(diplomas) == 0 {
+		return nil, http.NotFoundError{Resource: "diploma"}
+	}
+
+	if id == nil {
+		if len(diplomas) > 1 {
+			return nil, azugo.ParamInvalidError{
+				Name: "diploma",
+				Tag:  "required",
+			}
+		}
+
+		return diplomas[0], nil
+	}
+
+	for _, d := range diplomas {
+		if d.ID == *id {
+			return d, nil
+		}
+	}
+
+	// Person has diplomas so unknown diploma is an invalid request rather than missing person data
+	return nil, azugo.ParamInvalidError{
+		Name: "diploma",
+		Tag:  "oneof",
+	}
+}
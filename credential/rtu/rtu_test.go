@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package rtu
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"azugo.io/azugo"
+	"azugo.io/core/http"
+	"github.com/go-quicktest/qt"
+)
+
+func TestParseDiplomas(t *testing.T) {
+	res, err := parseDiplomas(json.RawMessage(` {"id":"diploma-1","title":"Bachelor"} `))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.HasLen(res, 1))
+	qt.Check(t, qt.Equals(res[0].ID, "diploma-1"))
+
+	res, err = parseDiplomas(json.RawMessage(`[{"id":"diploma-1"},{"id":"diploma-2"}]`))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.HasLen(res, 2))
+	qt.Check(t, qt.Equals(res[1].ID, "diploma-2"))
+
+	res, err = parseDiplomas(json.RawMessage(`null`))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.HasLen(res, 0))
+
+	res, err = parseDiplomas(nil)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.HasLen(res, 0))
+
+	_, err = parseDiplomas(json.RawMessage(`{"id":1}`))
+	qt.Check(t, qt.ErrorMatches(err, "failed to parse RTU diploma: .*"))
+
+	_, err = parseDiplomas(json.RawMessage(`[1]`))
+	qt.Check(t, qt.ErrorMatches(err, "failed to parse RTU diplomas: .*"))
+}
+
+func TestSelectDiploma(t *testing.T) {
+	id := func(s string) *string {
+		return &s
+	}
+
+	one := []*Diploma{{ID: "diploma-1"}}
+	two := []*Diploma{{ID: "diploma-1"}, {ID: "diploma-2"}}
+
+	// Selection can be omitted if the person has only one diploma
+	d, err := selectDiploma(one, nil)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(d.ID, "diploma-1"))
+
+	d, err = selectDiploma(two, id("diploma-2"))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(d.ID, "diploma-2"))
+
+	_, err = selectDiploma(two, nil)
+
+	var perr azugo.ParamInvalidError
+
+	qt.Assert(t, qt.ErrorAs(err, &perr))
+	qt.Check(t, qt.Equals(perr.Name, "diploma"))
+	qt.Check(t, qt.Equals(perr.Tag, "required"))
+
+	_, err = selectDiploma(two, id("unknown"))
+	qt.Assert(t, qt.ErrorAs(err, &perr))
+	qt.Check(t, qt.Equals(perr.Name, "diploma"))
+	qt.Check(t, qt.Equals(perr.Tag, "oneof"))
+	qt.Check(t, qt.IsFalse(errors.Is(err, http.NotFoundError{})))
+
+	_, err = selectDiploma(nil, nil)
+	qt.Check(t, qt.ErrorIs(err, error(http.NotFoundError{Resource: "diploma"})))
+}
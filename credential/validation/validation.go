@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package validation
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CodeDataInvalid is the stable error code of the invalid source registry data.
+const CodeDataInvalid = "data_invalid"
+
+// Validation error tags.
+const (
+	TagRequired           = "required"
+	TagCountryCode        = "iso3166_1_alpha2"
+	TagDate               = "date"
+	TagDateRange          = "date_range"
+	TagVehicleCategory    = "iso18013_2_category"
+	TagDistinguishingSign = "un_distinguishing_sign"
+	TagPortrait           = "iso18013_5_portrait"
+)
+
+// vehicleCategories are driving privilege categories as per ISO/IEC 18013-2 Annex A.
+var vehicleCategories = []string{
+	"AM", "A1", "A2", "A", "B1", "B", "BE", "C1", "C1E", "C", "CE", "D1", "D1E", "D", "DE", "T",
+}
+
+// distinguishingSign is distinguishing sign of the country according to ISO/IEC 18013-1 Annex F.
+var distinguishingSign = regexp.MustCompile(`^[A-Z]{1,3}$`)
+
+// FieldError describes invalid field of the source registry data.
+type FieldError struct {
+	// Field is the JSON path of the invalid field
+	Field string `json:"field"`
+	// Tag is the failed validation rule
+	Tag string `json:"tag"`
+}
+
+// Error is returned when source registry data can not be used to issue the credential.
+type Error struct {
+	// Source is the credential request type
+	Source string `json:"source"`
+	// Errors are the invalid fields
+	Errors []FieldError `json:"errors"`
+}
+
+func (e Error) Error() string {
+	fields := make([]string, 0, len(e.Errors))
+	for _, f := range e.Errors {
+		fields = append(fields, f.Field+" ("+f.Tag+")")
+	}
+
+	return fmt.Sprintf("invalid %s data: %s", e.Source, strings.Join(fields, ", "))
+}
+
+func (e Error) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (e Error) ErrorCode() string {
+	return CodeDataInvalid
+}
+
+// Validator validates and normalizes source registry data collecting all invalid fields.
+type Validator struct {
+	source string
+	errs   []FieldError
+}
+
+// New creates validator for the credential request type.
+func New(source string) *Validator {
+	return &Validator{
+		source: source,
+	}
+}
+
+// Fail registers invalid field.
+func (v *Validator) Fail(field, tag string) {
+	v.errs = append(v.errs, FieldError{
+		Field: field,
+		Tag:   tag,
+	})
+}
+
+// Required trims value and checks that it is not empty.
+func (v *Validator) Required(field string, value *string) bool {
+	*value = strings.TrimSpace(*value)
+	if *value == "" {
+		v.Fail(field, TagRequired)
+
+		return false
+	}
+
+	return true
+}
+
+// Country normalizes value to upper case and checks that it is ISO 3166-1 alpha-2 country code.
+func (v *Validator) Country(field string, value *string) {
+	if !v.Required(field, value) {
+		return
+	}
+
+	*value = strings.ToUpper(*value)
+	if !IsCountryCode(*value) {
+		v.Fail(field, TagCountryCode)
+	}
+}
+
+// OptionalCountry validates country code only if value is not empty.
+func (v *Validator) OptionalCountry(field string, value *string) {
+	if strings.TrimSpace(*value) == "" {
+		*value = ""
+
+		return
+	}
+
+	v.Country(field, value)
+}
+
+// Date checks that date is set.
+func (v *Validator) Date(field string, value time.Time) bool {
+	if value.IsZero() {
+		v.Fail(field, TagRequired)
+
+		return false
+	}
+
+	return true
+}
+
+// DateString trims value and checks that it is a date in `YYYY-MM-DD` format.
+func (v *Validator) DateString(field string, value *string) (time.Time, bool) {
+	if !v.Required(field, value) {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.DateOnly, *value)
+	if err != nil {
+		v.Fail(field, TagDate)
+
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// DateRange checks that both dates are set and the end date is not before the start date.
+func (v *Validator) DateRange(startField string, start time.Time, endField string, end time.Time) {
+	if !v.Date(startField, start) || !v.Date(endField, end) {
+		return
+	}
+
+	if end.Before(start) {
+		v.Fail(endField, TagDateRange)
+	}
+}
+
+// VehicleCategory normalizes value to upper case and checks that it is ISO/IEC 18013-2 driving privilege category.
+func (v *Validator) VehicleCategory(field string, value *string) {
+	if !v.Required(field, value) {
+		return
+	}
+
+	*value = strings.ToUpper(*value)
+	if !slices.Contains(vehicleCategories, *value) {
+		v.Fail(field, TagVehicleCategory)
+	}
+}
+
+// DistinguishingSign normalizes value to upper case and checks that it is UN distinguishing sign.
+func (v *Validator) DistinguishingSign(field string, value *string) {
+	if !v.Required(field, value) {
+		return
+	}
+
+	*value = strings.ToUpper(*value)
+	if !distinguishingSign.MatchString(*value) {
+		v.Fail(field, TagDistinguishingSign)
+	}
+}
+
+// Err returns validation error if any of the fields is invalid.
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+
+	return Error{
+		Source: v.source,
+		Errors: v.errs,
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package validation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestValidatorNormalizes(t *testing.T) {
+	country, category, sign := " lv ", "c1e", "lv"
+
+	v := New("mdl")
+	v.Country("issuing_country", &country)
+	v.VehicleCategory("vehicle_category_code", &category)
+	v.DistinguishingSign("un_distinguishing_sign", &sign)
+
+	qt.Assert(t, qt.IsNil(v.Err()))
+	qt.Check(t, qt.Equals(country, "LV"))
+	qt.Check(t, qt.Equals(category, "C1E"))
+	qt.Check(t, qt.Equals(sign, "LV"))
+}
+
+func TestValidatorErrors(t *testing.T) {
+	name, country, category, sign, birthDate := " ", "XX", "Z", "L1", "15.06.2007"
+
+	v := New("mdl")
+	v.Required("given_name", &name)
+	v.Country("issuing_country", &country)
+	v.VehicleCategory("driving_privileges[0].vehicle_category_code", &category)
+	v.DistinguishingSign("un_distinguishing_sign", &sign)
+	v.DateString("birth_date", &birthDate)
+	v.DateRange("issue_date", time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), "expiry_date", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	v.DateRange("valid_from", time.Time{}, "valid_to", time.Time{})
+
+	err := v.Err()
+	qt.Assert(t, qt.IsNotNil(err))
+
+	var verr Error
+	qt.Assert(t, qt.IsTrue(errors.As(err, &verr)))
+	qt.Check(t, qt.Equals(verr.StatusCode(), 422))
+	qt.Check(t, qt.Equals(verr.Source, "mdl"))
+	qt.Check(t, qt.DeepEquals(verr.Errors, []FieldError{
+		{Field: "given_name", Tag: TagRequired},
+		{Field: "issuing_country", Tag: TagCountryCode},
+		{Field: "driving_privileges[0].vehicle_category_code", Tag: TagVehicleCategory},
+		{Field: "un_distinguishing_sign", Tag: TagDistinguishingSign},
+		{Field: "birth_date", Tag: TagDate},
+		{Field: "expiry_date", Tag: TagDateRange},
+		{Field: "valid_from", Tag: TagRequired},
+	}))
+}
+
+func TestOptionalCountry(t *testing.T) {
+	empty, valid := " ", "ee"
+
+	v := New("pid")
+	v.OptionalCountry("nationality", &empty)
+	v.OptionalCountry("birth_country", &valid)
+
+	qt.Assert(t, qt.IsNil(v.Err()))
+	qt.Check(t, qt.Equals(empty, ""))
+	qt.Check(t, qt.Equals(valid, "EE"))
+}
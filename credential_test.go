@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/credential"
+
+	"azugo.io/azugo"
+	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
+)
+
+// query returns optional query parameter value.
+func query(ctx *azugo.Context, name string) string {
+	if v := ctx.Query.StringOptional(name); v != nil {
+		return *v
+	}
+
+	return ""
+}
+
+// testSource is the credential source that returns the current name as the form.
+type testSource struct {
+	name  atomic.Value
+	calls atomic.Int32
+}
+
+func (s *testSource) Type() string {
+	return "test"
+}
+
+func (s *testSource) ConfigurationIDs() []string {
+	return []string{"test"}
+}
+
+func (s *testSource) Errors() credential.Errors {
+	return credential.Errors{}
+}
+
+func (s *testSource) HealthCheck(_ context.Context) error {
+	return nil
+}
+
+func (s *testSource) BreakerState() credential.BreakerState {
+	return credential.BreakerClosed
+}
+
+func (s *testSource) FormVariant(ctx *azugo.Context) string {
+	return query(ctx, "variant")
+}
+
+func (s *testSource) Form(ctx *azugo.Context) (any, error) {
+	s.calls.Add(1)
+
+	return map[string]string{
+		"name":    s.name.Load().(string),
+		"variant": s.FormVariant(ctx),
+	}, nil
+}
+
+func TestCredentialFormCache(t *testing.T) {
+	a := TestApp(t,
+		WithEnv("UPSTREAM_CACHE_TTL", "1s"),
+		WithEnv("UPSTREAM_CACHE_SECRET", "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI="),
+	)
+
+	source := &testSource{}
+	source.name.Store("Jānis")
+
+	qt.Assert(t, qt.IsNil(a.Credentials().Register(source)))
+
+	a.Get("/form", func(ctx *azugo.Context) {
+		form, err := a.Credentials().Form(ctx, source, query(ctx, "person"))
+		if err != nil {
+			ctx.Error(err)
+
+			return
+		}
+
+		ctx.JSON(form)
+	})
+	a.Delete("/form", func(ctx *azugo.Context) {
+		if err := a.Credentials().Invalidate(ctx, query(ctx, "person"), source.Type()); err != nil {
+			ctx.Error(err)
+
+			return
+		}
+
+		ctx.StatusCode(fasthttp.StatusNoContent)
+	})
+
+	app := azugo.NewTestApp(a.App)
+
+	app.Start(t)
+	defer app.Stop()
+
+	client := app.TestClient()
+
+	form := func(person, variant string) map[string]string {
+		t.Helper()
+
+		resp, err := client.Get(fmt.Sprintf("/form?person=%s&variant=%s", person, variant))
+		qt.Assert(t, qt.IsNil(err))
+
+		defer fasthttp.ReleaseResponse(resp)
+
+		qt.Assert(t, qt.Equals(resp.StatusCode(), fasthttp.StatusOK))
+
+		buf, err := resp.BodyUncompressed()
+		qt.Assert(t, qt.IsNil(err))
+
+		res := map[string]string{}
+		qt.Assert(t, qt.IsNil(json.Unmarshal(buf, &res)))
+
+		return res
+	}
+
+	// Cache miss returns the same JSON form as the cache hit
+	qt.Check(t, qt.DeepEquals(form("1", ""), map[string]string{"name": "Jānis", "variant": ""}))
+	qt.Check(t, qt.Equals(source.calls.Load(), int32(1)))
+
+	source.name.Store("Anna")
+
+	// Cache hit decrypts the cached form
+	qt.Check(t, qt.DeepEquals(form("1", ""), map[string]string{"name": "Jānis", "variant": ""}))
+	qt.Check(t, qt.Equals(source.calls.Load(), int32(1)))
+
+	// Forms are cached per person
+	qt.Check(t, qt.DeepEquals(form("2", ""), map[string]string{"name": "Anna", "variant": ""}))
+	qt.Check(t, qt.Equals(source.calls.Load(), int32(2)))
+
+	// Other form variant is added without losing the cached variants
+	qt.Check(t, qt.DeepEquals(form("1", "a"), map[string]string{"name": "Anna", "variant": "a"}))
+	qt.Check(t, qt.DeepEquals(form("1", ""), map[string]string{"name": "Jānis", "variant": ""}))
+	qt.Check(t, qt.Equals(source.calls.Load(), int32(3)))
+
+	// Invalidation removes all form variants of the person
+	resp, err := client.Delete("/form?person=1")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(resp.StatusCode(), fasthttp.StatusNoContent))
+	fasthttp.ReleaseResponse(resp)
+
+	qt.Check(t, qt.DeepEquals(form("1", ""), map[string]string{"name": "Anna", "variant": ""}))
+	qt.Check(t, qt.DeepEquals(form("1", "a"), map[string]string{"name": "Anna", "variant": "a"}))
+	qt.Check(t, qt.Equals(source.calls.Load(), int32(5)))
+
+	// Cached forms expire
+	time.Sleep(1100 * time.Millisecond)
+
+	source.name.Store("Pēteris")
+
+	qt.Check(t, qt.DeepEquals(form("1", ""), map[string]string{"name": "Pēteris", "variant": ""}))
+	qt.Check(t, qt.Equals(source.calls.Load(), int32(6)))
+}
+
+func TestCredentialFormCache_ConcurrentVariants(t *testing.T) {
+	a := TestApp(t,
+		WithEnv("UPSTREAM_CACHE_TTL", "1m"),
+		WithEnv("UPSTREAM_CACHE_SECRET", "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI="),
+	)
+
+	source := &testSource{}
+	source.name.Store("Jānis")
+
+	qt.Assert(t, qt.IsNil(a.Credentials().Register(source)))
+
+	a.Get("/form", func(ctx *azugo.Context) {
+		form, err := a.Credentials().Form(ctx, source, "1")
+		if err != nil {
+			ctx.Error(err)
+
+			return
+		}
+
+		ctx.JSON(form)
+	})
+
+	app := azugo.NewTestApp(a.App)
+
+	app.Start(t)
+	defer app.Stop()
+
+	client := app.TestClient()
+
+	get := func(variant string) int {
+		resp, err := client.Get("/form?variant=" + variant)
+		if err != nil {
+			return 0
+		}
+
+		defer fasthttp.ReleaseResponse(resp)
+
+		return resp.StatusCode()
+	}
+
+	const variants = 10
+
+	var wg sync.WaitGroup
+
+	statuses := make([]int, variants)
+
+	for n := range variants {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			statuses[n] = get(fmt.Sprintf("v%d", n))
+		}()
+	}
+
+	wg.Wait()
+
+	for _, status := range statuses {
+		qt.Check(t, qt.Equals(status, fasthttp.StatusOK))
+	}
+
+	qt.Check(t, qt.Equals(source.calls.Load(), int32(variants)))
+
+	// No concurrently added variant is lost
+	for n := range variants {
+		qt.Check(t, qt.Equals(get(fmt.Sprintf("v%d", n)), fasthttp.StatusOK))
+	}
+
+	qt.Check(t, qt.Equals(source.calls.Load(), int32(variants)))
+}
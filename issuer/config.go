@@ -7,6 +7,9 @@ import (
 	"crypto/tls"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"azugo.io/core/cert"
@@ -23,8 +26,15 @@ type Configuration struct {
 	IssuerCertificatePasword string        `mapstructure:"issuer_certificate_password"`
 	APIURL                   string        `mapstructure:"issuer_url" validate:"required"`
 	TxCodeCacheTTL           time.Duration `mapstructure:"issuer_tx_cache_ttl" validate:"required,gt=0"`
+	BatchSize                int           `mapstructure:"batch_size" validate:"required,gt=0"`
+	BatchSizeLimits          string        `mapstructure:"batch_size_limits"`
+	TXCodeSecret             string        `mapstructure:"tx_code_secret" validate:"omitempty,base64"`
+	TXCodePolicy             string        `mapstructure:"tx_code_policy" validate:"required,oneof=require autofill"`
+	TXCodeLength             int           `mapstructure:"tx_code_length" validate:"required,min=4,max=9"`
+	TXCodeMaxAttempts        int           `mapstructure:"tx_code_max_attempts" validate:"required,gt=0"`
 
 	signingCertificate *tls.Certificate
+	batchSizeLimits    map[string]int
 }
 
 func (c *Configuration) Bind(prefix string, v *viper.Viper) {
@@ -39,6 +49,13 @@ func (c *Configuration) Bind(prefix string, v *viper.Viper) {
 	v.SetDefault(prefix+".issuer_certificate_password", password)
 
 	v.SetDefault(prefix+".issuer_tx_cache_ttl", 10*time.Minute)
+	v.SetDefault(prefix+".batch_size", 10)
+
+	txCodeSecret, _ := config.LoadRemoteSecret("ISSUER_TX_CODE_SECRET")
+	v.SetDefault(prefix+".tx_code_secret", txCodeSecret)
+	v.SetDefault(prefix+".tx_code_policy", "require")
+	v.SetDefault(prefix+".tx_code_length", 6)
+	v.SetDefault(prefix+".tx_code_max_attempts", 5)
 
 	_ = v.BindEnv(prefix+".nonce_shared_secret", "ISSUER_NONCE_SHARED_SECRET")
 	_ = v.BindEnv(prefix+".nonce_ttl", "ISSUER_NONCE_TTL")
@@ -46,6 +63,12 @@ func (c *Configuration) Bind(prefix string, v *viper.Viper) {
 	_ = v.BindEnv(prefix+".issuer_certificate_password", "ISSUER_CERTIFICATE_PASSWORD")
 	_ = v.BindEnv(prefix+".issuer_url", "ISSUER_API_URL")
 	_ = v.BindEnv(prefix+".issuer_tx_cache_ttl", "ISSUER_TX_CACHE_TTL")
+	_ = v.BindEnv(prefix+".batch_size", "ISSUER_BATCH_SIZE")
+	_ = v.BindEnv(prefix+".batch_size_limits", "ISSUER_BATCH_SIZE_LIMITS")
+	_ = v.BindEnv(prefix+".tx_code_secret", "ISSUER_TX_CODE_SECRET")
+	_ = v.BindEnv(prefix+".tx_code_policy", "ISSUER_TX_CODE_POLICY")
+	_ = v.BindEnv(prefix+".tx_code_length", "ISSUER_TX_CODE_LENGTH")
+	_ = v.BindEnv(prefix+".tx_code_max_attempts", "ISSUER_TX_CODE_MAX_ATTEMPTS")
 }
 
 func (c *Configuration) SigningCertificate() (*tls.Certificate, error) {
@@ -69,6 +92,64 @@ func (c *Configuration) SigningCertificate() (*tls.Certificate, error) {
 	return c.signingCertificate, nil
 }
 
+// TXCodeKey returns transaction code secret. If the secret is not configured, it is derived from the nonce shared secret.
+func (c *Configuration) TXCodeKey() ([]byte, error) {
+	if c.TXCodeSecret == "" {
+		b, err := base64.StdEncoding.DecodeString(c.NonceSharedSecret)
+		if err != nil {
+			return nil, errors.New("nonce_shared_secret must be a valid base64 encoded string")
+		}
+
+		return deriveKey(b, txCodeSecretInfo)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(c.TXCodeSecret)
+	if err != nil {
+		return nil, errors.New("tx_code_secret must be a valid base64 encoded string")
+	}
+
+	if len(b) != 32 {
+		return nil, errors.New("tx_code_secret must be exactly 32 bytes long")
+	}
+
+	return b, nil
+}
+
+// BatchSizeLimit returns maximum number of credentials that can be issued in a single batch request
+// for the credential configuration.
+func (c *Configuration) BatchSizeLimit(configurationID string) int {
+	if limit, ok := c.batchSizeLimits[configurationID]; ok {
+		return limit
+	}
+
+	return c.BatchSize
+}
+
+func parseBatchSizeLimits(limits string) (map[string]int, error) {
+	res := make(map[string]int)
+
+	for _, limit := range strings.Split(limits, ";") {
+		limit = strings.TrimSpace(limit)
+		if limit == "" {
+			continue
+		}
+
+		id, size, ok := strings.Cut(limit, "=")
+		if !ok {
+			return nil, fmt.Errorf("batch_size_limits must be in format <credential_configuration_id>=<size>: %s", limit)
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("batch_size_limits must have positive size for %s", id)
+		}
+
+		res[strings.TrimSpace(id)] = n
+	}
+
+	return res, nil
+}
+
 // Validate Issuer configuration section.
 func (c *Configuration) Validate(valid *validation.Validate) error {
 	if err := valid.Struct(c); err != nil {
@@ -84,6 +165,15 @@ func (c *Configuration) Validate(valid *validation.Validate) error {
 		return errors.New("nonce_shared_secret must be exactly 32 bytes long")
 	}
 
+	if _, err = c.TXCodeKey(); err != nil {
+		return err
+	}
+
+	c.batchSizeLimits, err = parseBatchSizeLimits(c.BatchSizeLimits)
+	if err != nil {
+		return err
+	}
+
 	_, err = c.SigningCertificate()
 
 	return err
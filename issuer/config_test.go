@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package issuer
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestParseBatchSizeLimits(t *testing.T) {
+	limits, err := parseBatchSizeLimits("")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.HasLen(limits, 0))
+
+	limits, err = parseBatchSizeLimits(" eu.europa.ec.eudi.pid_mdoc = 2; ;eu.europa.ec.eudi.mdl_mdoc=1;")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(limits, map[string]int{
+		"eu.europa.ec.eudi.pid_mdoc": 2,
+		"eu.europa.ec.eudi.mdl_mdoc": 1,
+	}))
+
+	_, err = parseBatchSizeLimits("eu.europa.ec.eudi.pid_mdoc")
+	qt.Check(t, qt.ErrorMatches(err, "batch_size_limits must be in format .*"))
+
+	_, err = parseBatchSizeLimits("eu.europa.ec.eudi.pid_mdoc=0")
+	qt.Check(t, qt.ErrorMatches(err, "batch_size_limits must have positive size for eu.europa.ec.eudi.pid_mdoc"))
+
+	_, err = parseBatchSizeLimits("eu.europa.ec.eudi.pid_mdoc=many")
+	qt.Check(t, qt.ErrorMatches(err, "batch_size_limits must have positive size for eu.europa.ec.eudi.pid_mdoc"))
+}
+
+func TestBatchSizeLimit(t *testing.T) {
+	c := &Configuration{
+		BatchSize:       10,
+		batchSizeLimits: map[string]int{"eu.europa.ec.eudi.pid_mdoc": 2},
+	}
+
+	qt.Check(t, qt.Equals(c.BatchSizeLimit("eu.europa.ec.eudi.pid_mdoc"), 2))
+	qt.Check(t, qt.Equals(c.BatchSizeLimit("eu.europa.ec.eudi.mdl_mdoc"), 10))
+	qt.Check(t, qt.Equals(c.BatchSizeLimit(""), 10))
+}
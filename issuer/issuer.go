@@ -3,6 +3,8 @@
 package issuer
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -12,30 +14,82 @@ import (
 
 	"git.zzdats.lv/edim/api-wallet/models"
 
+	"aidanwoods.dev/go-paseto"
 	"azugo.io/azugo"
 	"azugo.io/core/cache"
+	"azugo.io/core/http"
+	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
 )
 
 type Issuer struct {
-	ch              cache.Instance[int]
+	// ch keeps pre-authorized codes that can still be redeemed by the hash of the code
+	ch              cache.Instance[preAuthorizedCode]
+	offers          cache.Instance[string]
+	store           jsondb.Store
 	mu              sync.Mutex
+	config          *Configuration
+	ttl             time.Duration
+	txCodeHashKey   []byte
+	txCodeKey       paseto.V4SymmetricKey
 	walletPublicURL string
 }
 
-const issuerCache = "edim-wallet-api-issuer"
+const (
+	issuerCache = "edim-wallet-api-pre-auth-code"
+	offerCache  = "edim-wallet-api-offer"
+)
+
+// preAuthorizedCode is the pre-authorized code of the credential offer that can still be redeemed.
+type preAuthorizedCode struct {
+	OfferID        string    `json:"offerId"`
+	TXCodeHash     string    `json:"txCodeHash,omitempty"`
+	UpstreamTXCode string    `json:"upstreamTxCode,omitempty"`
+	Autofill       bool      `json:"autofill,omitempty"`
+	FailedAttempts int       `json:"failedAttempts,omitempty"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
 
 type CacheProvider interface {
 	Cache() *cache.Cache
 }
 
-func NewIssuer(app CacheProvider, verifyTTL time.Duration, walletPublicURL string) (*Issuer, error) {
+func NewIssuer(app CacheProvider, store jsondb.Store, config *Configuration, walletPublicURL string) (*Issuer, error) {
+	secret, err := config.TXCodeKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// Separate keys are used to hash the transaction code and to encrypt the upstream transaction code
+	hashKey, err := deriveKey(secret, txCodeHashInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := deriveKey(secret, txCodeEncryptionInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := paseto.V4SymmetricKeyFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
 	sid := &Issuer{
+		store:           store,
+		config:          config,
+		ttl:             config.TxCodeCacheTTL,
+		txCodeHashKey:   hashKey,
+		txCodeKey:       key,
 		walletPublicURL: walletPublicURL,
 	}
 
-	var err error
+	sid.ch, err = cache.Create[preAuthorizedCode](app.Cache(), issuerCache, cache.DefaultTTL(sid.ttl))
+	if err != nil {
+		return nil, err
+	}
 
-	sid.ch, err = cache.Create[int](app.Cache(), issuerCache, cache.DefaultTTL(verifyTTL))
+	sid.offers, err = cache.Create[string](app.Cache(), offerCache, cache.DefaultTTL(sid.ttl))
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +97,10 @@ func NewIssuer(app CacheProvider, verifyTTL time.Duration, walletPublicURL strin
 	return sid, nil
 }
 
-func (i *Issuer) ParseCredentialOffer(ctx *azugo.Context, credentialOffer models.GenerateCredentialOffer, showTXCode bool) (*models.GenerateCredentialOffer, error) {
+// ParseCredentialOffer stores credential offer received from the upstream issuer and returns offer by reference.
+// Transaction code shown to the user is generated locally. For trusted flows transaction code is not shown
+// and is filled in automatically if allowed by the transaction code policy.
+func (i *Issuer) ParseCredentialOffer(ctx *azugo.Context, credentialOffer models.GenerateCredentialOffer, personCode, requestType string, trusted bool) (*models.GenerateCredentialOffer, error) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
@@ -63,13 +120,57 @@ func (i *Issuer) ParseCredentialOffer(ctx *azugo.Context, credentialOffer models
 		return nil, fmt.Errorf("error parsing JSON string: %w", err)
 	}
 
-	if !showTXCode {
-		if err := i.ch.Set(ctx, offer.Grants.PreAuthorizedCode.PreAuthorizedCode, *credentialOffer.TXCode); err != nil {
-			return nil, err
+	// Pass offer by reference to keep QR code small
+	id, err := newOfferID()
+	if err != nil {
+		return nil, err
+	}
+
+	preAuthCode := offer.Grants.PreAuthorizedCode.PreAuthorizedCode
+
+	code := preAuthorizedCode{
+		OfferID:   id,
+		ExpiresAt: time.Now().Add(i.ttl),
+	}
+
+	if credentialOffer.TXCode != nil {
+		code.UpstreamTXCode = i.encryptTXCode(preAuthCode, strconv.Itoa(*credentialOffer.TXCode))
+
+		if trusted && i.config.TXCodePolicy == TXCodePolicyAutofill {
+			code.Autofill = true
+
+			offer.Grants.PreAuthorizedCode.TXCode = nil
+			credentialOffer.TXCode = nil
+		} else {
+			txCode, err := newTXCode(i.config.TXCodeLength)
+			if err != nil {
+				return nil, err
+			}
+
+			code.TXCodeHash = i.hashTXCode(preAuthCode, txCode)
+
+			n, _ := strconv.Atoi(txCode)
+			credentialOffer.TXCode = &n
+
+			tx := &models.TXCode{
+				Length:    i.config.TXCodeLength,
+				InputMode: "numeric",
+			}
+			if offer.Grants.PreAuthorizedCode.TXCode != nil {
+				tx.Description = offer.Grants.PreAuthorizedCode.TXCode.Description
+			}
+
+			offer.Grants.PreAuthorizedCode.TXCode = tx
 		}
+	}
 
-		offer.Grants.PreAuthorizedCode.TXCode = nil
-		credentialOffer.TXCode = nil
+	// New offer replaces all previous offers of the same credential type for the person
+	if err := i.cancelOffers(ctx, personCode, requestType); err != nil {
+		return nil, err
+	}
+
+	if err := i.ch.Set(ctx, preAuthorizedCodeHash(preAuthCode), code); err != nil {
+		return nil, err
 	}
 
 	offer.CredentialIssuer = i.walletPublicURL
@@ -79,10 +180,25 @@ func (i *Issuer) ParseCredentialOffer(ctx *azugo.Context, credentialOffer models
 		return nil, fmt.Errorf("error parsing JSON string: %w", err)
 	}
 
-	q.Set("credential_offer", string(buf))
+	if err := i.offers.Set(ctx, id, string(buf)); err != nil {
+		return nil, err
+	}
+
+	if err := i.createOffer(ctx, id, preAuthCode, personCode, requestType); err != nil {
+		return nil, err
+	}
+
+	offerURI, err := url.JoinPath(i.walletPublicURL, "credential-offer", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate credential offer URI: %w", err)
+	}
+
+	q = url.Values{}
+	q.Set("credential_offer_uri", offerURI)
 	u.RawQuery = q.Encode()
 
 	result := models.GenerateCredentialOffer{
+		OfferID: id,
 		URLData: u.String(),
 		TXCode:  credentialOffer.TXCode,
 	}
@@ -90,14 +206,33 @@ func (i *Issuer) ParseCredentialOffer(ctx *azugo.Context, credentialOffer models
 	return &result, nil
 }
 
-func (i *Issuer) GetTXCode(ctx *azugo.Context, preAuthorizedCode string) (string, error) {
+// CredentialOffer returns credential offer by its ID. Offer can be retrieved only once.
+func (i *Issuer) CredentialOffer(ctx *azugo.Context, id string) ([]byte, error) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
-	txCode, err := i.ch.Get(ctx, preAuthorizedCode)
+	offer, err := i.offers.Get(ctx, id)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	if offer == "" {
+		return nil, http.NotFoundError{Resource: "credential_offer"}
+	}
+
+	if err := i.offers.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return []byte(offer), nil
+}
+
+func newOfferID() (string, error) {
+	buf := make([]byte, 32)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate credential offer ID: %w", err)
 	}
 
-	return strconv.Itoa(txCode), nil
+	return base64.RawURLEncoding.EncodeToString(buf), nil
 }
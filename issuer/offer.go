@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package issuer
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/models"
+
+	"azugo.io/azugo"
+	"azugo.io/core/http"
+	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
+)
+
+// OfferState is the state of the credential offer lifecycle.
+type OfferState string
+
+const (
+	// OfferStateCreated is the state of the offer that has not yet been scanned by the wallet.
+	OfferStateCreated OfferState = "created"
+	// OfferStateTokenIssued is the state of the offer after pre-authorized code has been exchanged for the access token.
+	OfferStateTokenIssued OfferState = "token_issued"
+	// OfferStateCredentialIssued is the state of the offer after the credential has been issued to the wallet.
+	OfferStateCredentialIssued OfferState = "credential_issued"
+	// OfferStateExpired is the state of the offer that has not been used before its expiration.
+	OfferStateExpired OfferState = "expired"
+	// OfferStateCancelled is the state of the offer that has been cancelled by the person or replaced by a newer offer.
+	OfferStateCancelled OfferState = "cancelled"
+)
+
+type offer struct {
+	ID                    string     `json:"id"`
+	PreAuthorizedCodeHash string     `json:"preAuthorizedCodeHash,omitempty"`
+	PersonCode            string     `json:"personCode"`
+	RequestType           string     `json:"requestType"`
+	State                 OfferState `json:"state"`
+	CreatedAt             time.Time  `json:"createdAt"`
+	ExpiresAt             time.Time  `json:"expiresAt"`
+}
+
+// preAuthorizedCodeHash returns hash of the pre-authorized code that is used to store and look up the offer
+// so that the pre-authorized code itself is never persisted.
+func preAuthorizedCodeHash(preAuthorizedCode string) string {
+	h := sha256.Sum256([]byte(preAuthorizedCode))
+
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+func (i *Issuer) createOffer(ctx *azugo.Context, id, preAuthorizedCode, personCode, requestType string) error {
+	now := time.Now().UTC()
+
+	if err := i.store.Exec(ctx, "wallet.create_credential_offer", &offer{
+		ID:                    id,
+		PreAuthorizedCodeHash: preAuthorizedCodeHash(preAuthorizedCode),
+		PersonCode:            personCode,
+		RequestType:           requestType,
+		State:                 OfferStateCreated,
+		CreatedAt:             now,
+		ExpiresAt:             now.Add(i.ttl),
+	}, nil); err != nil {
+		return fmt.Errorf("failed to create credential offer: %w", err)
+	}
+
+	return nil
+}
+
+// OfferTokenIssued moves offer to the token issued state after its pre-authorized code has been redeemed.
+func (i *Issuer) OfferTokenIssued(ctx *azugo.Context, preAuthorizedCode, accessTokenHash string) error {
+	if err := i.store.Exec(ctx, "wallet.update_credential_offer_state", &struct {
+		PreAuthorizedCodeHash string     `json:"preAuthorizedCodeHash"`
+		AccessTokenHash       string     `json:"accessTokenHash"`
+		State                 OfferState `json:"state"`
+	}{
+		PreAuthorizedCodeHash: preAuthorizedCodeHash(preAuthorizedCode),
+		AccessTokenHash:       accessTokenHash,
+		State:                 OfferStateTokenIssued,
+	}, nil); err != nil {
+		return fmt.Errorf("failed to update credential offer state: %w", err)
+	}
+
+	return nil
+}
+
+// OfferCredentialIssued moves offer to the credential issued state after the credential has been issued
+// using the access token. Returns person code and credential request type of the offer.
+func (i *Issuer) OfferCredentialIssued(ctx *azugo.Context, accessTokenHash string) (string, string, error) {
+	o := &offer{}
+
+	if err := i.store.Exec(ctx, "wallet.update_credential_offer_state", &struct {
+		AccessTokenHash string     `json:"accessTokenHash"`
+		State           OfferState `json:"state"`
+	}{
+		AccessTokenHash: accessTokenHash,
+		State:           OfferStateCredentialIssued,
+	}, o); err != nil {
+		return "", "", fmt.Errorf("failed to update credential offer state: %w", err)
+	}
+
+	return o.PersonCode, o.RequestType, nil
+}
+
+// CancelOffer cancels pending credential offer of the person so that it can no longer be redeemed.
+func (i *Issuer) CancelOffer(ctx *azugo.Context, id, personCode string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	o := &offer{}
+
+	if err := i.store.Exec(ctx, "wallet.cancel_credential_offer", &struct {
+		ID         string `json:"id"`
+		PersonCode string `json:"personCode"`
+	}{
+		ID:         id,
+		PersonCode: personCode,
+	}, o); err != nil {
+		var eerr jsondb.ExecError
+		if errors.As(err, &eerr) && eerr.Code == "err:credential_offer:not_found" {
+			return http.NotFoundError{Resource: "credential_offer"}
+		}
+
+		return fmt.Errorf("failed to cancel credential offer: %w", err)
+	}
+
+	return i.revokeOffer(ctx, o)
+}
+
+// cancelOffers cancels all pending credential offers of the person for the credential type.
+func (i *Issuer) cancelOffers(ctx *azugo.Context, personCode, requestType string) error {
+	offers := make([]*offer, 0)
+
+	if err := i.store.Exec(ctx, "wallet.cancel_credential_offers", &struct {
+		PersonCode  string `json:"personCode"`
+		RequestType string `json:"requestType"`
+	}{
+		PersonCode:  personCode,
+		RequestType: requestType,
+	}, &offers); err != nil {
+		return fmt.Errorf("failed to cancel credential offers: %w", err)
+	}
+
+	for _, o := range offers {
+		if err := i.revokeOffer(ctx, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// revokeOffer removes cancelled offer and its pre-authorized code so that the wallet can not redeem it.
+func (i *Issuer) revokeOffer(ctx *azugo.Context, o *offer) error {
+	if o.PreAuthorizedCodeHash != "" {
+		if err := i.ch.Delete(ctx, o.PreAuthorizedCodeHash); err != nil {
+			return err
+		}
+	}
+
+	return i.offers.Delete(ctx, o.ID)
+}
+
+// OfferStatus returns credential offer status if the offer belongs to the person.
+func (i *Issuer) OfferStatus(ctx *azugo.Context, id, personCode string) (*models.CredentialOfferStatus, error) {
+	o := &offer{}
+
+	if err := i.store.Exec(ctx, "wallet.get_credential_offer", &struct {
+		ID         string `json:"id"`
+		PersonCode string `json:"personCode"`
+	}{
+		ID:         id,
+		PersonCode: personCode,
+	}, o); err != nil {
+		var eerr jsondb.ExecError
+		if errors.As(err, &eerr) && eerr.Code == "err:credential_offer:not_found" {
+			return nil, http.NotFoundError{Resource: "credential_offer"}
+		}
+
+		return nil, fmt.Errorf("failed to get credential offer: %w", err)
+	}
+
+	if o.State == OfferStateCreated && time.Now().After(o.ExpiresAt) {
+		o.State = OfferStateExpired
+	}
+
+	return &models.CredentialOfferStatus{
+		OfferID:     o.ID,
+		RequestType: o.RequestType,
+		State:       string(o.State),
+		CreatedAt:   o.CreatedAt,
+		ExpiresAt:   o.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package issuer
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"azugo.io/azugo"
+	"azugo.io/core/cache"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// TXCodePolicyRequire requires user to enter transaction code in the wallet for all credential offers.
+	TXCodePolicyRequire = "require"
+	// TXCodePolicyAutofill allows to fill in transaction code on behalf of the wallet for trusted flows.
+	TXCodePolicyAutofill = "autofill"
+)
+
+// HKDF info of the keys derived from the transaction code secret.
+const (
+	txCodeSecretInfo     = "edim-wallet-api/tx-code-secret"
+	txCodeHashInfo       = "edim-wallet-api/tx-code-hash"
+	txCodeEncryptionInfo = "edim-wallet-api/tx-code-encryption"
+)
+
+// ErrInvalidGrant is returned when pre-authorized code is unknown, cancelled, locked or transaction code is invalid.
+var ErrInvalidGrant = errors.New("invalid grant")
+
+// newTXCode generates random numeric transaction code. First digit is never zero so that
+// the code can be safely passed as a number.
+func newTXCode(length int) (string, error) {
+	var sb strings.Builder
+
+	for n := range length {
+		m := int64(10)
+		if n == 0 {
+			m = 9
+		}
+
+		d, err := rand.Int(rand.Reader, big.NewInt(m))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate transaction code: %w", err)
+		}
+
+		if n == 0 {
+			d.Add(d, big.NewInt(1))
+		}
+
+		sb.WriteString(d.String())
+	}
+
+	return sb.String(), nil
+}
+
+// deriveKey derives 32 byte key from the secret for the purpose identified by info.
+func deriveKey(secret []byte, info string) ([]byte, error) {
+	key := make([]byte, 32)
+
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte(info)), key); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	return key, nil
+}
+
+// hashTXCode returns keyed hash of the transaction code bound to the pre-authorized code.
+func (i *Issuer) hashTXCode(preAuthorizedCode, txCode string) string {
+	mac := hmac.New(sha256.New, i.txCodeHashKey)
+	mac.Write([]byte(preAuthorizedCode))
+	mac.Write([]byte{0})
+	mac.Write([]byte(txCode))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// encryptTXCode encrypts upstream issuer transaction code so that it is not stored in the cache as plain text.
+func (i *Issuer) encryptTXCode(preAuthorizedCode, txCode string) string {
+	t := paseto.NewToken()
+	t.SetSubject(txCode)
+
+	return t.V4Encrypt(i.txCodeKey, []byte(preAuthorizedCode))
+}
+
+func (i *Issuer) decryptTXCode(preAuthorizedCode, enc string) (string, error) {
+	parser := paseto.NewParserWithoutExpiryCheck()
+
+	t, err := parser.ParseV4Local(i.txCodeKey, enc, []byte(preAuthorizedCode))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt transaction code: %w", err)
+	}
+
+	return t.GetSubject()
+}
+
+// RedeemTXCode verifies transaction code entered by the user and returns transaction code
+// that must be passed to the upstream issuer. Transaction code is filled in automatically
+// only if it was not shown to the user. Pre-authorized code is locked after too many failed attempts.
+func (i *Issuer) RedeemTXCode(ctx *azugo.Context, preAuthorizedCode, txCode string) (string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	code, err := i.ch.Get(ctx, preAuthorizedCodeHash(preAuthorizedCode))
+	if err != nil {
+		return "", err
+	}
+
+	// Offer has been cancelled, expired or locked
+	if code.OfferID == "" {
+		return "", ErrInvalidGrant
+	}
+
+	if code.UpstreamTXCode == "" {
+		return "", nil
+	}
+
+	if !code.Autofill {
+		valid := txCode != "" && subtle.ConstantTimeCompare([]byte(i.hashTXCode(preAuthorizedCode, txCode)), []byte(code.TXCodeHash)) == 1
+		if !valid {
+			return "", i.failTXCode(ctx, preAuthorizedCode, code)
+		}
+	}
+
+	return i.decryptTXCode(preAuthorizedCode, code.UpstreamTXCode)
+}
+
+// failTXCode registers failed transaction code attempt and locks pre-authorized code if limit is reached.
+func (i *Issuer) failTXCode(ctx *azugo.Context, preAuthorizedCode string, code preAuthorizedCode) error {
+	code.FailedAttempts++
+
+	if code.FailedAttempts >= i.config.TXCodeMaxAttempts {
+		ctx.Log().Warn("pre-authorized code locked after too many failed transaction code attempts", zap.String("offer_id", code.OfferID))
+
+		if err := i.ch.Delete(ctx, preAuthorizedCodeHash(preAuthorizedCode)); err != nil {
+			return err
+		}
+
+		return ErrInvalidGrant
+	}
+
+	ttl := time.Until(code.ExpiresAt)
+	if ttl <= 0 {
+		return ErrInvalidGrant
+	}
+
+	if err := i.ch.Set(ctx, preAuthorizedCodeHash(preAuthorizedCode), code, cache.TTL[preAuthorizedCode](ttl)); err != nil {
+		return err
+	}
+
+	return ErrInvalidGrant
+}
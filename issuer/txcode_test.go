@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package issuer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strconv"
+	"testing"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/go-quicktest/qt"
+)
+
+func TestNewTXCode(t *testing.T) {
+	for range 100 {
+		code, err := newTXCode(6)
+		qt.Assert(t, qt.IsNil(err))
+		qt.Check(t, qt.HasLen(code, 6))
+		qt.Check(t, qt.Not(qt.Equals(code[0], '0')))
+
+		_, err = strconv.Atoi(code)
+		qt.Check(t, qt.IsNil(err))
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	secret := bytes.Repeat([]byte{1}, 32)
+
+	hashKey, err := deriveKey(secret, txCodeHashInfo)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.HasLen(hashKey, 32))
+
+	encKey, err := deriveKey(secret, txCodeEncryptionInfo)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Not(qt.DeepEquals(hashKey, encKey)))
+	qt.Check(t, qt.Not(qt.DeepEquals(hashKey, secret)))
+
+	again, err := deriveKey(secret, txCodeHashInfo)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(again, hashKey))
+}
+
+func TestTXCodeKey(t *testing.T) {
+	nonceSecret := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	txCodeSecret := bytes.Repeat([]byte{2}, 32)
+
+	// Derived from the nonce shared secret if not configured
+	c := &Configuration{NonceSharedSecret: nonceSecret}
+
+	key, err := c.TXCodeKey()
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.HasLen(key, 32))
+
+	derived, err := deriveKey(bytes.Repeat([]byte{1}, 32), txCodeSecretInfo)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(key, derived))
+
+	c.TXCodeSecret = base64.StdEncoding.EncodeToString(txCodeSecret)
+
+	key, err = c.TXCodeKey()
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(key, txCodeSecret))
+
+	c.TXCodeSecret = base64.StdEncoding.EncodeToString([]byte("short"))
+
+	_, err = c.TXCodeKey()
+	qt.Check(t, qt.ErrorMatches(err, "tx_code_secret must be exactly 32 bytes long"))
+
+	c.TXCodeSecret = "not base64!"
+
+	_, err = c.TXCodeKey()
+	qt.Check(t, qt.ErrorMatches(err, "tx_code_secret must be a valid base64 encoded string"))
+}
+
+func testTXCodeIssuer(t *testing.T, secret []byte) *Issuer {
+	t.Helper()
+
+	hashKey, err := deriveKey(secret, txCodeHashInfo)
+	qt.Assert(t, qt.IsNil(err))
+
+	b, err := deriveKey(secret, txCodeEncryptionInfo)
+	qt.Assert(t, qt.IsNil(err))
+
+	key, err := paseto.V4SymmetricKeyFromBytes(b)
+	qt.Assert(t, qt.IsNil(err))
+
+	return &Issuer{
+		txCodeHashKey: hashKey,
+		txCodeKey:     key,
+	}
+}
+
+func TestHashTXCode(t *testing.T) {
+	i := testTXCodeIssuer(t, bytes.Repeat([]byte{1}, 32))
+
+	hash := i.hashTXCode("code-1", "123456")
+	qt.Check(t, qt.Equals(i.hashTXCode("code-1", "123456"), hash))
+	qt.Check(t, qt.Not(qt.Equals(i.hashTXCode("code-1", "123457"), hash)))
+	qt.Check(t, qt.Not(qt.Equals(i.hashTXCode("code-2", "123456"), hash)))
+
+	other := testTXCodeIssuer(t, bytes.Repeat([]byte{2}, 32))
+	qt.Check(t, qt.Not(qt.Equals(other.hashTXCode("code-1", "123456"), hash)))
+}
+
+func TestEncryptTXCode(t *testing.T) {
+	i := testTXCodeIssuer(t, bytes.Repeat([]byte{1}, 32))
+
+	enc := i.encryptTXCode("code-1", "123456")
+	qt.Check(t, qt.Not(qt.StringContains(enc, "123456")))
+
+	txCode, err := i.decryptTXCode("code-1", enc)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(txCode, "123456"))
+
+	_, err = i.decryptTXCode("code-2", enc)
+	qt.Check(t, qt.ErrorMatches(err, "failed to decrypt transaction code: .*"))
+
+	other := testTXCodeIssuer(t, bytes.Repeat([]byte{2}, 32))
+
+	_, err = other.decryptTXCode("code-1", enc)
+	qt.Check(t, qt.ErrorMatches(err, "failed to decrypt transaction code: .*"))
+}
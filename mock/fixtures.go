@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package mock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/credential/ehic"
+	"git.zzdats.lv/edim/api-wallet/credential/mdl"
+	"git.zzdats.lv/edim/api-wallet/credential/pda1"
+	"git.zzdats.lv/edim/api-wallet/credential/pid"
+	"git.zzdats.lv/edim/api-wallet/credential/rtu"
+	"git.zzdats.lv/edim/api-wallet/util"
+
+	"github.com/nobid-lsp-latvia/go-idauth"
+	"github.com/valyala/fasthttp"
+)
+
+// Fixture person codes.
+const (
+	// PersonCodeFull is the person that has data in all source registries.
+	PersonCodeFull = "32345678901"
+	// PersonCodeDiplomas is the person that has two diplomas and no data in other source registries except FPRIS.
+	PersonCodeDiplomas = "32345678902"
+	// PersonCodeDeceased is the person that is deceased in all source registries.
+	PersonCodeDeceased = "32345678903"
+)
+
+// Person is the fixture person. Nil source registry data results in `404 Not Found` response.
+type Person struct {
+	// Status is the status code returned by all source registries instead of the data
+	Status int
+	// User is the IDAuth session of the person
+	User idauth.UserinfoResponse
+	// PID is the FPRIS data
+	PID *pid.Response
+	// Diplomas are the RTU diplomas
+	Diplomas []*rtu.Diploma
+	// DrivingLicence is the CSDD driving licence
+	DrivingLicence *mdl.DrivingLicence
+	// EHIC is the national health service European Health Insurance Card
+	EHIC *ehic.Card
+	// PDA1 is the social security Portable Document A1
+	PDA1 *pda1.Certificate
+}
+
+// Persons returns fixture persons by access token.
+func Persons() map[string]*Person {
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+
+	date := func(years int) util.Date {
+		return util.Date(now.AddDate(years, 0, 0))
+	}
+
+	full := newPerson(PersonCodeFull, "Jānis", "Paraugs", "1990-01-15")
+	full.Diplomas = []*rtu.Diploma{
+		newDiploma(full, "diploma-1", "Bachelor of Computer Science", now.AddDate(-10, 0, 0)),
+	}
+	full.DrivingLicence = &mdl.DrivingLicence{
+		PersonalAdministrativeNumber: PersonCodeFull,
+		DocumentNumber:               "AA1234567",
+		BirthDate:                    util.Date(time.Date(1990, 1, 15, 0, 0, 0, 0, time.UTC)),
+		GivenName:                    "Jānis",
+		FamilyName:                   "Paraugs",
+		IssueDate:                    date(-2),
+		ExpiryDate:                   date(8),
+		IssuingCountry:               "LV",
+		IssuingAuthority:             "CSDD",
+		DrivingPrivileges: []mdl.DrivingPrivilege{
+			{
+				VehicleCategoryCode: "B",
+				IssueDate:           date(-15),
+				ExpiryDate:          date(8),
+			},
+		},
+		UnDistinguishingSign: "LV",
+		Portrait:             portrait(),
+	}
+	full.EHIC = &ehic.Card{
+		PersonalAdministrativeNumber: PersonCodeFull,
+		GivenName:                    "Jānis",
+		FamilyName:                   "Paraugs",
+		BirthDate:                    util.Date(time.Date(1990, 1, 15, 0, 0, 0, 0, time.UTC)),
+		DocumentNumber:               "80428000000000000001",
+		InstitutionID:                "LV-NVD",
+		InstitutionName:              "Nacionālais veselības dienests",
+		IssuingCountry:               "LV",
+		IssuingAuthority:             "NVD",
+		IssueDate:                    date(-1),
+		ExpiryDate:                   date(4),
+	}
+	full.PDA1 = &pda1.Certificate{
+		PersonalAdministrativeNumber: PersonCodeFull,
+		GivenName:                    "Jānis",
+		FamilyName:                   "Paraugs",
+		BirthDate:                    util.Date(time.Date(1990, 1, 15, 0, 0, 0, 0, time.UTC)),
+		Nationality:                  "LV",
+		DocumentNumber:               "A1-2025-000001",
+		Employer: pda1.Employer{
+			ID:          "40003000001",
+			Name:        "SIA Paraugs",
+			CountryCode: "LV",
+		},
+		WorkCountryCode:          "EE",
+		MemberStateOfLegislation: "LV",
+		StartingDate:             date(0),
+		EndingDate:               date(1),
+		IssuingCountry:           "LV",
+		IssuingAuthority:         "VSAA",
+	}
+
+	diplomas := newPerson(PersonCodeDiplomas, "Anna", "Paraudziņa", "1985-06-30")
+	diplomas.Diplomas = []*rtu.Diploma{
+		newDiploma(diplomas, "diploma-1", "Bachelor of Economics", now.AddDate(-15, 0, 0)),
+		newDiploma(diplomas, "diploma-2", "Master of Economics", now.AddDate(-13, 0, 0)),
+	}
+
+	deceased := newPerson(PersonCodeDeceased, "Pēteris", "Paraugs", "1940-03-01")
+	deceased.Status = fasthttp.StatusGone
+
+	return map[string]*Person{
+		full.User.Code:     full,
+		diplomas.User.Code: diplomas,
+		deceased.User.Code: deceased,
+	}
+}
+
+func newPerson(code, givenName, familyName, birthDate string) *Person {
+	now := time.Now().UTC()
+
+	return &Person{
+		User: idauth.UserinfoResponse{
+			SessionID:     "01JMOCK" + code[len(code)-6:] + "0000000000000",
+			Active:        true,
+			UserID:        "PNOLV-" + code[:6] + "-" + code[6:],
+			Code:          code,
+			GivenName:     givenName,
+			FamilyName:    familyName,
+			State:         "authorized",
+			Scope:         []string{"citizen"},
+			SecondsToLive: 3600,
+		},
+		PID: &pid.Response{
+			Person: pid.Person{
+				GivenName:                    givenName,
+				FamilyName:                   familyName,
+				Nationality:                  "LV",
+				BirthDate:                    birthDate,
+				PersonalAdministrativeNumber: code,
+				BirthPlace:                   "Rīga",
+				BirthCountry:                 "LV",
+				IssuingAuthority:             "PMLP",
+				IssuingCountry:               "LV",
+				AgeOver18:                    true,
+			},
+			IssuanceDate: now.Format(time.DateOnly),
+			ExpiryDate:   now.AddDate(5, 0, 0).Format(time.DateOnly),
+		},
+	}
+}
+
+func newDiploma(p *Person, id, title string, awarded time.Time) *rtu.Diploma {
+	return &rtu.Diploma{
+		ID:                       id,
+		GivenName:                p.User.GivenName,
+		FamilyName:               p.User.FamilyName,
+		NationalID:               p.User.Code,
+		CitizenshipCountryCode:   "LV",
+		Type:                     "diploma",
+		Title:                    title,
+		AwardingDate:             awarded,
+		AwardingBodyLegalName:    "Rīgas Tehniskā universitāte",
+		AwardingBodyRegistration: "90000068977",
+		AwardingBodyCountryCode:  "LV",
+		CreditPoint:              "ECTS",
+		CreditValue:              "180",
+		MaximumDuration:          "P3Y",
+		ThematicArea:             "0613",
+		NqfLevel:                 "6",
+		EqfLevel:                 "6",
+		ValidFrom:                awarded,
+		IssuanceDate:             awarded,
+		Issued:                   awarded,
+	}
+}
+
+// portrait returns base64 encoded JPEG portrait.
+func portrait() string {
+	img := image.NewGray(image.Rect(0, 0, 240, 320))
+
+	for y := range 320 {
+		for x := range 240 {
+			img.SetGray(x, y, color.Gray{Y: uint8((x + y) % 256)}) //nolint:gosec
+		}
+	}
+
+	var buf bytes.Buffer
+
+	_ = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80})
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
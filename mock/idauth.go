@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package mock
+
+import (
+	"git.zzdats.lv/edim/api-wallet/mock/idauthtest"
+
+	"github.com/nobid-lsp-latvia/go-idauth"
+	"github.com/valyala/fasthttp"
+)
+
+// idauth serves IDAuth session endpoint of the fixture persons. Unknown access token results in an inactive session.
+func (s *Server) idauth(ctx *fasthttp.RequestCtx) {
+	idauthtest.SessionHandler(s.user)(ctx)
+}
+
+// user returns IDAuth session of the fixture person by the access token.
+func (s *Server) user(token string) (idauth.UserinfoResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.Persons[token]
+	if !ok {
+		return idauth.UserinfoResponse{}, false
+	}
+
+	return p.User, true
+}
@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package idauthtest provides in-process fake of the IDAuth session endpoint for tests.
+package idauthtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nobid-lsp-latvia/go-idauth"
+	"github.com/valyala/fasthttp"
+)
+
+// Server is the fake IDAuth server. Sessions are selected by the access token from the `Authorization` header.
+// Unknown access token results in an inactive session.
+type Server struct {
+	url   string
+	srv   *fasthttp.Server
+	mu    sync.Mutex
+	users map[string]*idauth.UserinfoResponse
+}
+
+// UserOption configures the session of the fake user.
+type UserOption func(u *idauth.UserinfoResponse)
+
+// WithState sets the session state. Default state is `authorized`.
+func WithState(state string) UserOption {
+	return func(u *idauth.UserinfoResponse) {
+		u.State = state
+	}
+}
+
+// WithScopes sets the user scopes. Default scope is `citizen`.
+func WithScopes(scopes ...string) UserOption {
+	return func(u *idauth.UserinfoResponse) {
+		u.Scope = scopes
+	}
+}
+
+// WithName sets the user given and family name.
+func WithName(givenName, familyName string) UserOption {
+	return func(u *idauth.UserinfoResponse) {
+		u.GivenName = givenName
+		u.FamilyName = familyName
+	}
+}
+
+// Inactive marks the session as inactive.
+func Inactive() UserOption {
+	return func(u *idauth.UserinfoResponse) {
+		u.Active = false
+	}
+}
+
+// New starts fake IDAuth server that is stopped when the test finishes.
+func New(tb testing.TB) *Server {
+	tb.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("failed to start fake IDAuth server: %v", err)
+	}
+
+	s := &Server{
+		url:   "http://" + ln.Addr().String(),
+		users: make(map[string]*idauth.UserinfoResponse),
+	}
+
+	s.srv = &fasthttp.Server{
+		Handler: SessionHandler(s.User),
+	}
+
+	go func() {
+		_ = s.srv.Serve(ln)
+	}()
+
+	tb.Cleanup(func() {
+		_ = s.srv.Shutdown()
+	})
+
+	return s
+}
+
+// URL returns IDAuth URL that must be set as `IDAUTH_URL`.
+func (s *Server) URL() string {
+	return s.url
+}
+
+// AddUser adds user session with the person code. Returns access token of the session.
+func (s *Server) AddUser(code string, opts ...UserOption) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.users) + 1
+	tok := fmt.Sprintf("token-%d", n)
+
+	u := &idauth.UserinfoResponse{
+		SessionID:     fmt.Sprintf("%026d", n),
+		Active:        true,
+		UserID:        "PNOLV-" + code,
+		Code:          code,
+		State:         "authorized",
+		Scope:         []string{"citizen"},
+		SecondsToLive: 3600,
+	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	s.users[tok] = u
+
+	return tok
+}
+
+// User returns session of the access token.
+func (s *Server) User(token string) (idauth.UserinfoResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[token]
+	if !ok {
+		return idauth.UserinfoResponse{}, false
+	}
+
+	return *u, true
+}
+
+// Authorization returns `Authorization` header value for the access token.
+func Authorization(token string) string {
+	return "Bearer " + token
+}
+
+// SessionHandler serves IDAuth session endpoint using the session lookup by the access token.
+// Unknown access token results in an inactive session.
+func SessionHandler(lookup func(token string) (idauth.UserinfoResponse, bool)) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if string(ctx.Path()) != "/api/1.0/session" || !ctx.IsGet() {
+			ctx.SetStatusCode(fasthttp.StatusNotFound)
+
+			return
+		}
+
+		auth := string(ctx.Request.Header.Peek(fasthttp.HeaderAuthorization))
+		tok, _ := strings.CutPrefix(auth, "Bearer ")
+
+		u, ok := lookup(tok)
+		if !ok {
+			u = idauth.UserinfoResponse{
+				State: "none",
+			}
+		}
+
+		buf, err := json.Marshal(&u)
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+
+			return
+		}
+
+		ctx.SetContentType("application/json")
+		ctx.SetBody(buf)
+	}
+}
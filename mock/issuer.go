@@ -0,0 +1,266 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+//nolint:tagliatelle
+package mock
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"git.zzdats.lv/edim/api-wallet/models"
+
+	"github.com/valyala/fasthttp"
+)
+
+// credentialConfigurations are the credential configurations supported by the issuer fake.
+var credentialConfigurations = map[string]string{
+	"eu.europa.ec.eudi.pid_mdoc":              "mso_mdoc",
+	"eu.europa.ec.eudi.rtu_diploma_mdoc":      "mso_mdoc",
+	"eu.europa.ec.eudi.mdl_mdoc":              "mso_mdoc",
+	"eu.europa.ec.eudi.age_verification_mdoc": "mso_mdoc",
+	"eu.europa.ec.eudi.ehic_sd_jwt_vc":        "vc+sd-jwt",
+	"eu.europa.ec.eudi.pda1_sd_jwt_vc":        "vc+sd-jwt",
+}
+
+type issuerOffer struct {
+	txCode                   string
+	credentialConfigurations []string
+}
+
+// issuerState keeps issued pre-authorized codes and access tokens of the issuer fake.
+type issuerState struct {
+	offers map[string]*issuerOffer
+	tokens map[string]*issuerOffer
+}
+
+func newIssuerState() issuerState {
+	return issuerState{
+		offers: make(map[string]*issuerOffer),
+		tokens: make(map[string]*issuerOffer),
+	}
+}
+
+// issuerAPI serves the upstream issuer endpoints used by the wallet API.
+func (s *Server) issuerAPI(ctx *fasthttp.RequestCtx) {
+	switch path := string(ctx.Path()); {
+	case path == "/.well-known/openid-credential-issuer" && ctx.IsGet():
+		s.issuerMetadata(ctx)
+	case path == "/.well-known/openid-configuration" && ctx.IsGet():
+		writeJSON(ctx, fasthttp.StatusOK, map[string]any{
+			"issuer":                s.url + PrefixIssuer,
+			"token_endpoint":        s.url + PrefixIssuer + "/token",
+			"jwks_uri":              s.url + PrefixIssuer + "/static/jwks.json",
+			"grant_types_supported": []string{"urn:ietf:params:oauth:grant-type:pre-authorized_code"},
+		})
+	case path == "/static/jwks.json" && ctx.IsGet():
+		writeJSON(ctx, fasthttp.StatusOK, map[string]any{
+			"keys": []any{},
+		})
+	case path == "/generate_credential_offer" && ctx.IsPost():
+		s.generateCredentialOffer(ctx)
+	case path == "/token" && ctx.IsPost():
+		s.token(ctx)
+	case path == "/credential" && ctx.IsPost():
+		s.credential(ctx)
+	case path == "/deferred_credential" && ctx.IsPost():
+		oauthError(ctx, fasthttp.StatusBadRequest, "invalid_transaction_id")
+	default:
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+	}
+}
+
+func (s *Server) issuerMetadata(ctx *fasthttp.RequestCtx) {
+	configs := make(map[string]any, len(credentialConfigurations))
+	for id, format := range credentialConfigurations {
+		configs[id] = map[string]any{
+			"format": format,
+			"proof_types_supported": map[string]any{
+				"jwt": map[string]any{
+					"proof_signing_alg_values_supported": []string{"ES256"},
+				},
+			},
+		}
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, map[string]any{
+		"credential_issuer":                   s.url + PrefixIssuer,
+		"credential_endpoint":                 s.url + PrefixIssuer + "/credential",
+		"credential_configurations_supported": configs,
+	})
+}
+
+func (s *Server) generateCredentialOffer(ctx *fasthttp.RequestCtx) {
+	req := struct {
+		CredentialIDs      []string `json:"credentialIds"`
+		CredentialOfferURI string   `json:"credentialOfferURI"`
+		Form               any      `json:"form"`
+	}{}
+
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil || len(req.CredentialIDs) == 0 || req.Form == nil {
+		oauthError(ctx, fasthttp.StatusBadRequest, "invalid_request")
+
+		return
+	}
+
+	for _, id := range req.CredentialIDs {
+		if _, ok := credentialConfigurations[id]; !ok {
+			oauthError(ctx, fasthttp.StatusBadRequest, "unsupported_credential_type")
+
+			return
+		}
+	}
+
+	txCode := randomDigits(5)
+	code := randomToken()
+
+	s.mu.Lock()
+	s.issuer.offers[code] = &issuerOffer{
+		txCode:                   txCode,
+		credentialConfigurations: req.CredentialIDs,
+	}
+	s.mu.Unlock()
+
+	offer := models.CredentialOffer{
+		CredentialIssuer:           s.url + PrefixIssuer,
+		CredentialConfigurationIDs: req.CredentialIDs,
+		Grants: models.Grants{
+			PreAuthorizedCode: models.PreAuthorizedCode{
+				PreAuthorizedCode: code,
+				TXCode: &models.TXCode{
+					Length:      len(txCode),
+					InputMode:   "numeric",
+					Description: "Enter the transaction code",
+				},
+			},
+		},
+	}
+
+	buf, err := json.Marshal(&offer)
+	if err != nil {
+		ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+
+		return
+	}
+
+	n, _ := strconv.Atoi(txCode)
+
+	writeJSON(ctx, fasthttp.StatusOK, &models.GenerateCredentialOffer{
+		TXCode:  &n,
+		URLData: req.CredentialOfferURI + "?" + url.Values{"credential_offer": {string(buf)}}.Encode(),
+	})
+}
+
+func (s *Server) token(ctx *fasthttp.RequestCtx) {
+	args := ctx.PostArgs()
+
+	if string(args.Peek("grant_type")) != "urn:ietf:params:oauth:grant-type:pre-authorized_code" {
+		oauthError(ctx, fasthttp.StatusBadRequest, "unsupported_grant_type")
+
+		return
+	}
+
+	code := string(args.Peek("pre-authorized_code"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offer, ok := s.issuer.offers[code]
+	if !ok || offer.txCode != string(args.Peek("tx_code")) {
+		oauthError(ctx, fasthttp.StatusBadRequest, "invalid_grant")
+
+		return
+	}
+
+	delete(s.issuer.offers, code)
+
+	tok := randomToken()
+	s.issuer.tokens[tok] = offer
+
+	writeJSON(ctx, fasthttp.StatusOK, map[string]any{
+		"access_token": tok,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (s *Server) credential(ctx *fasthttp.RequestCtx) {
+	auth := string(ctx.Request.Header.Peek(fasthttp.HeaderAuthorization))
+	tok, ok := strings.CutPrefix(auth, "Bearer ")
+
+	if !ok {
+		tok, _ = strings.CutPrefix(auth, "DPoP ")
+	}
+
+	req := struct {
+		CredentialConfigurationID string `json:"credential_configuration_id"`
+	}{}
+
+	_ = json.Unmarshal(ctx.PostBody(), &req)
+
+	s.mu.Lock()
+	offer, ok := s.issuer.tokens[tok]
+	s.mu.Unlock()
+
+	if !ok {
+		oauthError(ctx, fasthttp.StatusUnauthorized, "invalid_token")
+
+		return
+	}
+
+	id := req.CredentialConfigurationID
+	if id == "" {
+		id = offer.credentialConfigurations[0]
+	}
+
+	// Credential is not signed, it only identifies the issued credential configuration
+	cred, _ := json.Marshal(map[string]string{
+		"credential_configuration_id": id,
+		"issuer":                      s.url + PrefixIssuer,
+	})
+
+	writeJSON(ctx, fasthttp.StatusOK, map[string]any{
+		"credentials": []map[string]string{
+			{"credential": base64.RawURLEncoding.EncodeToString(cred)},
+		},
+	})
+}
+
+// oauthError writes OAuth 2.0 error response.
+func oauthError(ctx *fasthttp.RequestCtx, status int, code string) {
+	writeJSON(ctx, status, map[string]string{
+		"error": code,
+	})
+}
+
+func randomToken() string {
+	buf := make([]byte, 24)
+	_, _ = rand.Read(buf)
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// randomDigits returns random numeric code with non-zero first digit.
+func randomDigits(length int) string {
+	var sb strings.Builder
+
+	for n := range length {
+		m := int64(10)
+		if n == 0 {
+			m = 9
+		}
+
+		d, _ := rand.Int(rand.Reader, big.NewInt(m))
+		if n == 0 {
+			d.Add(d, big.NewInt(1))
+		}
+
+		sb.WriteString(d.String())
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package jsondbtest
+
+import (
+	"context"
+	"encoding/json"
+
+	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
+)
+
+// DeferredTransaction is the deferred credential issuance transaction.
+type DeferredTransaction struct {
+	TransactionID             string `json:"transactionId"`
+	AccessTokenHash           string `json:"accessTokenHash"`
+	Status                    string `json:"status"`
+	CredentialConfigurationID string `json:"credentialConfigurationId,omitempty"`
+}
+
+// DeferredTransaction returns deferred credential issuance transaction by its ID.
+func (s *Store) DeferredTransaction(transactionID string) (DeferredTransaction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.deferred[transactionID]
+	if !ok {
+		return DeferredTransaction{}, false
+	}
+
+	return *t, true
+}
+
+func (s *Store) createDeferredTransaction(_ context.Context, params json.RawMessage) (any, error) {
+	t := &DeferredTransaction{}
+	if err := json.Unmarshal(params, t); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deferred[t.TransactionID] = t
+
+	return nil, nil
+}
+
+func (s *Store) getDeferredTransaction(_ context.Context, params json.RawMessage) (any, error) {
+	p := &DeferredTransaction{}
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.deferred[p.TransactionID]
+	if !ok {
+		return nil, jsondb.ExecError{Code: "err:deferred_transaction:not_found", Message: "deferred transaction not found"}
+	}
+
+	return *t, nil
+}
+
+func (s *Store) completeDeferredTransaction(_ context.Context, params json.RawMessage) (any, error) {
+	p := &DeferredTransaction{}
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.deferred[p.TransactionID]
+	if !ok {
+		return nil, jsondb.ExecError{Code: "err:deferred_transaction:not_found", Message: "deferred transaction not found"}
+	}
+
+	t.Status = "issued"
+
+	return nil, nil
+}
@@ -0,0 +1,318 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package jsondbtest provides in-memory fake of the wallet database methods for tests.
+package jsondbtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"azugo.io/core"
+	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
+	"github.com/oklog/ulid/v2"
+)
+
+// Procedure implements database method. Result is returned to the caller as JSON.
+type Procedure func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Person is the person the wallet instance belongs to.
+type Person struct {
+	Code          string `json:"code"`
+	GivenName     string `json:"givenName,omitempty"`
+	FamilyName    string `json:"familyName,omitempty"`
+	RequesterCode string `json:"requesterCode,omitempty"`
+}
+
+// Instance is the registered wallet instance.
+type Instance struct {
+	ID             string    `json:"id"`
+	Status         string    `json:"status"`
+	HardwareKeyTag string    `json:"hardwareKeyTag"`
+	CertsIssued    int       `json:"certsIssued,omitempty"`
+	DeviceType     string    `json:"deviceType"`
+	PublicKey      string    `json:"publicKey"`
+	FirebaseID     string    `json:"firebaseId,omitempty"`
+	Person         *Person   `json:"person,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// Anonymous reports if the instance was registered without identified person.
+func (i *Instance) Anonymous() bool {
+	return i.Person == nil || i.Person.Code == "" || i.Person.Code == "anonymous"
+}
+
+// Store is in-memory implementation of the jsondb.Store.
+//
+// Wallet instance, deferred transaction, notification and credential offer methods are implemented with the same error codes
+// as the database, other methods can be added using Handle.
+type Store struct {
+	// Now returns current time. Can be replaced to control instance age.
+	Now func() time.Time
+
+	mu            sync.Mutex
+	procs         map[string]Procedure
+	instances     map[string]*Instance
+	deferred      map[string]*DeferredTransaction
+	notifications map[string]*Notification
+	offers        map[string]*CredentialOffer
+	tasks         []core.Tasker
+}
+
+var _ jsondb.Store = (*Store)(nil)
+
+// New returns empty in-memory store.
+func New() *Store {
+	s := &Store{
+		Now:           time.Now,
+		instances:     make(map[string]*Instance),
+		deferred:      make(map[string]*DeferredTransaction),
+		notifications: make(map[string]*Notification),
+		offers:        make(map[string]*CredentialOffer),
+	}
+
+	s.procs = map[string]Procedure{
+		"wallet.create_instance":                s.createInstance,
+		"wallet.get_public_key":                 s.getPublicKey,
+		"wallet.get_instance_by_tag":            s.getInstanceByTag,
+		"wallet.delete_inactive_instances":      s.deleteInactiveInstances,
+		"wallet.create_deferred_transaction":    s.createDeferredTransaction,
+		"wallet.get_deferred_transaction":       s.getDeferredTransaction,
+		"wallet.complete_deferred_transaction":  s.completeDeferredTransaction,
+		"wallet.create_credential_notification": s.createCredentialNotification,
+		"wallet.create_notification_event":      s.createNotificationEvent,
+		"wallet.create_credential_offer":        s.createCredentialOffer,
+		"wallet.update_credential_offer_state":  s.updateCredentialOfferState,
+		"wallet.cancel_credential_offer":        s.cancelCredentialOffer,
+		"wallet.cancel_credential_offers":       s.cancelCredentialOffers,
+		"wallet.get_credential_offer":           s.getCredentialOffer,
+	}
+
+	return s
+}
+
+// Handle registers or replaces database method implementation.
+func (s *Store) Handle(method string, proc Procedure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.procs[method] = proc
+}
+
+// Start implements jsondb.Store. In-memory store is always ready.
+func (s *Store) Start(_ context.Context) error {
+	return nil
+}
+
+// IsReady implements jsondb.Store.
+func (s *Store) IsReady() bool {
+	return true
+}
+
+// Close implements jsondb.Store.
+func (s *Store) Close() {}
+
+// AddTask implements jsondb.Store. Tasks are not started by the in-memory store.
+func (s *Store) AddTask(task core.Tasker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks = append(s.tasks, task)
+}
+
+// Tasks returns tasks added to the store.
+func (s *Store) Tasks() []core.Tasker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]core.Tasker(nil), s.tasks...)
+}
+
+// Ping implements jsondb.Store.
+func (s *Store) Ping(_ context.Context) error {
+	return nil
+}
+
+// Exec executes database method. Parameters and result are passed through JSON
+// the same way as for the database.
+func (s *Store) Exec(ctx context.Context, method string, params any, result any) error {
+	s.mu.Lock()
+	proc, ok := s.procs[method]
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("failed to execute procedure: method %s does not exist", method)
+	}
+
+	data := []byte("{}")
+
+	if params != nil {
+		var err error
+
+		data, err = json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to marshal params to JSON: %w", err)
+		}
+	}
+
+	res, err := proc(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	if result == nil || res == nil {
+		return nil
+	}
+
+	buf, err := json.Marshal(res)
+	if err != nil {
+		return fmt.Errorf("failed to marshal procedure result: %w", err)
+	}
+
+	if err := json.Unmarshal(buf, result); err != nil {
+		return fmt.Errorf("failed to unmarshal procedure result: %w", err)
+	}
+
+	return nil
+}
+
+// AddInstance adds wallet instance to the store. Returns instance ID.
+func (s *Store) AddInstance(i Instance) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addInstance(&i)
+}
+
+// Instance returns wallet instance by its hardware key tag.
+func (s *Store) Instance(hardwareKeyTag string) (Instance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.instances[hardwareKeyTag]
+	if !ok {
+		return Instance{}, false
+	}
+
+	return *i, true
+}
+
+// Instances returns count of the registered wallet instances.
+func (s *Store) Instances() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.instances)
+}
+
+func (s *Store) addInstance(i *Instance) string {
+	// Registering the same hardware key again replaces the instance
+	if prev, ok := s.instances[i.HardwareKeyTag]; ok && i.ID == "" {
+		i.ID = prev.ID
+	}
+
+	if i.ID == "" {
+		i.ID = ulid.Make().String()
+	}
+
+	if i.Status == "" {
+		i.Status = "active"
+	}
+
+	if i.CreatedAt.IsZero() {
+		i.CreatedAt = s.Now().UTC()
+	}
+
+	s.instances[i.HardwareKeyTag] = i
+
+	return i.ID
+}
+
+func (s *Store) createInstance(_ context.Context, params json.RawMessage) (any, error) {
+	i := &Instance{}
+	if err := json.Unmarshal(params, i); err != nil {
+		return nil, err
+	}
+
+	i.ID = ""
+	i.Status = ""
+	i.CreatedAt = time.Time{}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addInstance(i)
+
+	return nil, nil
+}
+
+type instanceParams struct {
+	Type           string `json:"type"`
+	HardwareKeyTag string `json:"hardwareKeyTag"`
+}
+
+func (s *Store) getPublicKey(_ context.Context, params json.RawMessage) (any, error) {
+	p := &instanceParams{}
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.instances[p.HardwareKeyTag]
+	if !ok {
+		return nil, jsondb.ExecError{Code: "err:public_key:not_found", Message: "public key not found"}
+	}
+
+	return struct {
+		PublicKey string  `json:"publicKey"`
+		Person    *Person `json:"person"`
+	}{
+		PublicKey: i.PublicKey,
+		Person:    i.Person,
+	}, nil
+}
+
+func (s *Store) getInstanceByTag(_ context.Context, params json.RawMessage) (any, error) {
+	p := &instanceParams{}
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.instances[p.HardwareKeyTag]
+	if !ok {
+		return nil, jsondb.ExecError{Code: "err:instance:not_found", Message: "instance not found"}
+	}
+
+	return *i, nil
+}
+
+// deleteInactiveInstances removes anonymous wallet instances that are older than the given time.
+func (s *Store) deleteInactiveInstances(_ context.Context, params json.RawMessage) (any, error) {
+	p := &struct {
+		//nolint:tagliatelle
+		OlderThanInMin int `json:"OlderThanInMin"`
+	}{}
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+
+	before := s.Now().UTC().Add(-time.Duration(p.OlderThanInMin) * time.Minute)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for tag, i := range s.instances {
+		if i.Anonymous() && i.CreatedAt.Before(before) {
+			delete(s.instances, tag)
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package jsondbtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-quicktest/qt"
+	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
+)
+
+func createInstance(t *testing.T, s *Store, tag, personCode string) {
+	t.Helper()
+
+	err := s.Exec(context.Background(), "wallet.create_instance", map[string]any{
+		"hardwareKeyTag": tag,
+		"deviceType":     "android",
+		"publicKey":      "-----BEGIN PUBLIC KEY-----",
+		"person": map[string]any{
+			"code":          personCode,
+			"requesterCode": personCode,
+		},
+	}, nil)
+	qt.Assert(t, qt.IsNil(err))
+}
+
+func TestInstance(t *testing.T) {
+	s := New()
+
+	createInstance(t, s, "tag1", "32345678901")
+
+	key := struct {
+		PublicKey string `json:"publicKey"`
+		Person    *struct {
+			Code string `json:"code"`
+		} `json:"person"`
+	}{}
+
+	err := s.Exec(context.Background(), "wallet.get_public_key", map[string]any{"type": "instance", "hardwareKeyTag": "tag1"}, &key)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(key.PublicKey, "-----BEGIN PUBLIC KEY-----"))
+	qt.Assert(t, qt.IsNotNil(key.Person))
+	qt.Check(t, qt.Equals(key.Person.Code, "32345678901"))
+
+	inst := struct {
+		ID             string `json:"id"`
+		Status         string `json:"status"`
+		HardwareKeyTag string `json:"hardwareKeyTag"`
+	}{}
+
+	err = s.Exec(context.Background(), "wallet.get_instance_by_tag", map[string]any{"type": "instance", "hardwareKeyTag": "tag1"}, &inst)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Not(qt.Equals(inst.ID, "")))
+	qt.Check(t, qt.Equals(inst.Status, "active"))
+	qt.Check(t, qt.Equals(inst.HardwareKeyTag, "tag1"))
+
+	// Registering the same key again keeps instance ID
+	createInstance(t, s, "tag1", "32345678901")
+
+	i, ok := s.Instance("tag1")
+	qt.Assert(t, qt.IsTrue(ok))
+	qt.Check(t, qt.Equals(i.ID, inst.ID))
+	qt.Check(t, qt.Equals(s.Instances(), 1))
+}
+
+func TestNotFound(t *testing.T) {
+	s := New()
+
+	tests := []struct {
+		method string
+		code   string
+	}{
+		{method: "wallet.get_public_key", code: "err:public_key:not_found"},
+		{method: "wallet.get_instance_by_tag", code: "err:instance:not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			err := s.Exec(context.Background(), tt.method, map[string]any{"type": "instance", "hardwareKeyTag": "unknown"}, &struct{}{})
+
+			var eerr jsondb.ExecError
+
+			qt.Assert(t, qt.IsTrue(errors.As(err, &eerr)))
+			qt.Check(t, qt.Equals(eerr.Code, tt.code))
+		})
+	}
+}
+
+func TestDeleteInactiveInstances(t *testing.T) {
+	s := New()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.Now = func() time.Time { return now }
+
+	createInstance(t, s, "old-anonymous", "anonymous")
+	createInstance(t, s, "old-person", "32345678901")
+
+	now = now.Add(time.Hour)
+
+	createInstance(t, s, "new-anonymous", "anonymous")
+
+	err := s.Exec(context.Background(), "wallet.delete_inactive_instances", map[string]any{"OlderThanInMin": 30}, nil)
+	qt.Assert(t, qt.IsNil(err))
+
+	_, ok := s.Instance("old-anonymous")
+	qt.Check(t, qt.IsFalse(ok))
+
+	_, ok = s.Instance("old-person")
+	qt.Check(t, qt.IsTrue(ok))
+
+	_, ok = s.Instance("new-anonymous")
+	qt.Check(t, qt.IsTrue(ok))
+}
+
+func TestHandle(t *testing.T) {
+	s := New()
+
+	err := s.Exec(context.Background(), "wallet.get_instance_history", nil, nil)
+	qt.Check(t, qt.IsNotNil(err))
+
+	s.Handle("wallet.get_instance_history", func(_ context.Context, _ json.RawMessage) (any, error) {
+		return map[string]string{"id": "history1"}, nil
+	})
+
+	res := struct {
+		ID string `json:"id"`
+	}{}
+
+	err = s.Exec(context.Background(), "wallet.get_instance_history", nil, &res)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(res.ID, "history1"))
+}
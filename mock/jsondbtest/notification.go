@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package jsondbtest
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
+)
+
+// Notification is the notification of the issued credential with events reported by the wallet.
+type Notification struct {
+	NotificationID            string   `json:"notificationId"`
+	AccessTokenHash           string   `json:"accessTokenHash"`
+	InstanceID                string   `json:"instanceId,omitempty"`
+	CredentialConfigurationID string   `json:"credentialConfigurationId,omitempty"`
+	Events                    []string `json:"-"`
+}
+
+// Notification returns notification of the issued credential by its ID.
+func (s *Store) Notification(notificationID string) (Notification, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notifications[notificationID]
+	if !ok {
+		return Notification{}, false
+	}
+
+	res := *n
+	res.Events = slices.Clone(n.Events)
+
+	return res, true
+}
+
+func (s *Store) createCredentialNotification(_ context.Context, params json.RawMessage) (any, error) {
+	n := &Notification{}
+	if err := json.Unmarshal(params, n); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notifications[n.NotificationID] = n
+
+	return nil, nil
+}
+
+func (s *Store) createNotificationEvent(_ context.Context, params json.RawMessage) (any, error) {
+	p := struct {
+		NotificationID  string `json:"notificationId"`
+		AccessTokenHash string `json:"accessTokenHash"`
+		InstanceID      string `json:"instanceId"`
+		Event           string `json:"event"`
+	}{}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notifications[p.NotificationID]
+	if !ok || n.AccessTokenHash != p.AccessTokenHash || n.InstanceID != p.InstanceID {
+		return nil, jsondb.ExecError{Code: "err:notification:not_found", Message: "notification not found"}
+	}
+
+	n.Events = append(n.Events, p.Event)
+
+	return nil, nil
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package jsondbtest
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
+)
+
+// Credential offer states.
+const (
+	OfferStateCreated          = "created"
+	OfferStateTokenIssued      = "token_issued"
+	OfferStateCredentialIssued = "credential_issued"
+	OfferStateCancelled        = "cancelled"
+)
+
+// CredentialOffer is the credential offer with its lifecycle state.
+type CredentialOffer struct {
+	ID                    string    `json:"id"`
+	PreAuthorizedCodeHash string    `json:"preAuthorizedCodeHash,omitempty"`
+	AccessTokenHash       string    `json:"accessTokenHash,omitempty"`
+	PersonCode            string    `json:"personCode"`
+	RequestType           string    `json:"requestType"`
+	State                 string    `json:"state"`
+	CreatedAt             time.Time `json:"createdAt"`
+	ExpiresAt             time.Time `json:"expiresAt"`
+}
+
+var errOfferNotFound = jsondb.ExecError{Code: "err:credential_offer:not_found", Message: "credential offer not found"}
+
+// CredentialOffer returns credential offer by its ID.
+func (s *Store) CredentialOffer(id string) (CredentialOffer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.offers[id]
+	if !ok {
+		return CredentialOffer{}, false
+	}
+
+	return *o, true
+}
+
+func (s *Store) createCredentialOffer(_ context.Context, params json.RawMessage) (any, error) {
+	o := &CredentialOffer{}
+	if err := json.Unmarshal(params, o); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offers[o.ID] = o
+
+	return nil, nil
+}
+
+// updateCredentialOfferState moves created offer to the token issued state by the hash of the pre-authorized code
+// and offer with issued token to the credential issued state by the hash of the access token.
+func (s *Store) updateCredentialOfferState(_ context.Context, params json.RawMessage) (any, error) {
+	p := struct {
+		PreAuthorizedCodeHash string `json:"preAuthorizedCodeHash"`
+		AccessTokenHash       string `json:"accessTokenHash"`
+		State                 string `json:"state"`
+	}{}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, o := range s.offers {
+		switch {
+		case p.State == OfferStateTokenIssued && p.PreAuthorizedCodeHash != "" &&
+			o.PreAuthorizedCodeHash == p.PreAuthorizedCodeHash && o.State == OfferStateCreated:
+			o.AccessTokenHash = p.AccessTokenHash
+		case p.State == OfferStateCredentialIssued && p.AccessTokenHash != "" &&
+			o.AccessTokenHash == p.AccessTokenHash && o.State == OfferStateTokenIssued:
+		default:
+			continue
+		}
+
+		o.State = p.State
+
+		return *o, nil
+	}
+
+	return nil, errOfferNotFound
+}
+
+func (s *Store) cancelCredentialOffer(_ context.Context, params json.RawMessage) (any, error) {
+	p := struct {
+		ID         string `json:"id"`
+		PersonCode string `json:"personCode"`
+	}{}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.offers[p.ID]
+	if !ok || o.PersonCode != p.PersonCode || o.State != OfferStateCreated || !s.Now().Before(o.ExpiresAt) {
+		return nil, errOfferNotFound
+	}
+
+	o.State = OfferStateCancelled
+
+	return *o, nil
+}
+
+func (s *Store) cancelCredentialOffers(_ context.Context, params json.RawMessage) (any, error) {
+	p := struct {
+		PersonCode  string `json:"personCode"`
+		RequestType string `json:"requestType"`
+	}{}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]CredentialOffer, 0)
+
+	for _, o := range s.offers {
+		if o.PersonCode != p.PersonCode || o.RequestType != p.RequestType || o.State != OfferStateCreated {
+			continue
+		}
+
+		o.State = OfferStateCancelled
+
+		res = append(res, *o)
+	}
+
+	return res, nil
+}
+
+func (s *Store) getCredentialOffer(_ context.Context, params json.RawMessage) (any, error) {
+	p := struct {
+		ID         string `json:"id"`
+		PersonCode string `json:"personCode"`
+	}{}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.offers[p.ID]
+	if !ok || o.PersonCode != p.PersonCode {
+		return nil, errOfferNotFound
+	}
+
+	return *o, nil
+}
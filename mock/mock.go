@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package mock provides in-process fakes of the upstream services for local development and CI.
+//
+// All upstreams are served by a single server under their own path prefix. Users are identified by
+// the `Authorization` header that is forwarded by the wallet API to IDAuth and the source registries.
+// Access token of the fixture person is its person code.
+package mock
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Upstream path prefixes.
+const (
+	PrefixIDAuth     = "/idauth"
+	PrefixFPRIS      = "/fpris"
+	PrefixRTU        = "/rtu"
+	PrefixMDL        = "/mdl"
+	PrefixEHIC       = "/ehic"
+	PrefixPDA1       = "/pda1"
+	PrefixIssuer     = "/issuer"
+	PrefixSimpleSign = "/simple-sign"
+)
+
+// Server serves fakes of all upstream services.
+type Server struct {
+	// Persons are fixture persons by access token
+	Persons map[string]*Person
+
+	url    string
+	ln     net.Listener
+	srv    *fasthttp.Server
+	mu     sync.Mutex
+	issuer issuerState
+
+	signSessions map[string]*signSession
+}
+
+// New creates upstream fakes server with fixture persons.
+func New() *Server {
+	s := &Server{
+		Persons: Persons(),
+		issuer:  newIssuerState(),
+
+		signSessions: make(map[string]*signSession),
+	}
+
+	s.srv = &fasthttp.Server{
+		Name:    "mock-upstreams",
+		Handler: s.handle,
+	}
+
+	return s
+}
+
+// Start listens on the address and serves requests in the background.
+func (s *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.ln = ln
+	s.url = "http://" + ln.Addr().String()
+
+	go func() {
+		_ = s.srv.Serve(ln)
+	}()
+
+	return nil
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.srv.Shutdown()
+}
+
+// URL returns base URL of the server.
+func (s *Server) URL() string {
+	return s.url
+}
+
+// Env returns environment variables that point the wallet API to the upstream fakes.
+func (s *Server) Env() map[string]string {
+	return map[string]string{
+		"IDAUTH_URL":             s.url + PrefixIDAuth,
+		"FPRIS_API_URL":          s.url + PrefixFPRIS,
+		"RTU_API_URL":            s.url + PrefixRTU,
+		"MDL_API_URL":            s.url + PrefixMDL,
+		"EHIC_API_URL":           s.url + PrefixEHIC,
+		"PDA1_API_URL":           s.url + PrefixPDA1,
+		"ISSUER_API_URL":         s.url + PrefixIssuer,
+		"SIMPLE_SIGN_SERVICE":    s.url + PrefixSimpleSign,
+		"SIMPLE_SIGN_PUBLIC_URL": s.url + PrefixSimpleSign,
+	}
+}
+
+func (s *Server) handle(ctx *fasthttp.RequestCtx) {
+	path := string(ctx.Path())
+
+	for prefix, h := range map[string]fasthttp.RequestHandler{
+		PrefixIDAuth:     s.idauth,
+		PrefixFPRIS:      s.fpris,
+		PrefixRTU:        s.rtu,
+		PrefixMDL:        s.mdl,
+		PrefixEHIC:       s.ehic,
+		PrefixPDA1:       s.pda1,
+		PrefixIssuer:     s.issuerAPI,
+		PrefixSimpleSign: s.simpleSign,
+	} {
+		if rest, ok := strings.CutPrefix(path, prefix); ok && (rest == "" || rest[0] == '/') {
+			if rest == "/healthz" {
+				ctx.SetStatusCode(fasthttp.StatusOK)
+
+				return
+			}
+
+			ctx.URI().SetPath(rest)
+			h(ctx)
+
+			return
+		}
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusNotFound)
+}
+
+// person returns fixture person by the access token from the `Authorization` header.
+func (s *Server) person(ctx *fasthttp.RequestCtx) (*Person, bool) {
+	auth := string(ctx.Request.Header.Peek(fasthttp.HeaderAuthorization))
+
+	if tok, ok := strings.CutPrefix(auth, "Bearer "); ok {
+		auth = tok
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.Persons[auth]
+
+	return p, ok
+}
+
+// writeJSON writes JSON response with the status code.
+func writeJSON(ctx *fasthttp.RequestCtx, status int, v any) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+
+		return
+	}
+
+	ctx.SetStatusCode(status)
+	ctx.SetContentType("application/json")
+	ctx.SetBody(buf)
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package mock
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
+)
+
+func get(t *testing.T, s *Server, path, token string) (int, []byte) {
+	t.Helper()
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(s.URL() + path)
+
+	if token != "" {
+		req.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+token)
+	}
+
+	qt.Assert(t, qt.IsNil(fasthttp.Do(req, resp)))
+
+	return resp.StatusCode(), append([]byte(nil), resp.Body()...)
+}
+
+func TestServer(t *testing.T) {
+	s := New()
+	qt.Assert(t, qt.IsNil(s.Start("127.0.0.1:0")))
+
+	defer s.Close()
+
+	status, body := get(t, s, PrefixFPRIS+"/1.0/pid", PersonCodeFull)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK))
+
+	person := struct {
+		Code string `json:"personal_administrative_number"`
+	}{}
+	qt.Assert(t, qt.IsNil(json.Unmarshal(body, &person)))
+	qt.Check(t, qt.Equals(person.Code, PersonCodeFull))
+
+	status, _ = get(t, s, PrefixMDL+"/1.0/mdl", PersonCodeDiplomas)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusNotFound))
+
+	status, _ = get(t, s, PrefixRTU+"/1.0/diploma", PersonCodeDeceased)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusGone))
+
+	status, _ = get(t, s, PrefixRTU+"/1.0/diploma", "")
+	qt.Check(t, qt.Equals(status, fasthttp.StatusUnauthorized))
+
+	status, body = get(t, s, PrefixIDAuth+"/api/1.0/session", PersonCodeFull)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK))
+
+	session := struct {
+		Active bool   `json:"active"`
+		Code   string `json:"code"`
+	}{}
+	qt.Assert(t, qt.IsNil(json.Unmarshal(body, &session)))
+	qt.Check(t, qt.IsTrue(session.Active))
+	qt.Check(t, qt.Equals(session.Code, PersonCodeFull))
+
+	status, _ = get(t, s, PrefixIssuer+"/healthz", "")
+	qt.Check(t, qt.Equals(status, fasthttp.StatusOK))
+}
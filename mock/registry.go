@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package mock
+
+import (
+	"github.com/valyala/fasthttp"
+)
+
+// registry serves source registry data of the person selected by the access token.
+func (s *Server) registry(ctx *fasthttp.RequestCtx, path string, data func(p *Person) any) {
+	if string(ctx.Path()) != path || !ctx.IsGet() {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+
+		return
+	}
+
+	p, ok := s.person(ctx)
+	if !ok {
+		ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+
+		return
+	}
+
+	if p.Status != 0 {
+		ctx.SetStatusCode(p.Status)
+
+		return
+	}
+
+	s.mu.Lock()
+	v := data(p)
+	s.mu.Unlock()
+
+	if v == nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, v)
+}
+
+func (s *Server) fpris(ctx *fasthttp.RequestCtx) {
+	s.registry(ctx, "/1.0/pid", func(p *Person) any {
+		if p.PID == nil {
+			return nil
+		}
+
+		return p.PID
+	})
+}
+
+func (s *Server) rtu(ctx *fasthttp.RequestCtx) {
+	s.registry(ctx, "/1.0/diploma", func(p *Person) any {
+		if len(p.Diplomas) == 0 {
+			return nil
+		}
+
+		return p.Diplomas
+	})
+}
+
+func (s *Server) mdl(ctx *fasthttp.RequestCtx) {
+	s.registry(ctx, "/1.0/mdl", func(p *Person) any {
+		if p.DrivingLicence == nil {
+			return nil
+		}
+
+		return p.DrivingLicence
+	})
+}
+
+func (s *Server) ehic(ctx *fasthttp.RequestCtx) {
+	s.registry(ctx, "/1.0/ehic", func(p *Person) any {
+		if p.EHIC == nil {
+			return nil
+		}
+
+		return p.EHIC
+	})
+}
+
+func (s *Server) pda1(ctx *fasthttp.RequestCtx) {
+	s.registry(ctx, "/1.0/pda1", func(p *Person) any {
+		if p.PDA1 == nil {
+			return nil
+		}
+
+		return p.PDA1
+	})
+}
@@ -0,0 +1,237 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package mock
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/routes/request"
+	"git.zzdats.lv/edim/api-wallet/routes/response"
+
+	"github.com/valyala/fasthttp"
+)
+
+// signSession is the SimpleSign session. Documents are signed immediately after the session is prepared.
+type signSession struct {
+	fileName    string
+	contentType string
+	data        []byte
+}
+
+// simpleSign serves SimpleSign (eParaksts) endpoints used by the wallet API.
+func (s *Server) simpleSign(ctx *fasthttp.RequestCtx) {
+	switch path := string(ctx.Path()); {
+	case (path == "/v2.0/mobile/preparelarge" || path == "/v2.0/mobile/eseal") && ctx.IsPost():
+		s.signPrepare(ctx)
+	case path == "/v2.0/file/get" && ctx.IsGet():
+		s.signGetFile(ctx)
+	case path == "/v2.0/file/close" && ctx.IsPost():
+		s.signClose(ctx)
+	case path == "/v2.0/file/validate" && ctx.IsPost():
+		s.signValidate(ctx)
+	case path == "/v2/mobile/identities" && ctx.IsGet():
+		s.identitiesRedirect(ctx)
+	case strings.HasPrefix(path, "/v2/mobile/identities/") && ctx.IsGet():
+		s.identities(ctx)
+	default:
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+	}
+}
+
+// multipartRequest returns JSON part and the first file of the multipart request.
+func multipartRequest(ctx *fasthttp.RequestCtx, v any) (string, []byte, bool) {
+	form, err := ctx.MultipartForm()
+	if err != nil || len(form.Value["json"]) == 0 {
+		return "", nil, false
+	}
+
+	if err := json.Unmarshal([]byte(form.Value["json"][0]), v); err != nil {
+		return "", nil, false
+	}
+
+	for name, files := range form.File {
+		if len(files) == 0 {
+			continue
+		}
+
+		f, err := files[0].Open()
+		if err != nil {
+			return "", nil, false
+		}
+
+		data, err := io.ReadAll(f)
+		_ = f.Close()
+
+		if err != nil {
+			return "", nil, false
+		}
+
+		return name, data, true
+	}
+
+	return "", nil, false
+}
+
+func (s *Server) signPrepare(ctx *fasthttp.RequestCtx) {
+	req := &request.SimpleSignPrepareRequest{}
+
+	fileName, data, ok := multipartRequest(ctx, req)
+	if !ok || len(req.Requests) == 0 {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+
+		return
+	}
+
+	contentType := "application/pdf"
+	if req.Requests[0].Type == "hash" {
+		contentType = "application/vnd.etsi.asic-e+zip"
+		fileName = strings.TrimSuffix(fileName, ".pdf") + ".edoc"
+	}
+
+	id := randomToken()
+
+	s.mu.Lock()
+	s.signSessions[id] = &signSession{
+		fileName:    fileName,
+		contentType: contentType,
+		data:        data,
+	}
+	s.mu.Unlock()
+
+	writeJSON(ctx, fasthttp.StatusOK, &response.EparakstsSignResponse{
+		RedirectURL: req.RedirectURL,
+		Sessions: []response.SimpleSignSession{
+			{
+				RequestID: req.Requests[0].RequestID,
+				SessionID: id,
+				Documents: []response.SimpleSignDocument{
+					{
+						ID:       randomToken(),
+						FileName: fileName,
+					},
+				},
+			},
+		},
+	})
+}
+
+func (s *Server) signGetFile(ctx *fasthttp.RequestCtx) {
+	s.mu.Lock()
+	sess, ok := s.signSessions[string(ctx.QueryArgs().Peek("sessionId"))]
+	s.mu.Unlock()
+
+	if !ok {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+
+		return
+	}
+
+	ctx.SetContentType(sess.contentType)
+	ctx.Response.Header.Set(fasthttp.HeaderContentDisposition, `attachment; filename="`+sess.fileName+`"`)
+	ctx.SetBody(sess.data)
+}
+
+func (s *Server) signClose(ctx *fasthttp.RequestCtx) {
+	ids := make([]string, 0, 1)
+
+	if err := json.Unmarshal(ctx.PostBody(), &ids); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+
+		return
+	}
+
+	s.mu.Lock()
+	for _, id := range ids {
+		delete(s.signSessions, id)
+	}
+	s.mu.Unlock()
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// signValidate returns successful validation report with a single signature of the full fixture person.
+func (s *Server) signValidate(ctx *fasthttp.RequestCtx) {
+	req := &request.ValidateRequest{}
+
+	fileName, _, ok := multipartRequest(ctx, req)
+	if !ok {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+
+		return
+	}
+
+	now := time.Now().UTC()
+	signed := now.Add(-time.Hour).Format(time.RFC3339)
+
+	writeJSON(ctx, fasthttp.StatusOK, map[string]any{
+		"documents": []map[string]any{
+			{
+				"fileName":             fileName,
+				"validationTime":       now.Format(time.RFC3339),
+				"signaturesCount":      1,
+				"validSignaturesCount": 1,
+				"indication":           "TOTAL_PASSED",
+				"signatures": []map[string]any{
+					{
+						"id":              "S-1",
+						"signedBy":        "JĀNIS PARAUGS",
+						"signatureFormat": "PAdES-BASELINE-LT",
+						"signingTime":     signed,
+						"indication":      "TOTAL_PASSED",
+						"certificate": map[string]any{
+							"subject":      "CN=JĀNIS PARAUGS,SERIALNUMBER=PNOLV-" + PersonCodeFull[:6] + "-" + PersonCodeFull[6:] + ",C=LV",
+							"issuer":       "CN=Mock eParaksts CA,C=LV",
+							"serialNumber": "PNOLV-" + PersonCodeFull[:6] + "-" + PersonCodeFull[6:],
+							"status":       "GOOD",
+						},
+						"timestamps": []map[string]any{
+							{
+								"productionTime": signed,
+								"indication":     "PASSED",
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// identitiesRedirect simulates the user choosing identity and redirects back with the identity request ID.
+func (s *Server) identitiesRedirect(ctx *fasthttp.RequestCtx) {
+	redirectURL := string(ctx.QueryArgs().Peek("redirecturl"))
+	if redirectURL == "" {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+
+		return
+	}
+
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+
+		return
+	}
+
+	q := u.Query()
+	q.Set("id", randomToken())
+	u.RawQuery = q.Encode()
+
+	ctx.Redirect(u.String(), fasthttp.StatusFound)
+}
+
+// identities returns identities of the full fixture person for any identity request ID.
+func (s *Server) identities(ctx *fasthttp.RequestCtx) {
+	writeJSON(ctx, fasthttp.StatusOK, []map[string]any{
+		{
+			"givenName":  "Jānis",
+			"familyName": "Paraugs",
+			"personCode": PersonCodeFull,
+			"type":       "eparaksts-mobile",
+		},
+	})
+}
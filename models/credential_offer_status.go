@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package models
+
+import "time"
+
+// CredentialOfferStatus represents the lifecycle state of the credential offer.
+type CredentialOfferStatus struct {
+	// OfferID is the credential offer identifier
+	OfferID string `json:"offerId"`
+	// RequestType is the credential type the offer was generated for
+	RequestType string `json:"requestType"`
+	// State is the offer state. Possible values: `created`, `token_issued`, `credential_issued`, `expired`, `cancelled`
+	State string `json:"state"`
+	// CreatedAt is the offer creation time
+	CreatedAt time.Time `json:"createdAt"`
+	// ExpiresAt is the time after which offer can no longer be redeemed
+	ExpiresAt time.Time `json:"expiresAt"`
+}
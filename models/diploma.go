@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package models
+
+import "time"
+
+// Diploma represents diploma that can be issued as RTU credential.
+type Diploma struct {
+	// ID is the diploma identifier that can be passed to the credential offer request
+	ID string `json:"id"`
+	// Type is the type of the diploma
+	Type string `json:"type"`
+	// Title is the title of the diploma
+	Title string `json:"title"`
+	// AwardingBody is the legal name of the awarding body
+	AwardingBody string `json:"awardingBody"`
+	// AwardingDate is the awarding date of the diploma
+	AwardingDate time.Time `json:"awardingDate"`
+}
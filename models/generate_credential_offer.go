@@ -4,6 +4,7 @@
 package models
 
 type GenerateCredentialOffer struct {
+	OfferID string `json:"offerId,omitempty"`
 	TXCode  *int   `json:"tx_code,omitempty"`
 	URLData string `json:"urlData"`
 }
@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package models
+
+import "git.zzdats.lv/edim/api-wallet/credential/validation"
+
+// SourceError is returned when credential can not be issued from the source registry data.
+type SourceError struct {
+	// Code is the stable error code that wallet app can use to show localized message
+	Code string `json:"code"`
+	// Message is the error description
+	Message string `json:"message"`
+	// Source is the credential request type
+	Source string `json:"source"`
+	// Reason is the reason why the person is inactive: `deceased` or `blocked`
+	Reason string `json:"reason,omitempty"`
+	// Errors are the invalid source registry data fields
+	Errors []validation.FieldError `json:"errors,omitempty"`
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package openapi
+
+import (
+	"encoding/json"
+)
+
+// WithPathParamEnum returns OpenAPI definition with allowed values set for all path parameters with the name.
+// Original definition is returned if it can not be parsed.
+func WithPathParamEnum(definition []byte, name string, values []string) []byte {
+	def := make(map[string]any)
+	if err := json.Unmarshal(definition, &def); err != nil {
+		return definition
+	}
+
+	paths, _ := def["paths"].(map[string]any)
+	for _, path := range paths {
+		operations, _ := path.(map[string]any)
+		for _, operation := range operations {
+			op, _ := operation.(map[string]any)
+
+			params, _ := op["parameters"].([]any)
+			for _, param := range params {
+				p, _ := param.(map[string]any)
+				if p["name"] != name || p["in"] != "path" {
+					continue
+				}
+
+				schema, ok := p["schema"].(map[string]any)
+				if !ok {
+					schema = make(map[string]any)
+					p["schema"] = schema
+				}
+
+				schema["enum"] = values
+			}
+		}
+	}
+
+	buf, err := json.Marshal(def)
+	if err != nil {
+		return definition
+	}
+
+	return buf
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+//nolint:tagliatelle
+package openid4vci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"azugo.io/core/cache"
+)
+
+// accessTokenBindingTTL is used for access token binding if the token response does not include expiration.
+const accessTokenBindingTTL = time.Hour
+
+// accessTokenBinding is the state bound to the access token issued by the upstream issuer.
+type accessTokenBinding struct {
+	// InstanceID is the wallet instance that has authenticated with the client attestation when requesting the token
+	InstanceID string `json:"instanceId,omitempty"`
+	// CredentialIdentifiers maps credential identifiers to the credential configuration IDs
+	CredentialIdentifiers map[string]string `json:"credentialIdentifiers,omitempty"`
+}
+
+type tokenResponse struct {
+	ExpiresIn            int `json:"expires_in"`
+	AuthorizationDetails []struct {
+		Type                      string   `json:"type"`
+		CredentialConfigurationID string   `json:"credential_configuration_id"`
+		CredentialIdentifiers     []string `json:"credential_identifiers"`
+	} `json:"authorization_details"`
+}
+
+// BindAccessToken stores wallet instance and credential identifiers returned by the upstream issuer in the
+// token response with the hash of the access token. Instance ID is empty if the wallet has not authenticated
+// with the client attestation.
+func (s *Service) BindAccessToken(ctx context.Context, accessToken, instanceID string, body []byte) error {
+	res := tokenResponse{}
+
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("error parsing token response: %w", err)
+	}
+
+	ttl := accessTokenBindingTTL
+	if res.ExpiresIn > 0 {
+		ttl = time.Duration(res.ExpiresIn) * time.Second
+	}
+
+	binding := accessTokenBinding{
+		InstanceID:            instanceID,
+		CredentialIdentifiers: make(map[string]string),
+	}
+
+	for _, d := range res.AuthorizationDetails {
+		if d.Type != "openid_credential" || d.CredentialConfigurationID == "" {
+			continue
+		}
+
+		for _, id := range d.CredentialIdentifiers {
+			binding.CredentialIdentifiers[id] = d.CredentialConfigurationID
+		}
+	}
+
+	if err := s.bindingCache.Set(ctx, AccessTokenHash(accessToken), binding, cache.TTL[accessTokenBinding](ttl)); err != nil {
+		return fmt.Errorf("failed to store access token binding: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) accessTokenBinding(ctx context.Context, accessToken string) (accessTokenBinding, error) {
+	binding, err := s.bindingCache.Get(ctx, AccessTokenHash(accessToken))
+	if err != nil {
+		return accessTokenBinding{}, fmt.Errorf("failed to get access token binding: %w", err)
+	}
+
+	return binding, nil
+}
+
+// WalletInstanceID returns wallet instance ID the access token was issued to or empty string if the wallet
+// has not authenticated with the client attestation.
+func (s *Service) WalletInstanceID(ctx context.Context, accessToken string) (string, error) {
+	binding, err := s.accessTokenBinding(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	return binding.InstanceID, nil
+}
+
+// credentialConfigurationID returns credential configuration ID of the credential request. Credential identifier
+// is resolved to configuration ID using identifiers issued together with the access token.
+func (s *Service) credentialConfigurationID(ctx context.Context, req *CredentialRequest, accessToken string) (string, error) {
+	if req.CredentialIdentifier == "" {
+		return req.CredentialConfigurationID, nil
+	}
+
+	binding, err := s.accessTokenBinding(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	return binding.CredentialIdentifiers[req.CredentialIdentifier], nil
+}
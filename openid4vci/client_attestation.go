@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package openid4vci
+
+import (
+	"errors"
+	"fmt"
+
+	"azugo.io/azugo"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// VerifyClientAttestation validates wallet attestation and its proof of possession signed by the key
+// the attestation was issued for. Returns wallet instance ID of the attestation.
+func (s *Service) VerifyClientAttestation(ctx *azugo.Context, attestation, pop string) (string, error) {
+	if pop == "" {
+		return "", azugo.BadRequestError{Description: "invalid_client", Err: errors.New("missing client attestation proof of possession")}
+	}
+
+	instanceID, _, err := s.VerifyAttestation(ctx, attestation)
+	if err != nil {
+		return "", err
+	}
+
+	// Attestation signature has already been verified
+	token, _, err := jwt.NewParser().ParseUnverified(attestation, jwt.MapClaims{})
+	if err != nil {
+		return "", azugo.BadRequestError{Description: "invalid_client", Err: err}
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+
+	cnf, ok := claims["cnf"].(map[string]any)
+	if !ok {
+		return "", azugo.BadRequestError{Description: "invalid_client", Err: errors.New("client attestation is not bound to a key")}
+	}
+
+	key, err := s.publicKeyFromJWK(cnf)
+	if err != nil {
+		return "", azugo.BadRequestError{Description: "invalid_client", Err: err}
+	}
+
+	if _, err := jwt.Parse(pop, func(t *jwt.Token) (any, error) {
+		if typ, ok := t.Header["typ"].(string); !ok || typ != "oauth-client-attestation-pop+jwt" {
+			return nil, errors.New("invalid proof of possession type")
+		}
+
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}),
+		jwt.WithAudience(s.walletPublicURL),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	); err != nil {
+		return "", azugo.BadRequestError{Description: "invalid_client", Err: fmt.Errorf("invalid client attestation proof of possession: %w", err)}
+	}
+
+	return instanceID, nil
+}
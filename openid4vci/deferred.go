@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package openid4vci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"azugo.io/core/http"
+	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
+)
+
+// DeferredTransaction is a credential issuance transaction that is still pending at the upstream issuer.
+type DeferredTransaction struct {
+	TransactionID   string `json:"transactionId"`
+	AccessTokenHash string `json:"accessTokenHash"`
+	Status          string `json:"status"`
+	// CredentialConfigurationID is the credential configuration requested when the issuance was deferred
+	CredentialConfigurationID string `json:"credentialConfigurationId,omitempty"`
+}
+
+// AccessTokenHash returns hash of the access token that can be safely persisted.
+func AccessTokenHash(accessToken string) string {
+	h := sha256.Sum256([]byte(accessToken))
+
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+// CreateDeferredTransaction stores pending transaction of the requested credential configuration bound to
+// the access token it was issued for.
+func (s *Service) CreateDeferredTransaction(ctx context.Context, transactionID, accessToken, credentialConfigurationID string) error {
+	if accessToken == "" {
+		return errors.New("deferred transaction must be bound to access token")
+	}
+
+	if err := s.store.Exec(ctx, "wallet.create_deferred_transaction", &DeferredTransaction{
+		TransactionID:             transactionID,
+		AccessTokenHash:           AccessTokenHash(accessToken),
+		Status:                    "pending",
+		CredentialConfigurationID: credentialConfigurationID,
+	}, nil); err != nil {
+		return fmt.Errorf("failed to create deferred transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeferredTransaction returns pending transaction if it was issued for the same access token.
+func (s *Service) DeferredTransaction(ctx context.Context, transactionID, accessToken string) (*DeferredTransaction, error) {
+	resp := &DeferredTransaction{}
+
+	if err := s.store.Exec(ctx, "wallet.get_deferred_transaction", &struct {
+		TransactionID string `json:"transactionId"`
+	}{
+		TransactionID: transactionID,
+	}, resp); err != nil {
+		var eerr jsondb.ExecError
+		if errors.As(err, &eerr) && eerr.Code == "err:deferred_transaction:not_found" {
+			return nil, http.NotFoundError{Resource: "transaction"}
+		}
+
+		return nil, fmt.Errorf("failed to get deferred transaction: %w", err)
+	}
+
+	if resp.AccessTokenHash != AccessTokenHash(accessToken) || resp.Status != "pending" {
+		return nil, http.NotFoundError{Resource: "transaction"}
+	}
+
+	return resp, nil
+}
+
+// CompleteDeferredTransaction marks transaction as issued so it can not be redeemed again.
+func (s *Service) CompleteDeferredTransaction(ctx context.Context, transactionID string) error {
+	if err := s.store.Exec(ctx, "wallet.complete_deferred_transaction", &struct {
+		TransactionID string `json:"transactionId"`
+	}{
+		TransactionID: transactionID,
+	}, nil); err != nil {
+		return fmt.Errorf("failed to complete deferred transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package openid4vci
+
+import (
+	"context"
+	"testing"
+
+	"git.zzdats.lv/edim/api-wallet/mock/jsondbtest"
+
+	"azugo.io/core/http"
+	"github.com/go-quicktest/qt"
+)
+
+func TestDeferredTransaction(t *testing.T) {
+	ctx := context.Background()
+	store := jsondbtest.New()
+	s := &Service{store: store}
+
+	err := s.CreateDeferredTransaction(ctx, "tx-1", "", "eu.europa.ec.eudi.pid_mdoc")
+	qt.Check(t, qt.ErrorMatches(err, "deferred transaction must be bound to access token"))
+
+	_, ok := store.DeferredTransaction("tx-1")
+	qt.Check(t, qt.IsFalse(ok))
+
+	err = s.CreateDeferredTransaction(ctx, "tx-1", "access-token", "eu.europa.ec.eudi.pid_mdoc")
+	qt.Assert(t, qt.IsNil(err))
+
+	stored, ok := store.DeferredTransaction("tx-1")
+	qt.Assert(t, qt.IsTrue(ok))
+	qt.Check(t, qt.Equals(stored.AccessTokenHash, AccessTokenHash("access-token")))
+	qt.Check(t, qt.Equals(stored.Status, "pending"))
+	qt.Check(t, qt.Equals(stored.CredentialConfigurationID, "eu.europa.ec.eudi.pid_mdoc"))
+
+	tx, err := s.DeferredTransaction(ctx, "tx-1", "access-token")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(tx.TransactionID, "tx-1"))
+	qt.Check(t, qt.Equals(tx.CredentialConfigurationID, "eu.europa.ec.eudi.pid_mdoc"))
+
+	_, err = s.DeferredTransaction(ctx, "unknown", "access-token")
+	qt.Check(t, qt.ErrorIs(err, error(http.NotFoundError{Resource: "transaction"})))
+
+	_, err = s.DeferredTransaction(ctx, "tx-1", "other-token")
+	qt.Check(t, qt.ErrorIs(err, error(http.NotFoundError{Resource: "transaction"})))
+
+	err = s.CompleteDeferredTransaction(ctx, "tx-1")
+	qt.Assert(t, qt.IsNil(err))
+
+	_, err = s.DeferredTransaction(ctx, "tx-1", "access-token")
+	qt.Check(t, qt.ErrorIs(err, error(http.NotFoundError{Resource: "transaction"})))
+
+	err = s.CompleteDeferredTransaction(ctx, "unknown")
+	qt.Check(t, qt.ErrorMatches(err, "failed to complete deferred transaction: .*"))
+}
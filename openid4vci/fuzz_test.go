@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package openid4vci
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/attestation/attestationtest"
+	"git.zzdats.lv/edim/api-wallet/mock/jsondbtest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const fuzzWalletURL = "http://wallet:8080"
+
+// ecJWK returns JWK of the elliptic curve public key.
+func ecJWK(key *ecdsa.PublicKey, crv string) map[string]any {
+	size := (key.Curve.Params().BitSize + 7) / 8
+
+	return map[string]any{
+		"kty": "EC",
+		"crv": crv,
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+	}
+}
+
+func mustJSON(f *testing.F, v any) []byte {
+	f.Helper()
+
+	buf, err := json.Marshal(v)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	return buf
+}
+
+func FuzzPublicKeyFromJWK(f *testing.F) {
+	for _, c := range []struct {
+		curve elliptic.Curve
+		crv   string
+	}{
+		{elliptic.P256(), "P-256"},
+		{elliptic.P384(), "P-384"},
+		{elliptic.P521(), "P-521"},
+	} {
+		key, err := ecdsa.GenerateKey(c.curve, rand.Reader)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(mustJSON(f, ecJWK(&key.PublicKey, c.crv)))
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(mustJSON(f, map[string]any{
+		"kty": "RSA",
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+	}))
+
+	// Key from the assertion request example
+	f.Add([]byte(`{"crv":"P-256","kty":"EC","x":"4HNptI-xr2pjyRJKGMnz4WmdnQD_uJSq4R95Nj98b44","y":"LIZnSB39vFJhYgS3k7jXE4r3-CoGFQwZtPBIRqpNlrg"}`))
+	f.Add([]byte(`{"kty":"oct","k":"c2VjcmV0"}`))
+
+	s := &Service{}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		jwk := make(map[string]any)
+		if err := json.Unmarshal(data, &jwk); err != nil {
+			return
+		}
+
+		key, err := s.publicKeyFromJWK(map[string]any{"jwk": jwk})
+		if err != nil {
+			return
+		}
+
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			if _, err := k.ECDH(); err != nil {
+				t.Fatalf("invalid elliptic curve public key returned: %v", err)
+			}
+		case *rsa.PublicKey:
+			if k.E < 2 || k.N.Sign() <= 0 {
+				t.Fatalf("invalid RSA public key returned: e=%d n=%s", k.E, k.N)
+			}
+		default:
+			t.Fatalf("unexpected public key type: %T", key)
+		}
+	})
+}
+
+// assertionClaims returns valid wallet instance attestation request claims for the key.
+func assertionClaims(key *ecdsa.PrivateKey, tag string) jwt.MapClaims {
+	now := time.Now()
+
+	return jwt.MapClaims{
+		"sub":              fuzzWalletURL,
+		"iss":              fuzzWalletURL + "/instance/" + tag,
+		"type":             "WalletInstanceAttestationRequest",
+		"hardware_key_tag": tag,
+		"cnf": map[string]any{
+			"jwk": ecJWK(&key.PublicKey, "P-256"),
+		},
+		"iat": now.Unix(),
+		"exp": now.Add(24 * time.Hour).Unix(),
+	}
+}
+
+func signAssertion(f *testing.F, key *ecdsa.PrivateKey, typ, kid string, claims jwt.MapClaims) string {
+	f.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = typ
+	token.Header["kid"] = kid
+
+	s, err := token.SignedString(key)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	return s
+}
+
+func FuzzVerifyAssertion(f *testing.F) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	tag, err := attestationtest.HardwareKeyTag(&key.PublicKey)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	store := jsondbtest.New()
+	store.AddInstance(jsondbtest.Instance{
+		HardwareKeyTag: tag,
+		DeviceType:     "android",
+		PublicKey:      string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	})
+
+	s := &Service{
+		store:             store,
+		walletPublicURL:   fuzzWalletURL,
+		walletInstanceURL: fuzzWalletURL + "/instance",
+	}
+
+	f.Add(signAssertion(f, key, "var+jwt", tag, assertionClaims(key, tag)))
+	f.Add(signAssertion(f, key, "JWT", tag, assertionClaims(key, tag)))
+	f.Add(signAssertion(f, key, "var+jwt", "unknown", assertionClaims(key, tag)))
+
+	claims := assertionClaims(key, tag)
+	claims["cnf"] = map[string]any{
+		"jwk": map[string]any{"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"},
+	}
+	f.Add(signAssertion(f, key, "var+jwt", tag, claims))
+
+	claims = assertionClaims(key, tag)
+	claims["hardware_key_tag"] = "not base64"
+	f.Add(signAssertion(f, key, "var+jwt", tag, claims))
+
+	claims = assertionClaims(key, tag)
+	delete(claims, "exp")
+	f.Add(signAssertion(f, key, "var+jwt", tag, claims))
+
+	f.Fuzz(func(t *testing.T, assertion string) {
+		token, instanceID, err := s.verifyAssertion(context.Background(), assertion)
+		if err != nil {
+			return
+		}
+
+		if token == nil || !token.Valid {
+			t.Fatal("invalid token returned without error")
+		}
+
+		if instanceID == "" {
+			t.Fatal("empty instance ID returned without error")
+		}
+	})
+}
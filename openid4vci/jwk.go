@@ -8,6 +8,7 @@ import (
 	"crypto/rsa"
 	"encoding/base64"
 	"errors"
+	"math"
 	"math/big"
 )
 
@@ -57,28 +58,31 @@ func (s *Service) publicKeyFromJWK(cnf map[string]any) (any, error) {
 
 		y = new(big.Int).SetBytes(ybuf)
 
+		var curve elliptic.Curve
+
 		switch crv {
 		case "P-256":
-			return &ecdsa.PublicKey{
-				Curve: elliptic.P256(),
-				X:     x,
-				Y:     y,
-			}, nil
+			curve = elliptic.P256()
 		case "P-384":
-			return &ecdsa.PublicKey{
-				Curve: elliptic.P384(),
-				X:     x,
-				Y:     y,
-			}, nil
+			curve = elliptic.P384()
 		case "P-521":
-			return &ecdsa.PublicKey{
-				Curve: elliptic.P521(),
-				X:     x,
-				Y:     y,
-			}, nil
+			curve = elliptic.P521()
 		default:
 			return nil, errors.New("unsupported curve")
 		}
+
+		pk := &ecdsa.PublicKey{
+			Curve: curve,
+			X:     x,
+			Y:     y,
+		}
+
+		// Reject points that are not on the curve
+		if _, err := pk.ECDH(); err != nil {
+			return nil, errors.New("invalid public key")
+		}
+
+		return pk, nil
 	case "RSA":
 		var (
 			e int
@@ -96,7 +100,12 @@ func (s *Service) publicKeyFromJWK(cnf map[string]any) (any, error) {
 			return nil, err
 		}
 
-		e = int(new(big.Int).SetBytes(ebuf).Uint64()) //nolint:gosec
+		ev := new(big.Int).SetBytes(ebuf)
+		if ev.Cmp(big.NewInt(1)) <= 0 || ev.Cmp(big.NewInt(math.MaxInt32)) > 0 {
+			return nil, errors.New("invalid exponent")
+		}
+
+		e = int(ev.Int64())
 
 		var nb string
 
@@ -110,8 +119,11 @@ func (s *Service) publicKeyFromJWK(cnf map[string]any) (any, error) {
 		}
 
 		n = new(big.Int).SetBytes(nbuf)
+		if n.Sign() == 0 {
+			return nil, errors.New("invalid modulus")
+		}
 
-		return rsa.PublicKey{
+		return &rsa.PublicKey{
 			E: e,
 			N: n,
 		}, nil
@@ -17,6 +17,8 @@ import (
 type Metadata struct {
 	CredentialIssuer                  string                              `json:"credential_issuer"`
 	NonceEndpoint                     string                              `json:"nonce_endpoint,omitempty"`
+	DeferredCredentialEndpoint        string                              `json:"deferred_credential_endpoint,omitempty"`
+	NotificationEndpoint              string                              `json:"notification_endpoint,omitempty"`
 	CredentialConfigurationsSupported map[string]*CredentialConfiguration `json:"credential_configurations_supported"`
 }
 
@@ -35,6 +37,8 @@ func (s *Service) Metadata(_ *azugo.Context) (*Metadata, error) {
 	return &Metadata{
 		CredentialIssuer:                  s.walletPublicURL,
 		NonceEndpoint:                     s.walletPublicURL + "/nonce",
+		DeferredCredentialEndpoint:        s.walletPublicURL + "/deferred_credential",
+		NotificationEndpoint:              s.walletPublicURL + "/notification",
 		CredentialConfigurationsSupported: map[string]*CredentialConfiguration{},
 	}, nil
 }
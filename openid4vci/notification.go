@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package openid4vci
+
+import (
+	"errors"
+	"fmt"
+
+	"azugo.io/azugo"
+	"azugo.io/core/http"
+	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
+)
+
+// NotificationEvent is an event type the wallet reports about the issued credential.
+type NotificationEvent string
+
+const (
+	NotificationEventCredentialAccepted NotificationEvent = "credential_accepted"
+	NotificationEventCredentialFailure  NotificationEvent = "credential_failure"
+	NotificationEventCredentialDeleted  NotificationEvent = "credential_deleted"
+)
+
+// Valid checks if the event is one of the events defined by the specification.
+func (e NotificationEvent) Valid() bool {
+	switch e {
+	case NotificationEventCredentialAccepted, NotificationEventCredentialFailure, NotificationEventCredentialDeleted:
+		return true
+	default:
+		return false
+	}
+}
+
+// Notification links issued credential to the wallet instance it was issued to.
+type Notification struct {
+	NotificationID            string `json:"notificationId"`
+	AccessTokenHash           string `json:"accessTokenHash"`
+	InstanceID                string `json:"instanceId,omitempty"`
+	CredentialConfigurationID string `json:"credentialConfigurationId,omitempty"`
+}
+
+// NotificationRequest is a notification request sent by the wallet.
+type NotificationRequest struct {
+	NotificationID   string            `json:"notification_id"`
+	Event            NotificationEvent `json:"event"`
+	EventDescription string            `json:"event_description,omitempty"`
+}
+
+// CreateNotification stores notification ID that was returned to the wallet with the issued credential.
+func (s *Service) CreateNotification(ctx *azugo.Context, accessToken string, notification *Notification) error {
+	notification.AccessTokenHash = AccessTokenHash(accessToken)
+
+	if err := s.store.Exec(ctx, "wallet.create_credential_notification", notification, nil); err != nil {
+		return fmt.Errorf("failed to create credential notification: %w", err)
+	}
+
+	return nil
+}
+
+// Notify records credential event reported by the wallet instance the credential was issued to.
+func (s *Service) Notify(ctx *azugo.Context, accessToken, instanceID string, req *NotificationRequest) error {
+	if err := s.store.Exec(ctx, "wallet.create_notification_event", &struct {
+		NotificationID   string            `json:"notificationId"`
+		AccessTokenHash  string            `json:"accessTokenHash"`
+		InstanceID       string            `json:"instanceId"`
+		Event            NotificationEvent `json:"event"`
+		EventDescription string            `json:"eventDescription,omitempty"`
+	}{
+		NotificationID:   req.NotificationID,
+		AccessTokenHash:  AccessTokenHash(accessToken),
+		InstanceID:       instanceID,
+		Event:            req.Event,
+		EventDescription: req.EventDescription,
+	}, nil); err != nil {
+		var eerr jsondb.ExecError
+		if errors.As(err, &eerr) && eerr.Code == "err:notification:not_found" {
+			return http.NotFoundError{Resource: "notification"}
+		}
+
+		return fmt.Errorf("failed to create notification event: %w", err)
+	}
+
+	return nil
+}
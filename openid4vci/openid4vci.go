@@ -26,6 +26,8 @@ type Service struct {
 	nonceLock  sync.Mutex
 	nonceKey   paseto.V4SymmetricKey
 
+	bindingCache cache.Instance[accessTokenBinding]
+
 	walletPublicURL   string
 	walletInstanceURL string
 }
@@ -41,7 +43,12 @@ func New(app *azugo.App, store jsondb.Store, config *issuer.Configuration, publi
 		return nil, err
 	}
 
-	cache, err := cache.Create[bool](app.Cache(), "nonce-reuse", cache.DefaultTTL(config.NonceTTL))
+	nonceCache, err := cache.Create[bool](app.Cache(), "nonce-reuse", cache.DefaultTTL(config.NonceTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	bindingCache, err := cache.Create[accessTokenBinding](app.Cache(), "access-token-binding", cache.DefaultTTL(accessTokenBindingTTL))
 	if err != nil {
 		return nil, err
 	}
@@ -56,9 +63,11 @@ func New(app *azugo.App, store jsondb.Store, config *issuer.Configuration, publi
 		config: config,
 		store:  store,
 
-		nonceCache: cache,
+		nonceCache: nonceCache,
 		nonceKey:   key,
 
+		bindingCache: bindingCache,
+
 		walletPublicURL:   strings.TrimSuffix(publicBaseURL, "/"),
 		walletInstanceURL: walletInstanceURL,
 	}, nil
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package openid4vci
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+
+	"azugo.io/azugo"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const proofTypeJWT = "jwt"
+
+// CredentialRequest is a credential request sent by the wallet.
+type CredentialRequest struct {
+	CredentialConfigurationID string              `json:"credential_configuration_id,omitempty"`
+	CredentialIdentifier      string              `json:"credential_identifier,omitempty"`
+	Format                    string              `json:"format,omitempty"`
+	Proof                     map[string]any      `json:"proof,omitempty"`
+	Proofs                    map[string][]string `json:"proofs,omitempty"`
+}
+
+// VerifyProofs validates all key proofs of the credential request.
+//
+// Every proof must be signed by a different key and the number of proofs must not exceed the
+// batch size limit of the requested credential configuration.
+func (s *Service) VerifyProofs(ctx *azugo.Context, req *CredentialRequest, accessToken string) error {
+	proofs, err := requestProofs(req)
+	if err != nil || len(proofs) == 0 {
+		return err
+	}
+
+	configurationID, err := s.credentialConfigurationID(ctx, req, accessToken)
+	if err != nil {
+		return err
+	}
+
+	if req.CredentialIdentifier != "" && configurationID == "" {
+		return azugo.BadRequestError{Description: "unknown_credential_identifier", Err: errors.New("credential identifier not issued for the access token")}
+	}
+
+	nonces, err := s.verifyProofJWTs(proofs, s.config.BatchSizeLimit(configurationID))
+	if err != nil {
+		return err
+	}
+
+	// Nonce can be used only once, but all proofs in the batch can share the same nonce
+	for _, nonce := range nonces {
+		if _, err := s.ValidateNonce(ctx, nonce); err != nil {
+			return azugo.BadRequestError{Description: "invalid_nonce", Err: err}
+		}
+	}
+
+	return nil
+}
+
+// requestProofs returns JWT key proofs of the credential request, either single proof or batch of proofs.
+func requestProofs(req *CredentialRequest) ([]string, error) {
+	if req.Proof != nil && len(req.Proofs) > 0 {
+		return nil, azugo.BadRequestError{Description: "invalid_credential_request", Err: errors.New("both proof and proofs provided")}
+	}
+
+	if req.Proof != nil {
+		typ, _ := req.Proof["proof_type"].(string)
+		proof, _ := req.Proof[proofTypeJWT].(string)
+
+		if typ != proofTypeJWT || proof == "" {
+			return nil, azugo.BadRequestError{Description: "invalid_proof", Err: errors.New("unsupported proof type")}
+		}
+
+		return []string{proof}, nil
+	}
+
+	if len(req.Proofs) == 0 {
+		return nil, nil
+	}
+
+	proofs, ok := req.Proofs[proofTypeJWT]
+	if !ok || len(req.Proofs) != 1 {
+		return nil, azugo.BadRequestError{Description: "invalid_proof", Err: errors.New("unsupported proof type")}
+	}
+
+	if len(proofs) == 0 {
+		return nil, azugo.BadRequestError{Description: "invalid_proof", Err: errors.New("no proofs provided")}
+	}
+
+	return proofs, nil
+}
+
+// verifyProofJWTs validates key proofs and returns distinct nonces of the proofs.
+func (s *Service) verifyProofJWTs(proofs []string, limit int) ([]string, error) {
+	if len(proofs) > limit {
+		return nil, azugo.BadRequestError{
+			Description: "invalid_credential_request",
+			Err:         fmt.Errorf("batch size %d exceeds limit %d", len(proofs), limit),
+		}
+	}
+
+	keys := make(map[string]struct{}, len(proofs))
+	nonces := make([]string, 0, 1)
+
+	for i, proof := range proofs {
+		key, nonce, err := s.verifyProofJWT(proof)
+		if err != nil {
+			return nil, azugo.BadRequestError{Description: "invalid_proof", Err: fmt.Errorf("proof %d: %w", i, err)}
+		}
+
+		if _, ok := keys[key]; ok {
+			return nil, azugo.BadRequestError{Description: "invalid_proof", Err: fmt.Errorf("proof %d: duplicate key", i)}
+		}
+
+		keys[key] = struct{}{}
+
+		if !slices.Contains(nonces, nonce) {
+			nonces = append(nonces, nonce)
+		}
+	}
+
+	return nonces, nil
+}
+
+// verifyProofJWT validates key proof and returns encoded public key and nonce of the proof.
+func (s *Service) verifyProofJWT(proof string) (string, string, error) {
+	var publicKey *ecdsa.PublicKey
+
+	token, err := jwt.Parse(proof, func(t *jwt.Token) (any, error) {
+		if typ, ok := t.Header["typ"].(string); !ok || typ != "openid4vci-proof+jwt" {
+			return nil, errors.New("invalid proof type")
+		}
+
+		jwk, ok := t.Header["jwk"].(map[string]any)
+		if !ok {
+			return nil, errors.New("missing jwk")
+		}
+
+		key, err := s.publicKeyFromJWK(map[string]any{"jwk": jwk})
+		if err != nil {
+			return nil, err
+		}
+
+		if publicKey, ok = key.(*ecdsa.PublicKey); !ok {
+			return nil, fmt.Errorf("invalid public key type: %T", key)
+		}
+
+		return publicKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}),
+		jwt.WithAudience(s.walletPublicURL),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return "", "", err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", "", errors.New("invalid claims")
+	}
+
+	nonce, ok := claims["nonce"].(string)
+	if !ok || nonce == "" {
+		return "", "", errors.New("missing nonce")
+	}
+
+	buf, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to marshal public key: %w", err)
+	}
+
+	return string(buf), nonce, nil
+}
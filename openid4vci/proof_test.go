@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package openid4vci
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/issuer"
+
+	"azugo.io/azugo"
+	"github.com/go-quicktest/qt"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signProof(t *testing.T, key *ecdsa.PrivateKey, aud, nonce string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud":   aud,
+		"iat":   time.Now().Unix(),
+		"nonce": nonce,
+	})
+	token.Header["typ"] = "openid4vci-proof+jwt"
+	token.Header["jwk"] = ecJWK(&key.PublicKey, "P-256")
+
+	s, err := token.SignedString(key)
+	qt.Assert(t, qt.IsNil(err))
+
+	return s
+}
+
+func newProofKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	qt.Assert(t, qt.IsNil(err))
+
+	return key
+}
+
+func badRequest(t *testing.T, err error) azugo.BadRequestError {
+	t.Helper()
+
+	var d azugo.BadRequestError
+
+	qt.Assert(t, qt.ErrorAs(err, &d))
+
+	return d
+}
+
+func TestRequestProofs(t *testing.T) {
+	proofs, err := requestProofs(&CredentialRequest{})
+	qt.Check(t, qt.IsNil(err))
+	qt.Check(t, qt.IsNil(proofs))
+
+	proofs, err = requestProofs(&CredentialRequest{Proof: map[string]any{"proof_type": "jwt", "jwt": "proof"}})
+	qt.Check(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(proofs, []string{"proof"}))
+
+	proofs, err = requestProofs(&CredentialRequest{Proofs: map[string][]string{"jwt": {"proof-1", "proof-2"}}})
+	qt.Check(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(proofs, []string{"proof-1", "proof-2"}))
+
+	tests := []struct {
+		name        string
+		req         *CredentialRequest
+		description string
+	}{
+		{
+			name: "proof and proofs",
+			req: &CredentialRequest{
+				Proof:  map[string]any{"proof_type": "jwt", "jwt": "proof"},
+				Proofs: map[string][]string{"jwt": {"proof"}},
+			},
+			description: "invalid_credential_request",
+		},
+		{
+			name:        "unsupported proof type",
+			req:         &CredentialRequest{Proof: map[string]any{"proof_type": "ldp_vp", "ldp_vp": "proof"}},
+			description: "invalid_proof",
+		},
+		{
+			name:        "missing proof",
+			req:         &CredentialRequest{Proof: map[string]any{"proof_type": "jwt"}},
+			description: "invalid_proof",
+		},
+		{
+			name:        "unsupported proofs type",
+			req:         &CredentialRequest{Proofs: map[string][]string{"jwt": {"proof"}, "attestation": {"proof"}}},
+			description: "invalid_proof",
+		},
+		{
+			name:        "empty proofs",
+			req:         &CredentialRequest{Proofs: map[string][]string{"jwt": {}}},
+			description: "invalid_proof",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := requestProofs(tt.req)
+			qt.Check(t, qt.Equals(badRequest(t, err).Description, tt.description))
+		})
+	}
+}
+
+func TestVerifyProofJWTs(t *testing.T) {
+	s := &Service{
+		config:          &issuer.Configuration{BatchSize: 3},
+		walletPublicURL: fuzzWalletURL,
+	}
+
+	key1, key2, key3, key4 := newProofKey(t), newProofKey(t), newProofKey(t), newProofKey(t)
+
+	nonces, err := s.verifyProofJWTs([]string{
+		signProof(t, key1, fuzzWalletURL, "nonce-1"),
+		signProof(t, key2, fuzzWalletURL, "nonce-1"),
+	}, 3)
+	qt.Check(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(nonces, []string{"nonce-1"}))
+
+	// Every nonce of the batch is returned for validation
+	nonces, err = s.verifyProofJWTs([]string{
+		signProof(t, key1, fuzzWalletURL, "nonce-1"),
+		signProof(t, key2, fuzzWalletURL, "nonce-2"),
+		signProof(t, key3, fuzzWalletURL, "nonce-1"),
+	}, 3)
+	qt.Check(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(nonces, []string{"nonce-1", "nonce-2"}))
+
+	_, err = s.verifyProofJWTs([]string{
+		signProof(t, key1, fuzzWalletURL, "nonce-1"),
+		signProof(t, key1, fuzzWalletURL, "nonce-1"),
+	}, 3)
+	qt.Check(t, qt.Equals(badRequest(t, err).Description, "invalid_proof"))
+	qt.Check(t, qt.ErrorMatches(badRequest(t, err).Err, "proof 1: duplicate key"))
+
+	_, err = s.verifyProofJWTs([]string{
+		signProof(t, key1, fuzzWalletURL, "nonce-1"),
+		signProof(t, key2, fuzzWalletURL, "nonce-1"),
+		signProof(t, key3, fuzzWalletURL, "nonce-1"),
+		signProof(t, key4, fuzzWalletURL, "nonce-1"),
+	}, 3)
+	qt.Check(t, qt.Equals(badRequest(t, err).Description, "invalid_credential_request"))
+
+	_, err = s.verifyProofJWTs([]string{signProof(t, key1, "http://other", "nonce-1")}, 3)
+	qt.Check(t, qt.Equals(badRequest(t, err).Description, "invalid_proof"))
+
+	_, err = s.verifyProofJWTs([]string{signProof(t, key1, fuzzWalletURL, "")}, 3)
+	qt.Check(t, qt.Equals(badRequest(t, err).Description, "invalid_proof"))
+}
@@ -4,6 +4,7 @@ package openid4vci
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"crypto/sha256"
 	"crypto/x509"
@@ -39,6 +40,21 @@ func (s *Service) Assertion(ctx *azugo.Context) error {
 		return err
 	}
 
+	token, instanceID, err := s.verifyAssertion(ctx, assertion)
+	if err != nil {
+		return err
+	}
+
+	// TODO: validate Android/Apple assertion
+	// `nonce`, `hardware_signature` = sign(sha256(nonce+keypub)+hardware_key_tag)) and `key_attestation` claims
+
+	// Currently do not include person data in the attestation
+	return s.IssueAttestations(ctx, token, instanceID, nil /*person*/)
+}
+
+// verifyAssertion validates wallet instance attestation request signed by the registered wallet instance key.
+// Returns the validated token and the wallet instance ID.
+func (s *Service) verifyAssertion(ctx context.Context, assertion string) (*jwt.Token, string, error) {
 	var instanceID string
 	// var person *AttestationPerson
 
@@ -71,10 +87,12 @@ func (s *Service) Assertion(ctx *azugo.Context) error {
 			return nil, errors.New("failed to get public key")
 		}
 
-		instanceID, err = url.JoinPath(s.walletInstanceURL, keyTag)
+		id, err := url.JoinPath(s.walletInstanceURL, keyTag)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate instance ID: %w", err)
 		}
+
+		instanceID = id
 		// person = resp.Person
 
 		// Parse PEM encoded public key
@@ -93,61 +111,61 @@ func (s *Service) Assertion(ctx *azugo.Context) error {
 	)
 	if err != nil {
 		if isJWTError(err) {
-			return azugo.BadRequestError{Description: err.Error()}
+			return nil, "", azugo.BadRequestError{Description: err.Error()}
 		}
 
-		return err
+		return nil, "", err
 	}
 
 	if !token.Valid {
-		return azugo.BadRequestError{Description: "invalid token"}
+		return nil, "", azugo.BadRequestError{Description: "invalid token"}
 	}
 
 	if typ, ok := token.Header["typ"].(string); !ok || typ != "var+jwt" {
-		return azugo.BadRequestError{Description: "invalid token type for wallet attestation"}
+		return nil, "", azugo.BadRequestError{Description: "invalid token type for wallet attestation"}
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return azugo.BadRequestError{Description: "invalid claims"}
+		return nil, "", azugo.BadRequestError{Description: "invalid claims"}
 	}
 
 	if typ, ok := claims["type"].(string); !ok || typ != "WalletInstanceAttestationRequest" {
-		return azugo.BadRequestError{Description: "invalid token type for wallet attestation"}
+		return nil, "", azugo.BadRequestError{Description: "invalid token type for wallet attestation"}
 	}
 
 	// Check if valid issuer
 	issuer, err := claims.GetIssuer()
 	if err != nil || issuer != instanceID {
-		return azugo.BadRequestError{Description: "unknown issuer"}
+		return nil, "", azugo.BadRequestError{Description: "unknown issuer"}
 	}
 
 	cnf, ok := claims["cnf"].(map[string]any)
 	if !ok {
-		return azugo.BadRequestError{Description: "invalid or missing cnf"}
+		return nil, "", azugo.BadRequestError{Description: "invalid or missing cnf"}
 	}
 
 	publicKey, err := s.publicKeyFromJWK(cnf)
 	if err != nil {
-		return azugo.BadRequestError{Description: "invalid or missing cnf", Err: err}
+		return nil, "", azugo.BadRequestError{Description: "invalid or missing cnf", Err: err}
 	}
 
 	// Convert public key to X9.62 format
 	pk, ok := publicKey.(*ecdsa.PublicKey)
 	if !ok {
-		return azugo.BadRequestError{Description: fmt.Sprintf("invalid public key type: %T", publicKey)}
+		return nil, "", azugo.BadRequestError{Description: fmt.Sprintf("invalid public key type: %T", publicKey)}
 	}
 
 	pubkey, err := pk.ECDH()
 	if err != nil {
-		return fmt.Errorf("failed to convert public key: %w", err)
+		return nil, "", fmt.Errorf("failed to convert public key: %w", err)
 	}
 
 	calulatedTagBytes := sha256.Sum256(pubkey.Bytes())
 
 	tag, ok := claims["hardware_key_tag"].(string)
 	if !ok {
-		return azugo.ParamInvalidError{
+		return nil, "", azugo.ParamInvalidError{
 			Name: "hardware_key_tag",
 			Tag:  "missing",
 		}
@@ -155,7 +173,7 @@ func (s *Service) Assertion(ctx *azugo.Context) error {
 
 	tagBytes, err := base64.StdEncoding.DecodeString(tag)
 	if err != nil {
-		return azugo.ParamInvalidError{
+		return nil, "", azugo.ParamInvalidError{
 			Name: "hardware_key_tag",
 			Tag:  "invalid",
 			Err:  err,
@@ -164,17 +182,13 @@ func (s *Service) Assertion(ctx *azugo.Context) error {
 
 	// Verify the tag
 	if !bytes.Equal(tagBytes, calulatedTagBytes[:]) {
-		return azugo.ParamInvalidError{
+		return nil, "", azugo.ParamInvalidError{
 			Name: "hardware_key_tag",
 			Tag:  "invalid",
 		}
 	}
 
-	// TODO: validate Android/Apple assertion
-	// `nonce`, `hardware_signature` = sign(sha256(nonce+keypub)+hardware_key_tag)) and `key_attestation` claims
-
-	// Currently do not include person data in the attestation
-	return s.IssueAttestations(ctx, token, instanceID, nil /*person*/)
+	return token, instanceID, nil
 }
 
 func (s *Service) IssueAttestations(ctx *azugo.Context, req *jwt.Token, instanceID string, person *AttestationPerson) error {
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package qr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"strings"
+
+	_ "image/jpeg" // Register JPEG decoder for the logo image
+
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/draw"
+)
+
+// logoRatio is the maximum part of the QR code width that the logo can cover.
+const logoRatio = 0.2
+
+// Renderer renders content as a QR code image.
+type Renderer struct {
+	size  int
+	level qrcode.RecoveryLevel
+	logo  image.Image
+}
+
+// ParseRecoveryLevel parses QR code error correction level (`L`, `M`, `Q` or `H`).
+func ParseRecoveryLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qrcode.Low, nil
+	case "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	default:
+		return qrcode.Low, fmt.Errorf("unsupported QR code recovery level: %s", level)
+	}
+}
+
+// New creates QR code renderer. Logo file is optional and must be PNG or JPEG image.
+func New(size int, level string, logoFile string) (*Renderer, error) {
+	l, err := ParseRecoveryLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Renderer{
+		size:  size,
+		level: l,
+	}
+
+	if logoFile == "" {
+		return r, nil
+	}
+
+	// Logo covers part of the modules so it must be restored by error correction
+	if l < qrcode.High {
+		return nil, errors.New("QR code logo requires recovery level Q or H")
+	}
+
+	f, err := os.Open(logoFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open QR code logo: %w", err)
+	}
+	defer f.Close()
+
+	r.logo, _, err = image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode QR code logo: %w", err)
+	}
+
+	return r, nil
+}
+
+// PNG renders content as PNG image.
+func (r *Renderer) PNG(content string) ([]byte, error) {
+	q, err := qrcode.New(content, r.level)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.logo == nil {
+		return q.PNG(r.size)
+	}
+
+	src := q.Image(r.size)
+
+	img := image.NewRGBA(src.Bounds())
+	draw.Draw(img, img.Bounds(), src, image.Point{}, draw.Src)
+
+	logo := r.logoRect(img.Bounds().Dx())
+	draw.CatmullRom.Scale(img, logo, r.logo, r.logo.Bounds(), draw.Over, nil)
+
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SVG renders content as SVG image.
+func (r *Renderer) SVG(content string) ([]byte, error) {
+	q, err := qrcode.New(content, r.level)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap := q.Bitmap()
+	modules := len(bitmap)
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, r.size, r.size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, modules, modules)
+
+	for y, row := range bitmap {
+		for x, v := range row {
+			if v {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+
+	buf.WriteString(`"/>`)
+
+	if r.logo != nil {
+		var logo bytes.Buffer
+
+		if err := png.Encode(&logo, r.logo); err != nil {
+			return nil, err
+		}
+
+		rect := r.logoRect(modules)
+
+		fmt.Fprintf(&buf, `<image x="%d" y="%d" width="%d" height="%d" href="data:image/png;base64,%s"/>`,
+			rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), base64.StdEncoding.EncodeToString(logo.Bytes()))
+	}
+
+	buf.WriteString(`</svg>`)
+
+	return buf.Bytes(), nil
+}
+
+// logoRect returns centered logo position keeping logo aspect ratio.
+func (r *Renderer) logoRect(size int) image.Rectangle {
+	b := r.logo.Bounds()
+	m := max(int(float64(size)*logoRatio), 1)
+
+	w, h := m, max(m*b.Dy()/max(b.Dx(), 1), 1)
+	if b.Dy() > b.Dx() {
+		w, h = max(m*b.Dx()/b.Dy(), 1), m
+	}
+
+	x := (size - w) / 2
+	y := (size - h) / 2
+
+	return image.Rect(x, y, x+w, y+h)
+}
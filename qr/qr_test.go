@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package qr
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+const testOffer = "openid-credential-offer://?credential_offer_uri=https%3A%2F%2Fwallet.example.lv%2Fcredential-offer%2Ftest"
+
+func TestRenderer_PNG(t *testing.T) {
+	r, err := New(256, "M", "")
+	qt.Assert(t, qt.IsNil(err))
+
+	buf, err := r.PNG(testOffer)
+	qt.Assert(t, qt.IsNil(err))
+
+	img, err := png.Decode(bytes.NewReader(buf))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(img.Bounds().Dx(), 256))
+	qt.Check(t, qt.Equals(img.Bounds().Dy(), 256))
+}
+
+func TestRenderer_SVG(t *testing.T) {
+	r, err := New(300, "L", "")
+	qt.Assert(t, qt.IsNil(err))
+
+	buf, err := r.SVG(testOffer)
+	qt.Assert(t, qt.IsNil(err))
+
+	svg := string(buf)
+	qt.Check(t, qt.IsTrue(strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="300" height="300"`)))
+	qt.Check(t, qt.IsTrue(strings.HasSuffix(svg, `</svg>`)))
+	qt.Check(t, qt.IsFalse(strings.Contains(svg, "<image")))
+}
+
+func TestRenderer_Logo(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for x := range 40 {
+		for y := range 20 {
+			logo.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "logo.png")
+
+	f, err := os.Create(file)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsNil(png.Encode(f, logo)))
+	qt.Assert(t, qt.IsNil(f.Close()))
+
+	_, err = New(256, "M", file)
+	qt.Assert(t, qt.ErrorMatches(err, "QR code logo requires recovery level Q or H"))
+
+	r, err := New(250, "H", file)
+	qt.Assert(t, qt.IsNil(err))
+
+	buf, err := r.PNG(testOffer)
+	qt.Assert(t, qt.IsNil(err))
+
+	img, err := png.Decode(bytes.NewReader(buf))
+	qt.Assert(t, qt.IsNil(err))
+
+	// Logo is centered and keeps aspect ratio
+	qt.Check(t, qt.Equals(r.logoRect(250), image.Rect(100, 112, 150, 137)))
+	qt.Check(t, qt.Equals(color.RGBAModel.Convert(img.At(125, 125)), color.Color(color.RGBA{R: 255, A: 255})))
+
+	buf, err = r.SVG(testOffer)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.IsTrue(strings.Contains(string(buf), `<image x=`)))
+}
+
+func TestParseRecoveryLevel(t *testing.T) {
+	_, err := ParseRecoveryLevel("X")
+	qt.Check(t, qt.ErrorMatches(err, "unsupported QR code recovery level: X"))
+}
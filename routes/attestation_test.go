@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	api "git.zzdats.lv/edim/api-wallet"
+	"git.zzdats.lv/edim/api-wallet/attestation/attestationtest"
+	"git.zzdats.lv/edim/api-wallet/mock/idauthtest"
+	"git.zzdats.lv/edim/api-wallet/mock/jsondbtest"
+	"git.zzdats.lv/edim/api-wallet/routes/request"
+
+	"azugo.io/azugo"
+	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
+)
+
+// nonce returns attestation challenge issued for the access token.
+func nonce(t *testing.T, app *azugo.TestApp, token string) string {
+	t.Helper()
+
+	status, body := testRequest(t, app, fasthttp.MethodPost, "/nonce", token, nil)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK), qt.Commentf("%s", body))
+
+	res := struct {
+		Nonce string `json:"c_nonce"` //nolint:tagliatelle
+	}{}
+	qt.Assert(t, qt.IsNil(json.Unmarshal(body, &res)))
+	qt.Assert(t, qt.Not(qt.Equals(res.Nonce, "")))
+
+	return res.Nonce
+}
+
+// registerInstance registers wallet instance with Android key attestation of the challenge hash.
+func registerInstance(t *testing.T, app *azugo.TestApp, ca *attestationtest.CA, challenge string) (*attestationtest.Attestation, int, []byte) {
+	t.Helper()
+
+	buf, err := base64.RawURLEncoding.DecodeString(challenge)
+	qt.Assert(t, qt.IsNil(err))
+
+	attch := sha256.Sum256(buf)
+
+	att, err := ca.Android(attestationtest.AndroidOptions{
+		Challenge:   attch[:],
+		CertsIssued: 1,
+	})
+	qt.Assert(t, qt.IsNil(err))
+
+	body, err := json.Marshal(&request.AttestationRequest{
+		Challenge:      challenge,
+		KeyAttestation: att.Attestation,
+		HardwareKeyTag: att.HardwareKeyTag,
+	})
+	qt.Assert(t, qt.IsNil(err))
+
+	status, res := testRequest(t, app, fasthttp.MethodPost, "/instance", "", body)
+
+	return att, status, res
+}
+
+func TestInstanceRegistration(t *testing.T) {
+	ca, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	store := jsondbtest.New()
+
+	app, idp := testApp(t,
+		api.WithStore(store),
+		api.WithEnv("ATTESTATION_TEST_ROOT_CA", ca.CertificatePEM()),
+	)
+
+	app.Start(t)
+	defer app.Stop()
+
+	tok := idp.AddUser("32345678901", idauthtest.WithName("Jānis", "Paraugs"))
+
+	// Wallet instance of the identified person
+	challenge := nonce(t, app, tok)
+
+	att, status, body := registerInstance(t, app, ca, challenge)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusCreated), qt.Commentf("%s", body))
+
+	instance, ok := store.Instance(att.HardwareKeyTag)
+	qt.Assert(t, qt.IsTrue(ok))
+	qt.Check(t, qt.Equals(instance.CertsIssued, 1))
+	qt.Check(t, qt.Not(qt.Equals(instance.PublicKey, "")))
+	qt.Assert(t, qt.IsNotNil(instance.Person))
+	qt.Check(t, qt.DeepEquals(*instance.Person, jsondbtest.Person{
+		Code:          "32345678901",
+		GivenName:     "Jānis",
+		FamilyName:    "Paraugs",
+		RequesterCode: "32345678901",
+	}))
+
+	// Challenge can be used only once
+	_, status, _ = registerInstance(t, app, ca, challenge)
+	qt.Check(t, qt.Not(qt.Equals(status, fasthttp.StatusCreated)))
+	qt.Check(t, qt.Equals(store.Instances(), 1))
+
+	// Anonymous wallet instance
+	att, status, body = registerInstance(t, app, ca, nonce(t, app, ""))
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusCreated), qt.Commentf("%s", body))
+
+	instance, ok = store.Instance(att.HardwareKeyTag)
+	qt.Assert(t, qt.IsTrue(ok))
+	qt.Check(t, qt.IsTrue(instance.Anonymous()))
+	qt.Check(t, qt.Equals(store.Instances(), 2))
+}
+
+func TestInstanceRegistration_Invalid(t *testing.T) {
+	ca, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	untrusted, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	store := jsondbtest.New()
+
+	app, _ := testApp(t,
+		api.WithStore(store),
+		api.WithEnv("ATTESTATION_TEST_ROOT_CA", ca.CertificatePEM()),
+	)
+
+	app.Start(t)
+	defer app.Stop()
+
+	// Attestation signed by untrusted root CA
+	_, status, _ := registerInstance(t, app, untrusted, nonce(t, app, ""))
+	qt.Check(t, qt.Not(qt.Equals(status, fasthttp.StatusCreated)))
+
+	// Attestation of the other challenge
+	buf, err := base64.RawURLEncoding.DecodeString(nonce(t, app, ""))
+	qt.Assert(t, qt.IsNil(err))
+
+	attch := sha256.Sum256(buf)
+
+	att, err := ca.Android(attestationtest.AndroidOptions{Challenge: attch[:]})
+	qt.Assert(t, qt.IsNil(err))
+
+	body, err := json.Marshal(&request.AttestationRequest{
+		Challenge:      nonce(t, app, ""),
+		KeyAttestation: att.Attestation,
+		HardwareKeyTag: att.HardwareKeyTag,
+	})
+	qt.Assert(t, qt.IsNil(err))
+
+	status, _ = testRequest(t, app, fasthttp.MethodPost, "/instance", "", body)
+	qt.Check(t, qt.Not(qt.Equals(status, fasthttp.StatusCreated)))
+
+	// Challenge is not base64url encoded
+	status, _ = testRequest(t, app, fasthttp.MethodPost, "/instance", "", []byte(`{"challenge":"!"}`))
+	qt.Check(t, qt.Equals(status, fasthttp.StatusUnprocessableEntity))
+
+	qt.Check(t, qt.Equals(store.Instances(), 0))
+}
+
+func TestInstanceRegistration_TestRootCA(t *testing.T) {
+	ca, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	store := jsondbtest.New()
+
+	app, _ := testApp(t,
+		api.WithStore(store),
+		api.WithEnv("ATTESTATION_TEST_ROOT_CA", ca.CertificatePEM()),
+	)
+
+	app.Start(t)
+	defer app.Stop()
+
+	// Test root CA is trusted for iOS attestations as well
+	challenge := nonce(t, app, "")
+
+	buf, err := base64.RawURLEncoding.DecodeString(challenge)
+	qt.Assert(t, qt.IsNil(err))
+
+	attch := sha256.Sum256(buf)
+
+	att, err := ca.Apple(attestationtest.AppleOptions{Challenge: attch[:]})
+	qt.Assert(t, qt.IsNil(err))
+
+	body, err := json.Marshal(&request.AttestationRequest{
+		Challenge:      challenge,
+		KeyAttestation: att.Attestation,
+		HardwareKeyTag: att.HardwareKeyTag,
+	})
+	qt.Assert(t, qt.IsNil(err))
+
+	status, res := testRequest(t, app, fasthttp.MethodPost, "/instance", "", body)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusCreated), qt.Commentf("%s", res))
+
+	instance, ok := store.Instance(att.HardwareKeyTag)
+	qt.Assert(t, qt.IsTrue(ok))
+	qt.Check(t, qt.Equals(instance.DeviceType, "ios"))
+}
@@ -164,7 +164,7 @@ func (r *router) eparakstsCloseSession(ctx *azugo.Context) {
 
 // @operationId eparakstsValidate
 // @title eparaksts validate
-// @description Method to validate eparaksts file
+// @description Method to validate signatures of the eparaksts file. SimpleSign validation report is normalized to the stable schema
 // @accept multipart/form-data
 // @param file file file true "File to upload. Form key needs to be the filename of the file with extension. Example: file.edoc"
 // @param json formData string true "Json data for request in string format"
@@ -172,6 +172,7 @@ func (r *router) eparakstsCloseSession(ctx *azugo.Context) {
 // @failure 400 string string "Bad request"
 // @failure 422 string string "Invalid request"
 // @failure 500 string string "Internal server error"
+// @failure 502 string string "Invalid SimpleSign validation response"
 // @resource Eparaksts
 // @route /eparaksts/validate [post].
 func (r *router) eparakstsValidate(ctx *azugo.Context) {
@@ -204,7 +205,7 @@ func (r *router) eparakstsValidate(ctx *azugo.Context) {
 		return
 	}
 
-	simpleSignResp, err := r.App.SimpleSignClient().ValidateFile(ctx, reqJSON, reqFile)
+	res, err := r.App.SimpleSignClient().ValidateFile(ctx, reqJSON, reqFile)
 	if err != nil {
 		d := azugo.BadRequestError{}
 		if errors.As(err, &d) {
@@ -219,7 +220,7 @@ func (r *router) eparakstsValidate(ctx *azugo.Context) {
 		return
 	}
 
-	ctx.Raw(simpleSignResp)
+	ctx.JSON(res)
 }
 
 // @operationId eparakstsIdentities
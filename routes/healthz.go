@@ -3,6 +3,12 @@
 package routes
 
 import (
+	"context"
+	"sync"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/credential"
+
 	"azugo.io/azugo"
 )
 
@@ -30,17 +36,92 @@ const (
 
 // HealthzResponse is the data returned by the health endpoint, which will be marshaled to JSON format.
 type HealthzResponse struct {
-	Status      HealthzStatus `json:"status"`
-	Description string        `json:"description"` // a human-friendly description of the service
+	Status      HealthzStatus             `json:"status"`
+	Description string                    `json:"description"` // a human-friendly description of the service
+	Checks      map[string][]HealthzCheck `json:"checks,omitempty"`
+}
+
+// HealthzCheck is the status of the downstream dependency.
+type HealthzCheck struct {
+	ComponentID   string        `json:"componentId"`
+	ComponentType string        `json:"componentType"`
+	ObservedValue any           `json:"observedValue,omitempty"`
+	Status        HealthzStatus `json:"status"`
+	Output        string        `json:"output,omitempty"`
+	Time          time.Time     `json:"time"`
 }
 
 func (r *router) healthz(ctx *azugo.Context) {
 	ctx.SkipRequestLog()
 
-	// TODO: Implement health checks
-
-	ctx.JSON(&HealthzResponse{
+	res := &HealthzResponse{
 		Status:      HealthzPass,
 		Description: ctx.App().AppName,
-	})
+		Checks:      make(map[string][]HealthzCheck),
+	}
+
+	sources := r.Credentials().Sources()
+
+	// Sources are checked in parallel so that the health check completes within
+	// credential.HealthCheckTimeout regardless of the number of sources. Request context
+	// must not be used from other goroutines, so checks share the context with timeout.
+	// Check results are cached by the source clients for credential.HealthCheckCacheTTL.
+	checkCtx, cancel := context.WithTimeout(ctx, credential.HealthCheckTimeout)
+	defer cancel()
+
+	availability := make([]HealthzCheck, len(sources))
+
+	var wg sync.WaitGroup
+
+	for n, source := range sources {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			check := HealthzCheck{
+				ComponentID:   source.Type(),
+				ComponentType: "component",
+				Status:        HealthzPass,
+			}
+
+			if err := source.HealthCheck(checkCtx); err != nil {
+				check.Status = HealthzWarn
+				check.Output = err.Error()
+			}
+
+			check.Time = time.Now().UTC()
+
+			availability[n] = check
+		}()
+	}
+
+	wg.Wait()
+
+	// Unavailable credential source only affects issuance of its credential type
+	for n, source := range sources {
+		check := availability[n]
+		if check.Status != HealthzPass {
+			res.Status = HealthzWarn
+		}
+
+		res.Checks[source.Type()+":availability"] = []HealthzCheck{check}
+
+		breaker := HealthzCheck{
+			ComponentID:   source.Type(),
+			ComponentType: "component",
+			ObservedValue: source.BreakerState(),
+			Status:        HealthzPass,
+			Time:          time.Now().UTC(),
+		}
+
+		if breaker.ObservedValue != credential.BreakerClosed {
+			breaker.Status = HealthzWarn
+			res.Status = HealthzWarn
+		}
+
+		res.Checks[source.Type()+":circuit_breaker"] = []HealthzCheck{breaker}
+	}
+
+	ctx.JSON(res)
 }
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package routes
+
+import (
+	"encoding/json"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	api "git.zzdats.lv/edim/api-wallet"
+
+	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
+)
+
+// slowUpstream starts source registry fake that responds to the health check after the delay.
+// Returns the URL of the fake and the number of health check requests received.
+func slowUpstream(t *testing.T, delay time.Duration) (string, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	qt.Assert(t, qt.IsNil(err))
+
+	srv := &fasthttp.Server{
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			calls.Add(1)
+			time.Sleep(delay)
+			ctx.SetStatusCode(fasthttp.StatusOK)
+		},
+	}
+
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+
+	t.Cleanup(func() {
+		_ = srv.Shutdown()
+	})
+
+	return "http://" + ln.Addr().String(), &calls
+}
+
+func TestHealthz(t *testing.T) {
+	app, _ := testApp(t, withUpstreams(t)...)
+
+	app.Start(t)
+	defer app.Stop()
+
+	status, body := testRequest(t, app, fasthttp.MethodGet, "/healthz", "", nil)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK))
+
+	res := HealthzResponse{}
+	qt.Assert(t, qt.IsNil(json.Unmarshal(body, &res)))
+	qt.Check(t, qt.Equals(res.Status, HealthzPass))
+	qt.Check(t, qt.Equals(res.Checks["pid:availability"][0].Status, HealthzPass))
+}
+
+func TestHealthz_SlowSources(t *testing.T) {
+	fpris, fprisCalls := slowUpstream(t, 3*time.Second)
+	rtu, _ := slowUpstream(t, 3*time.Second)
+
+	app, _ := testApp(t, append(withUpstreams(t),
+		api.WithEnv("FPRIS_API_URL", fpris),
+		api.WithEnv("RTU_API_URL", rtu),
+		api.WithEnv("FPRIS_API_TIMEOUT", "10s"),
+		api.WithEnv("RTU_API_TIMEOUT", "10s"),
+	)...)
+
+	app.Start(t)
+	defer app.Stop()
+
+	start := time.Now()
+
+	status, body := testRequest(t, app, fasthttp.MethodGet, "/healthz", "", nil)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK))
+
+	// Slow sources are checked in parallel within the health check timeout
+	qt.Check(t, qt.IsTrue(time.Since(start) < 3*time.Second), qt.Commentf("health check took %s", time.Since(start)))
+
+	res := HealthzResponse{}
+	qt.Assert(t, qt.IsNil(json.Unmarshal(body, &res)))
+	qt.Check(t, qt.Equals(res.Status, HealthzWarn))
+	qt.Check(t, qt.Equals(res.Checks["pid:availability"][0].Status, HealthzWarn))
+	qt.Check(t, qt.Equals(res.Checks["age:availability"][0].Status, HealthzWarn))
+	qt.Check(t, qt.Equals(res.Checks["rtu:availability"][0].Status, HealthzWarn))
+	qt.Check(t, qt.Equals(res.Checks["mdl:availability"][0].Status, HealthzPass))
+
+	// Sources that share the FPRIS client are probed once
+	qt.Check(t, qt.Equals(fprisCalls.Load(), int32(1)))
+}
+
+func TestHealthz_Cached(t *testing.T) {
+	fpris, fprisCalls := slowUpstream(t, 0)
+
+	app, _ := testApp(t, append(withUpstreams(t), api.WithEnv("FPRIS_API_URL", fpris))...)
+
+	app.Start(t)
+	defer app.Stop()
+
+	for range 3 {
+		status, _ := testRequest(t, app, fasthttp.MethodGet, "/healthz", "", nil)
+		qt.Check(t, qt.Equals(status, fasthttp.StatusOK))
+	}
+
+	// Unauthenticated health checks do not load the source registry on every call
+	qt.Check(t, qt.Equals(fprisCalls.Load(), int32(1)))
+}
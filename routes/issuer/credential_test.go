@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package issuer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	wallet "git.zzdats.lv/edim/api-wallet"
+	"git.zzdats.lv/edim/api-wallet/mock/jsondbtest"
+
+	"github.com/go-quicktest/qt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/valyala/fasthttp"
+)
+
+// credentialIssuer is the fake upstream issuer that issues credential for every request.
+func credentialIssuer(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("application/json")
+	ctx.SetBodyString(`{"credentials":[{"credential":"credential"}]}`)
+}
+
+// keyProof returns key proof JWT signed by the new key.
+func keyProof(t *testing.T, nonce string) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	qt.Assert(t, qt.IsNil(err))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud":   "http://wallet:8080",
+		"iat":   time.Now().Unix(),
+		"nonce": nonce,
+	})
+	token.Header["typ"] = "openid4vci-proof+jwt"
+	token.Header["jwk"] = map[string]any{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+
+	s, err := token.SignedString(key)
+	qt.Assert(t, qt.IsNil(err))
+
+	return s
+}
+
+func credentialRequest(t *testing.T, req map[string]any) string {
+	t.Helper()
+
+	buf, err := json.Marshal(req)
+	qt.Assert(t, qt.IsNil(err))
+
+	return string(buf)
+}
+
+func TestCredential_Proofs(t *testing.T) {
+	app, a, _ := testApp(t,
+		upstreamIssuer(t, credentialIssuer),
+		wallet.WithEnv("ISSUER_BATCH_SIZE", "10"),
+		wallet.WithEnv("ISSUER_BATCH_SIZE_LIMITS", "eu.europa.ec.eudi.pid_mdoc=1"),
+	)
+
+	err := a.OpenID4VCI().BindAccessToken(context.Background(), "access-token", "", []byte(`{
+		"access_token": "access-token",
+		"authorization_details": [{
+			"type": "openid_credential",
+			"credential_configuration_id": "eu.europa.ec.eudi.pid_mdoc",
+			"credential_identifiers": ["pid"]
+		}]
+	}`))
+	qt.Assert(t, qt.IsNil(err))
+
+	app.Start(t)
+	defer app.Stop()
+
+	tests := []struct {
+		name  string
+		req   map[string]any
+		error string
+	}{
+		{
+			name: "single proof with invalid nonce",
+			req: map[string]any{
+				"credential_configuration_id": "eu.europa.ec.eudi.mdl_mdoc",
+				"proof":                       map[string]any{"proof_type": "jwt", "jwt": keyProof(t, "nonce")},
+			},
+			error: "invalid_nonce",
+		},
+		{
+			name: "single proof with unsupported type",
+			req: map[string]any{
+				"credential_configuration_id": "eu.europa.ec.eudi.mdl_mdoc",
+				"proof":                       map[string]any{"proof_type": "cwt", "cwt": "proof"},
+			},
+			error: "invalid_proof",
+		},
+		{
+			name: "batch limit of credential configuration",
+			req: map[string]any{
+				"credential_configuration_id": "eu.europa.ec.eudi.pid_mdoc",
+				"proofs":                      map[string]any{"jwt": []string{keyProof(t, "nonce"), keyProof(t, "nonce")}},
+			},
+			error: "invalid_credential_request",
+		},
+		{
+			name: "batch limit of credential identifier",
+			req: map[string]any{
+				"credential_identifier": "pid",
+				"proofs":                map[string]any{"jwt": []string{keyProof(t, "nonce"), keyProof(t, "nonce")}},
+			},
+			error: "invalid_credential_request",
+		},
+		{
+			name: "unknown credential identifier",
+			req: map[string]any{
+				"credential_identifier": "mdl",
+				"proofs":                map[string]any{"jwt": []string{keyProof(t, "nonce")}},
+			},
+			error: "unknown_credential_identifier",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := postJSON(t, app, "/credential", "access-token", credentialRequest(t, tt.req))
+			qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+			qt.Check(t, qt.JSONEquals([]byte(body), map[string]any{"error": tt.error}))
+		})
+	}
+
+	// Batch limit of other credential configurations is not affected
+	status, body := postJSON(t, app, "/credential", "other-token", credentialRequest(t, map[string]any{
+		"credential_configuration_id": "eu.europa.ec.eudi.mdl_mdoc",
+		"proofs":                      map[string]any{"jwt": []string{keyProof(t, "nonce"), keyProof(t, "nonce")}},
+	}))
+	qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+	qt.Check(t, qt.JSONEquals([]byte(body), map[string]any{"error": "invalid_nonce"}))
+}
+
+func TestCredential_InvalidRequest(t *testing.T) {
+	var calls atomic.Int32
+
+	app, _, _ := testApp(t, wallet.WithStore(jsondbtest.New()), upstreamIssuer(t, func(ctx *fasthttp.RequestCtx) {
+		calls.Add(1)
+		credentialIssuer(ctx)
+	}))
+
+	app.Start(t)
+	defer app.Stop()
+
+	// Request that can not be parsed is not passed to the upstream issuer without proof validation
+	for _, req := range []string{`not json`, `{"proofs":`, `[]`} {
+		status, body := postJSON(t, app, "/credential", "access-token", req)
+		qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+		qt.Check(t, qt.JSONEquals([]byte(body), map[string]any{"error": "invalid_credential_request"}))
+	}
+
+	qt.Check(t, qt.Equals(calls.Load(), int32(0)))
+
+	// Encrypted request is passed to the upstream issuer as is
+	client := app.TestClient()
+
+	resp, err := client.Post("/credential", []byte("eyJhbGciOiJFQ0RILUVTIiwiZW5jIjoiQTI1NkdDTSJ9..iv.ciphertext.tag"),
+		client.WithHeader(fasthttp.HeaderContentType, contentTypeJWT),
+		client.WithHeader(fasthttp.HeaderAuthorization, "Bearer access-token"),
+	)
+	qt.Assert(t, qt.IsNil(err))
+
+	defer fasthttp.ReleaseResponse(resp)
+
+	qt.Check(t, qt.Equals(resp.StatusCode(), fasthttp.StatusOK))
+	qt.Check(t, qt.Equals(calls.Load(), int32(1)))
+}
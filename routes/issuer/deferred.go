@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package issuer
+
+import (
+	"encoding/json"
+	"errors"
+
+	"azugo.io/azugo"
+	"azugo.io/core/http"
+)
+
+type deferredCredentialRequest struct {
+	TransactionID string `json:"transaction_id"`
+}
+
+// deferredTransactionID returns transaction ID from the upstream issuer credential response if issuance was deferred.
+func deferredTransactionID(body []byte) string {
+	res := deferredCredentialRequest{}
+
+	if err := json.Unmarshal(body, &res); err != nil {
+		return ""
+	}
+
+	return res.TransactionID
+}
+
+// credentialIssued checks if the upstream issuer has returned credential in the response.
+func credentialIssued(body []byte) bool {
+	res := struct {
+		Credential  any   `json:"credential"`
+		Credentials []any `json:"credentials"`
+	}{}
+
+	if err := json.Unmarshal(body, &res); err != nil {
+		return false
+	}
+
+	return res.Credential != nil || len(res.Credentials) > 0
+}
+
+// @operationId DeferredCredential
+// @title Deferred credential
+// @description Returns credential for the previously deferred credential issuance transaction.
+// @success 200 object string "OK"
+// @failure 400 string string "Bad request"
+// @failure 401 {empty} "Unauthorized"
+// @failure 500 string string "Internal server error"
+// @resource Issuer
+// @route /deferred_credential [post].
+func (r *router) deferredCredential(ctx *azugo.Context) {
+	tok := accessToken(ctx)
+	if tok == "" {
+		ctx.Error(http.UnauthorizedError{})
+
+		return
+	}
+
+	req := deferredCredentialRequest{}
+
+	if err := ctx.Body.JSON(&req); err != nil || req.TransactionID == "" {
+		errorResponse(ctx, "invalid_request")
+
+		return
+	}
+
+	tx, err := r.OpenID4VCI().DeferredTransaction(ctx, req.TransactionID, tok)
+	if err != nil {
+		if errors.Is(err, http.NotFoundError{}) {
+			errorResponse(ctx, "invalid_transaction_id")
+
+			return
+		}
+
+		ctx.Error(err)
+
+		return
+	}
+
+	resp, err := r.forward(ctx, "/deferred_credential")
+	if err != nil {
+		ctx.Error(err)
+
+		return
+	}
+
+	body := resp.Body()
+
+	if resp.Success() && credentialIssued(body) {
+		if err := r.OpenID4VCI().CompleteDeferredTransaction(ctx, req.TransactionID); err != nil {
+			ctx.Error(err)
+
+			return
+		}
+
+		r.offerCredentialIssued(ctx)
+
+		body = r.withNotification(ctx, body, tx.CredentialConfigurationID)
+	}
+
+	ctx.Raw(body)
+	ctx.StatusCode(resp.StatusCode())
+}
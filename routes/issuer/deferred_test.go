@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package issuer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	wallet "git.zzdats.lv/edim/api-wallet"
+	"git.zzdats.lv/edim/api-wallet/mock/jsondbtest"
+	"git.zzdats.lv/edim/api-wallet/openid4vci"
+
+	"azugo.io/azugo"
+	"azugo.io/core/http"
+	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
+)
+
+// deferredIssuer is the fake upstream issuer that defers issuance of every credential.
+func deferredIssuer(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("application/json")
+
+	switch string(ctx.Path()) {
+	case "/credential":
+		ctx.SetStatusCode(fasthttp.StatusAccepted)
+		ctx.SetBodyString(`{"transaction_id":"tx-1","interval":5}`)
+	case "/deferred_credential":
+		ctx.SetBodyString(`{"credentials":[{"credential":"credential"}]}`)
+	default:
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+	}
+}
+
+// testDeferredApp returns test app with the deferring upstream issuer. Access token "access-token" is bound
+// to the wallet instance "instance-1".
+func testDeferredApp(t *testing.T) (*azugo.TestApp, *jsondbtest.Store) {
+	t.Helper()
+
+	store := jsondbtest.New()
+
+	app, a, _ := testApp(t, wallet.WithStore(store), upstreamIssuer(t, deferredIssuer))
+
+	qt.Assert(t, qt.IsNil(a.OpenID4VCI().BindAccessToken(context.Background(), "access-token", "instance-1", []byte(`{}`))))
+
+	return app, store
+}
+
+func postJSON(t *testing.T, app *azugo.TestApp, path, tok, body string) (int, string) {
+	t.Helper()
+
+	client := app.TestClient()
+
+	opts := []http.RequestOption{client.WithHeader(fasthttp.HeaderContentType, "application/json")}
+	if tok != "" {
+		opts = append(opts, client.WithHeader(fasthttp.HeaderAuthorization, "Bearer "+tok))
+	}
+
+	resp, err := client.Post(path, []byte(body), opts...)
+	qt.Assert(t, qt.IsNil(err))
+
+	defer fasthttp.ReleaseResponse(resp)
+
+	buf, err := resp.BodyUncompressed()
+	qt.Assert(t, qt.IsNil(err))
+
+	return resp.StatusCode(), string(buf)
+}
+
+func TestCredential_MissingAccessToken(t *testing.T) {
+	app, store := testDeferredApp(t)
+
+	app.Start(t)
+	defer app.Stop()
+
+	status, _ := postJSON(t, app, "/credential", "", `{"credential_configuration_id":"eu.europa.ec.eudi.pid_mdoc"}`)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusUnauthorized))
+
+	_, ok := store.DeferredTransaction("tx-1")
+	qt.Check(t, qt.IsFalse(ok))
+}
+
+func TestDeferredCredential(t *testing.T) {
+	app, store := testDeferredApp(t)
+
+	app.Start(t)
+	defer app.Stop()
+
+	status, body := postJSON(t, app, "/credential", "access-token", `{"credential_configuration_id":"eu.europa.ec.eudi.pid_mdoc"}`)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusAccepted))
+	qt.Check(t, qt.JSONEquals([]byte(body), map[string]any{"transaction_id": "tx-1", "interval": 5}))
+
+	tx, ok := store.DeferredTransaction("tx-1")
+	qt.Assert(t, qt.IsTrue(ok))
+	qt.Check(t, qt.Equals(tx.AccessTokenHash, openid4vci.AccessTokenHash("access-token")))
+	qt.Check(t, qt.Equals(tx.CredentialConfigurationID, "eu.europa.ec.eudi.pid_mdoc"))
+
+	status, _ = postJSON(t, app, "/deferred_credential", "", `{"transaction_id":"tx-1"}`)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusUnauthorized))
+
+	status, body = postJSON(t, app, "/deferred_credential", "access-token", `{"transaction_id":"unknown"}`)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+	qt.Check(t, qt.JSONEquals([]byte(body), map[string]any{"error": "invalid_transaction_id"}))
+
+	status, body = postJSON(t, app, "/deferred_credential", "other-token", `{"transaction_id":"tx-1"}`)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+	qt.Check(t, qt.JSONEquals([]byte(body), map[string]any{"error": "invalid_transaction_id"}))
+
+	status, body = postJSON(t, app, "/deferred_credential", "access-token", `{"transaction_id":"tx-1"}`)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK))
+
+	res := struct {
+		Credentials    []any  `json:"credentials"`
+		NotificationID string `json:"notification_id"`
+	}{}
+
+	qt.Assert(t, qt.IsNil(json.Unmarshal([]byte(body), &res)))
+	qt.Check(t, qt.HasLen(res.Credentials, 1))
+	qt.Check(t, qt.Not(qt.Equals(res.NotificationID, "")))
+
+	// Notification of the deferred credential keeps the credential configuration of the original request
+	n, ok := store.Notification(res.NotificationID)
+	qt.Assert(t, qt.IsTrue(ok))
+	qt.Check(t, qt.Equals(n.CredentialConfigurationID, "eu.europa.ec.eudi.pid_mdoc"))
+	qt.Check(t, qt.Equals(n.InstanceID, "instance-1"))
+
+	tx, _ = store.DeferredTransaction("tx-1")
+	qt.Check(t, qt.Equals(tx.Status, "issued"))
+
+	// Completed transaction can not be redeemed again
+	status, body = postJSON(t, app, "/deferred_credential", "access-token", `{"transaction_id":"tx-1"}`)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+	qt.Check(t, qt.JSONEquals([]byte(body), map[string]any{"error": "invalid_transaction_id"}))
+}
@@ -6,11 +6,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"strings"
 
+	walletissuer "git.zzdats.lv/edim/api-wallet/issuer"
+	"git.zzdats.lv/edim/api-wallet/openid4vci"
+
 	"azugo.io/azugo"
 	"azugo.io/core/http"
 	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
 )
 
 // @operationId GetOpenIDCredentialIssuer
@@ -43,6 +48,14 @@ func (r *router) openIDCredentialIssuer(ctx *azugo.Context) {
 	res["nonce_endpoint"] = publicURL + "/nonce"
 	res["credential_issuer"] = publicURL
 	res["credential_endpoint"] = publicURL + "/credential"
+	res["deferred_credential_endpoint"] = publicURL + "/deferred_credential"
+	res["notification_endpoint"] = publicURL + "/notification"
+
+	if r.Config().Issuer.BatchSize > 1 {
+		res["batch_credential_issuance"] = map[string]any{
+			"batch_size": r.Config().Issuer.BatchSize,
+		}
+	}
 
 	ctx.JSON(res)
 }
@@ -151,13 +164,86 @@ func (r *router) openIDJWKS(ctx *azugo.Context) {
 }
 
 func (r *router) credential(ctx *azugo.Context) {
+	tok := accessToken(ctx)
+	if tok == "" {
+		ctx.Error(http.UnauthorizedError{})
+
+		return
+	}
+
+	req := &openid4vci.CredentialRequest{}
+
+	// Encrypted credential requests are passed to the upstream issuer as is
+	if !encryptedRequest(ctx) {
+		if err := ctx.Body.JSON(req); err != nil {
+			ctx.Log().Debug("invalid credential request", zap.Error(err))
+			errorResponse(ctx, "invalid_credential_request")
+
+			return
+		}
+
+		if err := r.OpenID4VCI().VerifyProofs(ctx, req, tok); err != nil {
+			d := azugo.BadRequestError{}
+			if errors.As(err, &d) {
+				ctx.Log().Debug("invalid credential request proofs", zap.Error(err))
+				errorResponse(ctx, d.Description)
+
+				return
+			}
+
+			ctx.Error(err)
+
+			return
+		}
+	}
+
+	resp, err := r.forward(ctx, "/credential")
+	if err != nil {
+		ctx.Error(err)
+
+		return
+	}
+
+	body := resp.Body()
+
+	// Upstream issuer can not issue credential yet, keep track of the transaction
+	if transactionID := deferredTransactionID(body); transactionID != "" {
+		if err := r.OpenID4VCI().CreateDeferredTransaction(ctx, transactionID, tok, req.CredentialConfigurationID); err != nil {
+			ctx.Error(err)
+
+			return
+		}
+	}
+
+	if resp.Success() && credentialIssued(body) {
+		r.offerCredentialIssued(ctx)
+
+		body = r.withNotification(ctx, body, req.CredentialConfigurationID)
+	}
+
+	ctx.Raw(body)
+	ctx.StatusCode(resp.StatusCode())
+}
+
+// contentTypeJWT is the content type of the encrypted credential request.
+const contentTypeJWT = "application/jwt"
+
+// encryptedRequest checks if the request body is encrypted JWT that can be decrypted only by the upstream issuer.
+func encryptedRequest(ctx *azugo.Context) bool {
+	mediaType, _, err := mime.ParseMediaType(ctx.Header.Get(fasthttp.HeaderContentType))
+
+	return err == nil && mediaType == contentTypeJWT
+}
+
+// forward request to the upstream issuer endpoint. Response is written directly to the context response.
+func (r *router) forward(ctx *azugo.Context, path string) (*http.Response, error) {
 	client := ctx.HTTPClient()
 
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 	ctx.Request().CopyTo(req)
 
-	req.SetRequestURI(r.Config().Issuer.APIURL + "/credential")
+	req.SetRequestURI(r.Config().Issuer.APIURL + path)
 	req.Header.SetMethod("POST")
 
 	resp := &ctx.Context().Response
@@ -170,15 +256,54 @@ func (r *router) credential(ctx *azugo.Context) {
 		Response: resp,
 	}
 
-	err := client.Do(httpRequest, httpResponse)
+	if err := client.Do(httpRequest, httpResponse); err != nil {
+		return nil, err
+	}
+
+	return httpResponse, nil
+}
+
+// offerCredentialIssued updates state of the credential offer the access token was issued for
+// and removes cached source registry data of the issued credential.
+// Failure to track offer state must not fail the credential issuance.
+func (r *router) offerCredentialIssued(ctx *azugo.Context) {
+	personCode, requestType, err := r.Issuer().OfferCredentialIssued(ctx, openid4vci.AccessTokenHash(accessToken(ctx)))
 	if err != nil {
-		ctx.Error(err)
+		ctx.Log().Error("failed to update credential offer state", zap.Error(err))
+
+		return
+	}
 
+	if personCode == "" || requestType == "" {
 		return
 	}
 
-	ctx.Raw(httpResponse.Body())
-	ctx.StatusCode(httpResponse.StatusCode())
+	if err := r.Credentials().Invalidate(ctx, personCode, requestType); err != nil {
+		ctx.Log().Error("failed to invalidate cached source registry data", zap.Error(err))
+	}
+}
+
+// errorResponse writes OpenID4VCI error response.
+func errorResponse(ctx *azugo.Context, code string) {
+	ctx.StatusCode(fasthttp.StatusBadRequest)
+	ctx.JSON(struct {
+		Error string `json:"error"`
+	}{
+		Error: code,
+	})
+}
+
+// accessToken returns access token from the authorization header.
+func accessToken(ctx *azugo.Context) string {
+	auth := ctx.Header.Get(fasthttp.HeaderAuthorization)
+
+	if tok, ok := strings.CutPrefix(auth, "Bearer "); ok {
+		return tok
+	}
+
+	tok, _ := strings.CutPrefix(auth, "DPoP ")
+
+	return tok
 }
 
 func (r *router) token(ctx *azugo.Context) {
@@ -235,17 +360,45 @@ func (r *router) token(ctx *azugo.Context) {
 
 	data["pre-authorized_code"] = []string{preAuthorizedCode}
 
-	code, _ := ctx.Form.String("tx_code")
-	if code == "" {
-		code, err = r.App.Issuer().GetTXCode(ctx, preAuthorizedCode)
+	// Wallet instance is bound to the access token only if the wallet authenticates with the client attestation
+	var instanceID string
+
+	if att := ctx.Header.Get(headerClientAttestation); att != "" {
+		instanceID, err = r.OpenID4VCI().VerifyClientAttestation(ctx, att, ctx.Header.Get(headerClientAttestationPoP))
 		if err != nil {
+			d := azugo.BadRequestError{}
+			if errors.As(err, &d) || errors.Is(err, http.NotFoundError{}) {
+				ctx.Log().Debug("invalid client attestation", zap.Error(err))
+				errorResponse(ctx, "invalid_client")
+
+				return
+			}
+
 			ctx.Error(err)
 
 			return
 		}
 	}
 
-	data["tx_code"] = []string{code}
+	code, _ := ctx.Form.String("tx_code")
+
+	code, err = r.Issuer().RedeemTXCode(ctx, preAuthorizedCode, code)
+	if err != nil {
+		// Offer has been cancelled, expired, locked or transaction code is invalid
+		if errors.Is(err, walletissuer.ErrInvalidGrant) {
+			errorResponse(ctx, "invalid_grant")
+
+			return
+		}
+
+		ctx.Error(err)
+
+		return
+	}
+
+	if code != "" {
+		data["tx_code"] = []string{code}
+	}
 
 	res, err := client.PostForm("/token", data)
 	if err != nil {
@@ -263,5 +416,19 @@ func (r *router) token(ctx *azugo.Context) {
 		return
 	}
 
+	if data, ok := jsonData.(map[string]any); ok {
+		if tok, ok := data["access_token"].(string); ok && tok != "" {
+			if err := r.OpenID4VCI().BindAccessToken(ctx, tok, instanceID, res); err != nil {
+				ctx.Error(err)
+
+				return
+			}
+
+			if err := r.Issuer().OfferTokenIssued(ctx, preAuthorizedCode, openid4vci.AccessTokenHash(tok)); err != nil {
+				ctx.Log().Error("failed to update credential offer state", zap.Error(err))
+			}
+		}
+	}
+
 	ctx.JSON(jsonData)
 }
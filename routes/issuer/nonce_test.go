@@ -5,6 +5,8 @@ package issuer
 import (
 	"testing"
 
+	"git.zzdats.lv/edim/api-wallet/mock/idauthtest"
+
 	"azugo.io/azugo"
 	"github.com/go-quicktest/qt"
 	"github.com/goccy/go-json"
@@ -12,15 +14,17 @@ import (
 )
 
 func TestNonce_Request(t *testing.T) {
-	// TODO: Needs mock IDAuth server
-	t.SkipNow()
+	app, a, idp := testApp(t)
 
-	app, a := testApp(t)
+	tok := idp.AddUser("32345678901")
+	u, _ := idp.User(tok)
 
 	app.Start(t)
 	defer app.Stop()
 
-	resp, err := app.TestClient().Post("/nonce", nil)
+	client := app.TestClient()
+
+	resp, err := client.Post("/nonce", nil, client.WithHeader(fasthttp.HeaderAuthorization, idauthtest.Authorization(tok)))
 	qt.Assert(t, qt.IsNil(err))
 	qt.Assert(t, qt.Equals(resp.StatusCode(), fasthttp.StatusOK))
 
@@ -48,22 +52,57 @@ func TestNonce_Request(t *testing.T) {
 		ctx.StatusCode(fasthttp.StatusOK)
 	})
 
-	resp, _ = app.TestClient().Get("/test")
+	resp, err = app.TestClient().Get("/test")
+	qt.Assert(t, qt.IsNil(err))
 	qt.Assert(t, qt.Equals(resp.StatusCode(), fasthttp.StatusOK))
 
+	buf, err = resp.BodyUncompressed()
+	fasthttp.ReleaseResponse(resp)
+
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(string(buf), u.SessionID))
+}
+
+func TestNonce_Anonymous(t *testing.T) {
+	app, _, _ := testApp(t)
+
+	app.Start(t)
+	defer app.Stop()
+
+	resp, err := app.TestClient().Post("/nonce", nil)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(resp.StatusCode(), fasthttp.StatusOK))
+	fasthttp.ReleaseResponse(resp)
+}
+
+func TestNonce_InactiveSession(t *testing.T) {
+	app, _, idp := testApp(t)
+
+	tok := idp.AddUser("32345678901", idauthtest.Inactive())
+
+	app.Start(t)
+	defer app.Stop()
+
+	client := app.TestClient()
+
+	resp, err := client.Post("/nonce", nil, client.WithHeader(fasthttp.HeaderAuthorization, idauthtest.Authorization(tok)))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(resp.StatusCode(), fasthttp.StatusUnauthorized))
 	fasthttp.ReleaseResponse(resp)
 }
 
 func TestNonce_ReplyAttack(t *testing.T) {
-	// TODO: Needs mock IDAuth server
-	t.SkipNow()
+	app, a, idp := testApp(t)
 
-	app, a := testApp(t)
+	tok := idp.AddUser("32345678901")
+	u, _ := idp.User(tok)
 
 	app.Start(t)
 	defer app.Stop()
 
-	resp, err := app.TestClient().Post("/nonce", nil)
+	client := app.TestClient()
+
+	resp, err := client.Post("/nonce", nil, client.WithHeader(fasthttp.HeaderAuthorization, idauthtest.Authorization(tok)))
 	qt.Assert(t, qt.IsNil(err))
 	qt.Assert(t, qt.Equals(resp.StatusCode(), fasthttp.StatusOK))
 
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package issuer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"git.zzdats.lv/edim/api-wallet/openid4vci"
+
+	"azugo.io/azugo"
+	"azugo.io/core/http"
+	"github.com/oklog/ulid/v2"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	headerClientAttestation    = "OAuth-Client-Attestation"
+	headerClientAttestationPoP = "OAuth-Client-Attestation-PoP"
+)
+
+// withNotification adds notification ID to the credential response and records it for the wallet instance
+// the access token was issued to. Notification ID is not added if the access token has no bound wallet instance
+// as such notifications would be rejected. Credential is already issued by the upstream issuer, so failure to
+// record the notification must not fail the issuance and the response is returned unchanged.
+func (r *router) withNotification(ctx *azugo.Context, body []byte, credentialConfigurationID string) []byte {
+	tok := accessToken(ctx)
+
+	instanceID, err := r.OpenID4VCI().WalletInstanceID(ctx, tok)
+	if err != nil {
+		ctx.Log().Error("failed to get wallet instance of the access token", zap.Error(err))
+
+		return body
+	}
+
+	if instanceID == "" {
+		return body
+	}
+
+	res := make(map[string]json.RawMessage)
+
+	if err := json.Unmarshal(body, &res); err != nil {
+		ctx.Log().Error("failed to parse credential response", zap.Error(err))
+
+		return body
+	}
+
+	var notificationID string
+
+	if v, ok := res["notification_id"]; ok {
+		_ = json.Unmarshal(v, &notificationID)
+	}
+
+	resp := body
+
+	if notificationID == "" {
+		notificationID = ulid.Make().String()
+
+		if resp, err = setNotificationID(body, notificationID); err != nil {
+			ctx.Log().Error("failed to add notification ID to credential response", zap.Error(err))
+
+			return body
+		}
+	}
+
+	if err := r.OpenID4VCI().CreateNotification(ctx, tok, &openid4vci.Notification{
+		NotificationID:            notificationID,
+		InstanceID:                instanceID,
+		CredentialConfigurationID: credentialConfigurationID,
+	}); err != nil {
+		ctx.Log().Error("failed to create credential notification", zap.Error(err))
+
+		return body
+	}
+
+	return resp
+}
+
+// setNotificationID appends notification ID to the JSON object without changing the other fields.
+func setNotificationID(body []byte, notificationID string) ([]byte, error) {
+	obj := bytes.TrimSpace(body)
+	if len(obj) < 2 || obj[0] != '{' || obj[len(obj)-1] != '}' {
+		return nil, errors.New("credential response is not JSON object")
+	}
+
+	v, err := json.Marshal(notificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := bytes.TrimSpace(obj[1 : len(obj)-1])
+
+	buf := make([]byte, 0, len(obj)+len(v)+20)
+	buf = append(buf, '{')
+	buf = append(buf, fields...)
+
+	if len(fields) > 0 {
+		buf = append(buf, ',')
+	}
+
+	buf = append(buf, `"notification_id":`...)
+	buf = append(buf, v...)
+	buf = append(buf, '}')
+
+	return buf, nil
+}
+
+// @operationId Notification
+// @title Credential notification
+// @description Receives notification from the wallet about the issued credential being accepted, failed or deleted.
+// @success 204 {empty} "No content"
+// @failure 400 string string "Bad request"
+// @failure 401 {empty} "Unauthorized"
+// @failure 500 string string "Internal server error"
+// @resource Issuer
+// @route /notification [post].
+func (r *router) notification(ctx *azugo.Context) {
+	tok := accessToken(ctx)
+	if tok == "" {
+		ctx.Error(http.UnauthorizedError{})
+
+		return
+	}
+
+	req := &openid4vci.NotificationRequest{}
+
+	if err := ctx.Body.JSON(req); err != nil || req.NotificationID == "" || !req.Event.Valid() {
+		errorResponse(ctx, "invalid_notification_request")
+
+		return
+	}
+
+	// Notifications are accepted only from the wallet instance that has authenticated when requesting the token
+	instanceID, err := r.OpenID4VCI().WalletInstanceID(ctx, tok)
+	if err != nil {
+		ctx.Error(err)
+
+		return
+	}
+
+	if instanceID == "" {
+		ctx.Error(http.UnauthorizedError{})
+
+		return
+	}
+
+	if err := r.OpenID4VCI().Notify(ctx, tok, instanceID, req); err != nil {
+		if errors.Is(err, http.NotFoundError{}) {
+			errorResponse(ctx, "invalid_notification_id")
+
+			return
+		}
+
+		ctx.Error(err)
+
+		return
+	}
+
+	ctx.StatusCode(fasthttp.StatusNoContent)
+}
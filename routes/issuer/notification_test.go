@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package issuer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	wallet "git.zzdats.lv/edim/api-wallet"
+	"git.zzdats.lv/edim/api-wallet/mock/jsondbtest"
+
+	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
+)
+
+func TestNotification(t *testing.T) {
+	store := jsondbtest.New()
+
+	app, a, _ := testApp(t, wallet.WithStore(store), upstreamIssuer(t, credentialIssuer))
+
+	ctx := context.Background()
+
+	qt.Assert(t, qt.IsNil(a.OpenID4VCI().BindAccessToken(ctx, "access-token", "instance-1", []byte(`{}`))))
+	qt.Assert(t, qt.IsNil(a.OpenID4VCI().BindAccessToken(ctx, "other-token", "instance-2", []byte(`{}`))))
+	qt.Assert(t, qt.IsNil(a.OpenID4VCI().BindAccessToken(ctx, "anonymous-token", "", []byte(`{}`))))
+
+	app.Start(t)
+	defer app.Stop()
+
+	status, body := postJSON(t, app, "/credential", "access-token", `{"credential_configuration_id":"eu.europa.ec.eudi.pid_mdoc"}`)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK))
+
+	res := struct {
+		NotificationID string `json:"notification_id"`
+	}{}
+
+	qt.Assert(t, qt.IsNil(json.Unmarshal([]byte(body), &res)))
+
+	n, ok := store.Notification(res.NotificationID)
+	qt.Assert(t, qt.IsTrue(ok))
+	qt.Check(t, qt.Equals(n.InstanceID, "instance-1"))
+	qt.Check(t, qt.Equals(n.CredentialConfigurationID, "eu.europa.ec.eudi.pid_mdoc"))
+
+	tests := []struct {
+		name   string
+		token  string
+		req    string
+		status int
+		error  string
+	}{
+		{
+			name:   "missing access token",
+			req:    `{"notification_id":"` + res.NotificationID + `","event":"credential_accepted"}`,
+			status: fasthttp.StatusUnauthorized,
+		},
+		{
+			name:   "unknown access token",
+			token:  "unknown-token",
+			req:    `{"notification_id":"` + res.NotificationID + `","event":"credential_accepted"}`,
+			status: fasthttp.StatusUnauthorized,
+		},
+		{
+			name:   "access token without wallet instance",
+			token:  "anonymous-token",
+			req:    `{"notification_id":"` + res.NotificationID + `","event":"credential_accepted"}`,
+			status: fasthttp.StatusUnauthorized,
+		},
+		{
+			name:   "access token of other wallet instance",
+			token:  "other-token",
+			req:    `{"notification_id":"` + res.NotificationID + `","event":"credential_accepted"}`,
+			status: fasthttp.StatusBadRequest,
+			error:  "invalid_notification_id",
+		},
+		{
+			name:   "unknown notification ID",
+			token:  "access-token",
+			req:    `{"notification_id":"unknown","event":"credential_accepted"}`,
+			status: fasthttp.StatusBadRequest,
+			error:  "invalid_notification_id",
+		},
+		{
+			name:   "invalid event",
+			token:  "access-token",
+			req:    `{"notification_id":"` + res.NotificationID + `","event":"credential_revoked"}`,
+			status: fasthttp.StatusBadRequest,
+			error:  "invalid_notification_request",
+		},
+		{
+			name:   "missing notification ID",
+			token:  "access-token",
+			req:    `{"event":"credential_accepted"}`,
+			status: fasthttp.StatusBadRequest,
+			error:  "invalid_notification_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := postJSON(t, app, "/notification", tt.token, tt.req)
+			qt.Check(t, qt.Equals(status, tt.status))
+
+			if tt.error != "" {
+				qt.Check(t, qt.JSONEquals([]byte(body), map[string]any{"error": tt.error}))
+			}
+		})
+	}
+
+	n, _ = store.Notification(res.NotificationID)
+	qt.Check(t, qt.HasLen(n.Events, 0))
+
+	status, _ = postJSON(t, app, "/notification", "access-token", `{"notification_id":"`+res.NotificationID+`","event":"credential_accepted"}`)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusNoContent))
+
+	n, _ = store.Notification(res.NotificationID)
+	qt.Check(t, qt.DeepEquals(n.Events, []string{"credential_accepted"}))
+}
+
+func TestNotification_CredentialResponse(t *testing.T) {
+	store := jsondbtest.New()
+
+	app, a, _ := testApp(t, wallet.WithStore(store), upstreamIssuer(t, credentialIssuer))
+
+	ctx := context.Background()
+
+	qt.Assert(t, qt.IsNil(a.OpenID4VCI().BindAccessToken(ctx, "access-token", "instance-1", []byte(`{}`))))
+	qt.Assert(t, qt.IsNil(a.OpenID4VCI().BindAccessToken(ctx, "anonymous-token", "", []byte(`{}`))))
+
+	app.Start(t)
+	defer app.Stop()
+
+	const (
+		req        = `{"credential_configuration_id":"eu.europa.ec.eudi.pid_mdoc"}`
+		credential = `{"credentials":[{"credential":"credential"}]}`
+	)
+
+	// Notification ID is added without changing the rest of the upstream issuer response
+	status, body := postJSON(t, app, "/credential", "access-token", req)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK))
+
+	notificationID, ok := strings.CutPrefix(body, `{"credentials":[{"credential":"credential"}],"notification_id":"`)
+	qt.Assert(t, qt.IsTrue(ok), qt.Commentf("%s", body))
+
+	_, ok = store.Notification(strings.TrimSuffix(notificationID, `"}`))
+	qt.Check(t, qt.IsTrue(ok))
+
+	// Notification ID is not returned if the wallet instance can not send notifications
+	status, body = postJSON(t, app, "/credential", "anonymous-token", req)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusOK))
+	qt.Check(t, qt.Equals(body, credential))
+
+	// Failure to record the notification does not fail the issued credential
+	store.Handle("wallet.create_credential_notification", func(context.Context, json.RawMessage) (any, error) {
+		return nil, errors.New("database is unavailable")
+	})
+
+	status, body = postJSON(t, app, "/credential", "access-token", req)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusOK))
+	qt.Check(t, qt.Equals(body, credential))
+}
+
+func TestSetNotificationID(t *testing.T) {
+	tests := []struct {
+		body string
+		res  string
+	}{
+		{body: `{}`, res: `{"notification_id":"n-1"}`},
+		{body: " {\"credential\": 12345678901234567890, \"b\": 1}\n", res: `{"credential": 12345678901234567890, "b": 1,"notification_id":"n-1"}`},
+	}
+
+	for _, tt := range tests {
+		res, err := setNotificationID([]byte(tt.body), "n-1")
+		qt.Assert(t, qt.IsNil(err))
+		qt.Check(t, qt.Equals(string(res), tt.res))
+	}
+
+	_, err := setNotificationID([]byte(`[]`), "n-1")
+	qt.Check(t, qt.ErrorMatches(err, "credential response is not JSON object"))
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package issuer
+
+import (
+	"azugo.io/azugo"
+)
+
+// @operationId GetCredentialOffer
+// @title Gets credential offer
+// @description Gets credential offer referenced by `credential_offer_uri`. Offer can be retrieved only once.
+// @param id path string true "Credential offer ID"
+// @success 200 object string "OK"
+// @failure 404 {empty} "Not found"
+// @failure 500 string string "Internal server error"
+// @resource Issuer
+// @route /credential-offer/{id} [get].
+func (r *router) credentialOffer(ctx *azugo.Context) {
+	offer, err := r.Issuer().CredentialOffer(ctx, ctx.Params.String("id"))
+	if err != nil {
+		ctx.Error(err)
+
+		return
+	}
+
+	ctx.Header.Set("Cache-Control", "no-store")
+	ctx.ContentType("application/json")
+	ctx.Raw(offer)
+}
@@ -20,7 +20,10 @@ func Bind(a *wallet.App, g azugo.Router) error {
 	g.Get("/.well-known/openid-credential-issuer", r.openIDCredentialIssuer)
 	g.Get("/.well-known/openid-configuration", r.openIDConfiguration)
 	g.Get("/.well-known/jwks", r.openIDJWKS)
+	g.Get("/credential-offer/{id}", r.credentialOffer)
 	g.Post("/credential", r.credential)
+	g.Post("/deferred_credential", r.deferredCredential)
+	g.Post("/notification", r.notification)
 	g.Post("/token", r.token)
 
 	// Nonce support
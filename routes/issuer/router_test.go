@@ -3,19 +3,44 @@
 package issuer
 
 import (
+	"net"
 	"testing"
 
 	wallet "git.zzdats.lv/edim/api-wallet"
+	"git.zzdats.lv/edim/api-wallet/mock/idauthtest"
 
 	"azugo.io/azugo"
 	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
 )
 
-func testApp(t testing.TB) (*azugo.TestApp, *wallet.App) {
-	app := wallet.TestApp(t)
+func testApp(t testing.TB, opts ...wallet.TestOption) (*azugo.TestApp, *wallet.App, *idauthtest.Server) {
+	idp := idauthtest.New(t)
+
+	app := wallet.TestApp(t, append([]wallet.TestOption{wallet.WithIDAuthURL(idp.URL())}, opts...)...)
 
 	err := Bind(app, app)
 	qt.Assert(t, qt.IsNil(err))
 
-	return azugo.NewTestApp(app.App), app
+	return azugo.NewTestApp(app.App), app, idp
+}
+
+// upstreamIssuer starts fake upstream issuer that responds to every request with the handler.
+func upstreamIssuer(t testing.TB, handler fasthttp.RequestHandler) wallet.TestOption {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	qt.Assert(t, qt.IsNil(err))
+
+	srv := &fasthttp.Server{Handler: handler}
+
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+
+	t.Cleanup(func() {
+		_ = srv.Shutdown()
+	})
+
+	return wallet.WithEnv("ISSUER_API_URL", "http://"+ln.Addr().String())
 }
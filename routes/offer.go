@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package routes
+
+import (
+	"strconv"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/models"
+
+	"azugo.io/azugo"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// offerStatusMaxWait is the maximum time the status request can wait for the offer state change.
+	offerStatusMaxWait = 30 * time.Second
+	// offerStatusPollInterval is the interval between offer state checks while waiting for the change.
+	offerStatusPollInterval = time.Second
+)
+
+// @operationId GetCredentialOfferStatus
+// @title Get credential offer status
+// @description Returns credential offer lifecycle state. When `state` and `wait` parameters are provided, waits up to `wait` seconds (maximum 30) for the offer to leave the provided state.
+// @param offerID path string true "Credential offer ID"
+// @param state query string false "Last known state. Options: `created`, `token_issued`, `credential_issued`, `expired`, `cancelled`"
+// @param wait query integer false "Number of seconds to wait for the state change"
+// @success 200 CredentialOfferStatus models.CredentialOfferStatus "Credential offer status"
+// @failure 401 {empty} "Unauthorized"
+// @failure 403 {empty} "Forbidden"
+// @failure 404 {empty} "Not found"
+// @failure 422 string string "Invalid request"
+// @failure 500 string string "Internal server error"
+// @resource QRCode
+// @route /1.0/offers/{offerID} [get].
+func (r *router) offerStatus(ctx *azugo.Context) {
+	personCode := ctx.User().ClaimValue("code")
+	if personCode == "" {
+		ctx.StatusCode(fasthttp.StatusUnauthorized)
+
+		return
+	}
+
+	offerID := ctx.Params.String("offerID")
+
+	var wait time.Duration
+
+	if w := ctx.Query.StringOptional("wait"); w != nil {
+		sec, err := strconv.Atoi(*w)
+		if err != nil || sec < 0 {
+			ctx.Error(azugo.ParamInvalidError{
+				Name: "wait",
+				Tag:  "invalid",
+				Err:  err,
+			})
+
+			return
+		}
+
+		wait = min(time.Duration(sec)*time.Second, offerStatusMaxWait)
+	}
+
+	status, err := r.Issuer().OfferStatus(ctx, offerID, personCode)
+	if err != nil {
+		ctx.Error(err)
+
+		return
+	}
+
+	state := ctx.Query.StringOptional("state")
+	if state == nil || wait == 0 {
+		ctx.JSON(status)
+
+		return
+	}
+
+	status, err = r.waitOfferStatus(ctx, status, *state, personCode, wait)
+	if err != nil {
+		ctx.Error(err)
+
+		return
+	}
+
+	ctx.JSON(status)
+}
+
+// waitOfferStatus polls credential offer status until it differs from the known state or wait time passes.
+func (r *router) waitOfferStatus(ctx *azugo.Context, status *models.CredentialOfferStatus, state, personCode string, wait time.Duration) (*models.CredentialOfferStatus, error) {
+	ticker := time.NewTicker(offerStatusPollInterval)
+	defer ticker.Stop()
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	for status.State == state {
+		select {
+		case <-ctx.Done():
+			return status, nil
+		case <-timeout.C:
+			return status, nil
+		case <-ticker.C:
+		}
+
+		var err error
+
+		status, err = r.Issuer().OfferStatus(ctx, status.OfferID, personCode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+// @operationId CancelCredentialOffer
+// @title Cancel credential offer
+// @description Cancels pending credential offer so that it can no longer be redeemed by the wallet.
+// @param offerID path string true "Credential offer ID"
+// @success 204 {empty} "No content"
+// @failure 401 {empty} "Unauthorized"
+// @failure 403 {empty} "Forbidden"
+// @failure 404 {empty} "Not found"
+// @failure 500 string string "Internal server error"
+// @resource QRCode
+// @route /1.0/offers/{offerID} [delete].
+func (r *router) cancelOffer(ctx *azugo.Context) {
+	personCode := ctx.User().ClaimValue("code")
+	if personCode == "" {
+		ctx.StatusCode(fasthttp.StatusUnauthorized)
+
+		return
+	}
+
+	if err := r.Issuer().CancelOffer(ctx, ctx.Params.String("offerID"), personCode); err != nil {
+		ctx.Error(err)
+
+		return
+	}
+
+	ctx.StatusCode(fasthttp.StatusNoContent)
+}
@@ -0,0 +1,354 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package routes
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	api "git.zzdats.lv/edim/api-wallet"
+	"git.zzdats.lv/edim/api-wallet/mock"
+	"git.zzdats.lv/edim/api-wallet/mock/jsondbtest"
+	"git.zzdats.lv/edim/api-wallet/models"
+
+	"azugo.io/azugo"
+	"azugo.io/core/http"
+	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
+)
+
+// testRequest sends request to the test application and returns response status code and body.
+func testRequest(t *testing.T, app *azugo.TestApp, method, path, token string, body []byte, header ...string) (int, []byte) {
+	t.Helper()
+
+	client := app.TestClient()
+
+	var opts []http.RequestOption
+	if token != "" {
+		opts = append(opts, client.WithHeader(fasthttp.HeaderAuthorization, "Bearer "+token))
+	}
+
+	for i := 0; i+1 < len(header); i += 2 {
+		opts = append(opts, client.WithHeader(header[i], header[i+1]))
+	}
+
+	var (
+		resp *fasthttp.Response
+		err  error
+	)
+
+	switch method {
+	case fasthttp.MethodGet:
+		resp, err = client.Get(path, opts...)
+	case fasthttp.MethodPost:
+		resp, err = client.Post(path, body, opts...)
+	case fasthttp.MethodDelete:
+		resp, err = client.Delete(path, opts...)
+	default:
+		t.Fatalf("unsupported method %s", method)
+	}
+
+	qt.Assert(t, qt.IsNil(err))
+
+	defer fasthttp.ReleaseResponse(resp)
+
+	buf, err := resp.BodyUncompressed()
+	qt.Assert(t, qt.IsNil(err))
+
+	return resp.StatusCode(), buf
+}
+
+// testOffer is the credential offer generated for the person.
+type testOffer struct {
+	ID                string
+	TXCode            string
+	PreAuthorizedCode string
+}
+
+func createOffer(t *testing.T, app *azugo.TestApp, token, requestType string) testOffer {
+	t.Helper()
+
+	offer := createOfferPath(t, app, token, "/1.0/"+requestType)
+	qt.Assert(t, qt.Not(qt.Equals(offer.TXCode, "")))
+
+	return offer
+}
+
+// createOfferPath creates credential offer using the endpoint path. Transaction code is empty if it is
+// filled in automatically.
+func createOfferPath(t *testing.T, app *azugo.TestApp, token, path string) testOffer {
+	t.Helper()
+
+	status, body := testRequest(t, app, fasthttp.MethodPost, path, token, nil)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK), qt.Commentf("%s", body))
+
+	res := models.GenerateCredentialOffer{}
+	qt.Assert(t, qt.IsNil(json.Unmarshal(body, &res)))
+
+	u, err := url.Parse(res.URLData)
+	qt.Assert(t, qt.IsNil(err))
+
+	uri, err := url.Parse(u.Query().Get("credential_offer_uri"))
+	qt.Assert(t, qt.IsNil(err))
+
+	status, body = testRequest(t, app, fasthttp.MethodGet, uri.Path, "", nil)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK))
+
+	offer := models.CredentialOffer{}
+	qt.Assert(t, qt.IsNil(json.Unmarshal(body, &offer)))
+
+	o := testOffer{
+		ID:                res.OfferID,
+		PreAuthorizedCode: offer.Grants.PreAuthorizedCode.PreAuthorizedCode,
+	}
+
+	if res.TXCode != nil {
+		o.TXCode = strconv.Itoa(*res.TXCode)
+	}
+
+	return o
+}
+
+func redeemOffer(t *testing.T, app *azugo.TestApp, offer testOffer) (int, map[string]any) {
+	t.Helper()
+
+	form := url.Values{
+		"grant_type":          {"urn:ietf:params:oauth:grant-type:pre-authorized_code"},
+		"client_id":           {"wallet"},
+		"pre-authorized_code": {offer.PreAuthorizedCode},
+	}
+
+	if offer.TXCode != "" {
+		form.Set("tx_code", offer.TXCode)
+	}
+
+	status, body := testRequest(t, app, fasthttp.MethodPost, "/token", "", []byte(form.Encode()),
+		fasthttp.HeaderContentType, "application/x-www-form-urlencoded")
+
+	res := make(map[string]any)
+	qt.Assert(t, qt.IsNil(json.Unmarshal(body, &res)))
+
+	return status, res
+}
+
+func offerState(t *testing.T, app *azugo.TestApp, token, path string) string {
+	t.Helper()
+
+	status, body := testRequest(t, app, fasthttp.MethodGet, path, token, nil)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK))
+
+	res := models.CredentialOfferStatus{}
+	qt.Assert(t, qt.IsNil(json.Unmarshal(body, &res)))
+
+	return res.State
+}
+
+func TestCredentialOfferLifecycle(t *testing.T) {
+	store := jsondbtest.New()
+
+	app, _ := testApp(t, append(withUpstreams(t), api.WithStore(store))...)
+
+	app.Start(t)
+	defer app.Stop()
+
+	person := mock.PersonCodeFull
+
+	offer := createOffer(t, app, person, "pid")
+
+	// Pre-authorized code is stored only as a hash
+	stored, ok := store.CredentialOffer(offer.ID)
+	qt.Assert(t, qt.IsTrue(ok))
+	qt.Check(t, qt.Equals(stored.State, jsondbtest.OfferStateCreated))
+	qt.Check(t, qt.Not(qt.Equals(stored.PreAuthorizedCodeHash, "")))
+
+	buf, err := json.Marshal(stored)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.IsFalse(strings.Contains(string(buf), offer.PreAuthorizedCode)))
+
+	statusPath := "/1.0/offers/" + offer.ID
+
+	qt.Check(t, qt.Equals(offerState(t, app, person, statusPath), "created"))
+
+	// Offer of other person is not found
+	status, _ := testRequest(t, app, fasthttp.MethodGet, statusPath, mock.PersonCodeDiplomas, nil)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusNotFound))
+
+	// Long polling returns as soon as the pre-authorized code is redeemed
+	waited := make(chan string, 1)
+	start := time.Now()
+
+	go func() {
+		client := app.TestClient()
+
+		res := models.CredentialOfferStatus{}
+
+		resp, err := client.Get(statusPath+"?state=created&wait=10", client.WithHeader(fasthttp.HeaderAuthorization, "Bearer "+person))
+		if err == nil {
+			buf, _ := resp.BodyUncompressed()
+			_ = json.Unmarshal(buf, &res)
+
+			fasthttp.ReleaseResponse(resp)
+		}
+
+		waited <- res.State
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	status, tok := redeemOffer(t, app, offer)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK))
+
+	qt.Check(t, qt.Equals(<-waited, "token_issued"))
+	qt.Check(t, qt.IsTrue(time.Since(start) < 5*time.Second))
+
+	accessToken, _ := tok["access_token"].(string)
+	qt.Assert(t, qt.Not(qt.Equals(accessToken, "")))
+
+	status, body := testRequest(t, app, fasthttp.MethodPost, "/credential", accessToken,
+		[]byte(`{"credential_configuration_id":"eu.europa.ec.eudi.pid_mdoc"}`),
+		fasthttp.HeaderContentType, "application/json")
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK), qt.Commentf("%s", body))
+
+	qt.Check(t, qt.Equals(offerState(t, app, person, statusPath), "credential_issued"))
+
+	// Long polling returns current state when wait time passes without change
+	start = time.Now()
+
+	qt.Check(t, qt.Equals(offerState(t, app, person, statusPath+"?state=credential_issued&wait=1"), "credential_issued"))
+	qt.Check(t, qt.IsTrue(time.Since(start) >= time.Second))
+
+	// Issued offer can not be cancelled
+	status, _ = testRequest(t, app, fasthttp.MethodDelete, statusPath, person, nil)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusNotFound))
+
+	status, _ = testRequest(t, app, fasthttp.MethodGet, statusPath+"?state=created&wait=invalid", person, nil)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusUnprocessableEntity))
+}
+
+func TestCredentialOfferCancel(t *testing.T) {
+	store := jsondbtest.New()
+
+	app, _ := testApp(t, append(withUpstreams(t), api.WithStore(store))...)
+
+	app.Start(t)
+	defer app.Stop()
+
+	person := mock.PersonCodeFull
+
+	first := createOffer(t, app, person, "pid")
+	second := createOffer(t, app, person, "pid")
+
+	// New offer replaces previous offer of the same credential type
+	qt.Check(t, qt.Equals(offerState(t, app, person, "/1.0/offers/"+first.ID), "cancelled"))
+
+	status, res := redeemOffer(t, app, first)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+	qt.Check(t, qt.Equals(res["error"], "invalid_grant"))
+
+	status, _ = testRequest(t, app, fasthttp.MethodDelete, "/1.0/offers/"+second.ID, mock.PersonCodeDiplomas, nil)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusNotFound))
+
+	status, _ = testRequest(t, app, fasthttp.MethodDelete, "/1.0/offers/"+second.ID, person, nil)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusNoContent))
+
+	qt.Check(t, qt.Equals(offerState(t, app, person, "/1.0/offers/"+second.ID), "cancelled"))
+
+	status, res = redeemOffer(t, app, second)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+	qt.Check(t, qt.Equals(res["error"], "invalid_grant"))
+
+	status, _ = testRequest(t, app, fasthttp.MethodDelete, "/1.0/offers/"+second.ID, person, nil)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusNotFound))
+}
+
+// wrongTXCode returns transaction code that differs from the code of the offer.
+func wrongTXCode(offer testOffer) testOffer {
+	n, _ := strconv.Atoi(offer.TXCode)
+
+	offer.TXCode = strconv.Itoa(n%900000 + 100001)
+
+	return offer
+}
+
+func TestTXCode(t *testing.T) {
+	app, _ := testApp(t, append(withUpstreams(t),
+		api.WithStore(jsondbtest.New()),
+		api.WithEnv("ISSUER_TX_CODE_MAX_ATTEMPTS", "3"),
+	)...)
+
+	app.Start(t)
+	defer app.Stop()
+
+	person := mock.PersonCodeFull
+
+	// Wrong transaction code does not invalidate the offer until attempts are exhausted
+	offer := createOffer(t, app, person, "pid")
+
+	for range 2 {
+		status, res := redeemOffer(t, app, wrongTXCode(offer))
+		qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+		qt.Check(t, qt.Equals(res["error"], "invalid_grant"))
+	}
+
+	status, res := redeemOffer(t, app, offer)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusOK))
+	qt.Check(t, qt.Not(qt.Equals(res["access_token"], nil)))
+
+	// Missing transaction code is a failed attempt
+	offer = createOffer(t, app, person, "mdl")
+
+	status, res = redeemOffer(t, app, testOffer{PreAuthorizedCode: offer.PreAuthorizedCode})
+	qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+	qt.Check(t, qt.Equals(res["error"], "invalid_grant"))
+
+	// Pre-authorized code is locked after the maximum number of failed attempts
+	for range 2 {
+		status, res = redeemOffer(t, app, wrongTXCode(offer))
+		qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+		qt.Check(t, qt.Equals(res["error"], "invalid_grant"))
+	}
+
+	status, res = redeemOffer(t, app, offer)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+	qt.Check(t, qt.Equals(res["error"], "invalid_grant"))
+
+	// Unknown pre-authorized code
+	status, res = redeemOffer(t, app, testOffer{PreAuthorizedCode: "unknown", TXCode: "123456"})
+	qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+	qt.Check(t, qt.Equals(res["error"], "invalid_grant"))
+}
+
+func TestTXCode_Autofill(t *testing.T) {
+	app, _ := testApp(t, append(withUpstreams(t),
+		api.WithStore(jsondbtest.New()),
+		api.WithEnv("ISSUER_TX_CODE_POLICY", "autofill"),
+	)...)
+
+	app.Start(t)
+	defer app.Stop()
+
+	person := mock.PersonCodeFull
+
+	// Transaction code is filled in automatically for trusted flow
+	offer := createOfferPath(t, app, person, "/1.0/pid")
+	qt.Check(t, qt.Equals(offer.TXCode, ""))
+
+	status, res := redeemOffer(t, app, offer)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusOK))
+	qt.Check(t, qt.Not(qt.Equals(res["access_token"], nil)))
+
+	// Transaction code is still required for the portal flow
+	offer = createOfferPath(t, app, person, "/1.0/internal/pid")
+	qt.Assert(t, qt.Not(qt.Equals(offer.TXCode, "")))
+
+	status, res = redeemOffer(t, app, testOffer{PreAuthorizedCode: offer.PreAuthorizedCode})
+	qt.Check(t, qt.Equals(status, fasthttp.StatusBadRequest))
+	qt.Check(t, qt.Equals(res["error"], "invalid_grant"))
+
+	status, _ = redeemOffer(t, app, offer)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusOK))
+}
@@ -5,108 +5,100 @@ package routes
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"git.zzdats.lv/edim/api-wallet/models"
 	"git.zzdats.lv/edim/api-wallet/routes/object"
 	"git.zzdats.lv/edim/api-wallet/routes/request"
 
 	"azugo.io/azugo"
-	"azugo.io/core/http"
 	"github.com/valyala/fasthttp"
 )
 
+// Response headers that contain credential offer details when credential offer is returned as QR code image.
+const (
+	headerTXCode  = "X-Tx-Code"
+	headerOfferID = "X-Offer-Id"
+)
+
 func (r *router) qrCodeInternal(ctx *azugo.Context) {
 	requestType := ctx.Params.String("requestType")
 
-	r.qrCodeData(ctx, requestType, true)
+	r.qrCodeData(ctx, requestType, false)
 }
 
 // @operationId GenerateQRCode
 // @title Generate QR code
 // @description Generates qr code
-// @param requestType path string true "options: `pid`, `rtu`, `mdl`"
+// @param requestType path string true "Credential request type"
+// @param diploma query string false "Diploma ID for `rtu` credential. Required if person has more than one diploma"
+// @param format query string false "Response format. Options: `json`, `png`, `svg`. Defaults to value negotiated by `Accept` header or `json`"
 // @success 200 GenerateCredentialOfferResponse models.GenerateCredentialOfferResponse "pid result"
 // @failure 400 string string "Bad request"
 // @failure 401 {empty} "Unauthorized"
-// @failure 403 {empty} "Forbidden"
-// @failure 422 string string "Invalid request"
+// @failure 403 SourceError models.SourceError "Forbidden or person is deceased or blocked"
+// @failure 404 SourceError models.SourceError "Person not found"
+// @failure 422 SourceError models.SourceError "Document expired or invalid source registry data"
 // @failure 500 string string "Internal server error"
+// @failure 502 SourceError models.SourceError "Source registry rejected the call"
+// @failure 503 SourceError models.SourceError "Source registry temporarily unavailable"
 // @resource QRCode
 // @route /1.0/{requestType} [post].
 func (r *router) qrCode(ctx *azugo.Context) {
 	requestType := ctx.Params.String("requestType")
 
-	r.qrCodeData(ctx, requestType, false)
+	r.qrCodeData(ctx, requestType, true)
 }
 
-func (r *router) qrCodeData(ctx *azugo.Context, requestType string, showTXCode bool) {
+// qrCodeData generates credential offer. Transaction code can be filled in automatically only for trusted flows.
+func (r *router) qrCodeData(ctx *azugo.Context, requestType string, trusted bool) {
 	personCodeClaim := ctx.User().Claim("code")
-
-	var (
-		gcoReq any
-
-		err error
-	)
-
 	if len(personCodeClaim) == 0 || personCodeClaim[0] == "" {
 		ctx.StatusCode(fasthttp.StatusUnauthorized)
 
 		return
 	}
 
-	switch requestType {
-	case "pid":
-		gcoReq, err = r.getPIDData(ctx)
-		if err != nil {
-			if errors.Is(err, http.NotFoundError{}) {
-				ctx.Error(err)
-				ctx.Text("Data about person not found")
+	source, ok := r.Credentials().Source(requestType)
+	if !ok {
+		ctx.StatusCode(fasthttp.StatusNotFound)
 
-				return
-			}
+		return
+	}
 
-			ctx.Error(fmt.Errorf("failed to call PID: %w", err))
+	form, err := r.Credentials().Form(ctx, source, personCodeClaim[0])
+	if err != nil {
+		perr := azugo.ParamInvalidError{}
+		if errors.As(err, &perr) {
+			ctx.Error(err)
 
 			return
 		}
-	case "rtu":
-		gcoReq, err = r.getRTUData(ctx)
-		if err != nil {
-			if errors.Is(err, http.NotFoundError{}) {
-				ctx.Error(err)
-				ctx.Text("Data about education not found")
-
-				return
-			}
-
-			ctx.Error(fmt.Errorf("failed to call RTU: %w", err))
 
+		if sourceError(ctx, source, err) {
 			return
 		}
-	case "mdl":
-		gcoReq, err = r.getMDLData(ctx)
-		if err != nil {
-			if errors.Is(err, http.NotFoundError{}) {
-				ctx.Error(err)
-				ctx.Text("Data about drivers licence not found")
-
-				return
-			}
 
-			ctx.Error(fmt.Errorf("failed to call MDL: %w", err))
-
-			return
-		}
-	default:
-		ctx.StatusCode(fasthttp.StatusNotFound)
+		ctx.Error(fmt.Errorf("%s: %w", source.Errors().Failed, err))
 
 		return
 	}
 
+	gcoReq := &request.CredentialOfferRequest{
+		GenericCredentialOffer: object.GenericCredentialOffer{
+			CredentialIDS:      source.ConfigurationIDs(),
+			CodeGrant:          "pre_auth_code",
+			CredentialOfferURI: r.Config().QRAPIDeepLink + "://",
+			ReturnInHTML:       false,
+		},
+		Form: form,
+	}
+
 	issuerRes := &models.GenerateCredentialOffer{}
 	client := ctx.HTTPClient().WithBaseURL(r.Config().Issuer.APIURL)
 
-	err = client.PostJSON("/generate_credential_offer", &gcoReq, issuerRes)
+	err = client.PostJSON("/generate_credential_offer", gcoReq, issuerRes)
 	if err != nil {
 		ctx.Error(err)
 
@@ -116,97 +108,76 @@ func (r *router) qrCodeData(ctx *azugo.Context, requestType string, showTXCode b
 	// ignore lint here, we need this to have reference in swagger
 	res := &models.GenerateCredentialOffer{} //nolint:ineffassign,wastedassign
 
-	res, err = r.App.Issuer().ParseCredentialOffer(ctx, *issuerRes, showTXCode)
+	res, err = r.App.Issuer().ParseCredentialOffer(ctx, *issuerRes, personCodeClaim[0], requestType, trusted)
 	if err != nil {
 		ctx.Error(err)
 
 		return
 	}
 
-	ctx.JSON(res)
+	r.credentialOfferResponse(ctx, res)
 }
 
-func (r *router) getPIDData(ctx *azugo.Context) (any, error) {
-	// call fpris and get person data
-	fprisRes, err := r.App.FprisAPIClient().GetFprisData(ctx)
-	if err != nil {
-		return nil, err
-	}
+// credentialOfferResponse writes credential offer as JSON or as QR code image depending on the requested format.
+func (r *router) credentialOfferResponse(ctx *azugo.Context, res *models.GenerateCredentialOffer) {
+	var (
+		buf         []byte
+		contentType string
+		err         error
+	)
 
-	if fprisRes == nil {
-		return nil, http.NotFoundError{Resource: "person"}
-	}
+	switch qrCodeFormat(ctx) {
+	case "json":
+		ctx.JSON(res)
 
-	gcoReq := &request.PIDCredentialOfferRequest{
-		GenericCredentialOffer: object.GenericCredentialOffer{
-			CredentialIDS:      []string{"eu.europa.ec.eudi.pid_mdoc"},
-			CodeGrant:          "pre_auth_code",
-			CredentialOfferURI: r.Config().QRAPIDeepLink + "://",
-			ReturnInHTML:       false,
-		},
-		Form: &request.PIDCredentialOfferForm{},
-	}
+		return
+	case "png":
+		contentType = "image/png"
+		buf, err = r.QRCode().PNG(res.URLData)
+	case "svg":
+		contentType = "image/svg+xml"
+		buf, err = r.QRCode().SVG(res.URLData)
+	default:
+		ctx.Error(azugo.ParamInvalidError{
+			Name: "format",
+			Tag:  "oneof",
+		})
 
-	gcoReq.Form.FamilyName = fprisRes.FamilyName
-	gcoReq.Form.GivenName = fprisRes.GivenName
-	gcoReq.Form.BirthDate = fprisRes.BirthDate
-	gcoReq.Form.PersonalAdministrativeNumber = fprisRes.PersonalAdministrativeNumber
-	gcoReq.Form.BirthPlace = fprisRes.BirthPlace
-	gcoReq.Form.BirthCountry = fprisRes.BirthCountry
-	gcoReq.Form.EstimatedIssuanceDate = fprisRes.IssuanceDate
-	gcoReq.Form.EstimatedExpiryDate = fprisRes.ExpiryDate
-	gcoReq.Form.IssuingCountry = fprisRes.IssuingCountry
-	gcoReq.Form.IssuingAuthority = fprisRes.IssuingAuthority
-	gcoReq.Form.AgeOver18 = fprisRes.AgeOver18
-	gcoReq.Form.Nationality = fprisRes.Nationality
-
-	return gcoReq, nil
-}
+		return
+	}
 
-func (r *router) getRTUData(ctx *azugo.Context) (any, error) {
-	// call rtu and get diploma data
-	rtuRes, err := r.App.RTUAPIClient().GetRTUData(ctx)
 	if err != nil {
-		return nil, err
+		ctx.Error(fmt.Errorf("failed to render QR code: %w", err))
+
+		return
 	}
 
-	if rtuRes == nil {
-		return nil, http.NotFoundError{Resource: "person"}
+	if res.TXCode != nil {
+		ctx.Header.Set(headerTXCode, strconv.Itoa(*res.TXCode))
 	}
 
-	gcoReq := &request.RTUCredentialOfferRequest{
-		GenericCredentialOffer: object.GenericCredentialOffer{
-			CredentialIDS:      []string{"eu.europa.ec.eudi.rtu_diploma_mdoc"},
-			CodeGrant:          "pre_auth_code",
-			CredentialOfferURI: r.Config().QRAPIDeepLink + "://",
-			ReturnInHTML:       false,
-		},
-		Form: rtuRes,
+	if res.OfferID != "" {
+		ctx.Header.Set(headerOfferID, res.OfferID)
 	}
 
-	return gcoReq, nil
+	ctx.ContentType(contentType)
+	ctx.Raw(buf)
 }
 
-func (r *router) getMDLData(ctx *azugo.Context) (any, error) {
-	// call mdl and get driving license data
-	mdlRes, err := r.App.MDLAPIClient().GetMDLData(ctx)
-	if err != nil {
-		return nil, err
+// qrCodeFormat returns requested credential offer format from the query parameter or `Accept` header.
+func qrCodeFormat(ctx *azugo.Context) string {
+	if format := ctx.Query.StringOptional("format"); format != nil {
+		return strings.ToLower(*format)
 	}
 
-	if mdlRes == nil {
-		return nil, http.NotFoundError{Resource: "person"}
-	}
+	accept := ctx.Header.Get(fasthttp.HeaderAccept)
 
-	gcoReq := &request.MDLCredentialOfferRequest{
-		GenericCredentialOffer: object.GenericCredentialOffer{
-			CredentialIDS:      []string{"eu.europa.ec.eudi.mdl_mdoc"},
-			CodeGrant:          "pre_auth_code",
-			CredentialOfferURI: r.Config().QRAPIDeepLink + "://",
-			ReturnInHTML:       false,
-		},
-		Form: mdlRes,
+	switch {
+	case strings.Contains(accept, "image/png"):
+		return "png"
+	case strings.Contains(accept, "image/svg+xml"):
+		return "svg"
+	default:
+		return "json"
 	}
-
-	return gcoReq, nil
 }
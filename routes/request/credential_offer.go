@@ -0,0 +1,11 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package request
+
+import "git.zzdats.lv/edim/api-wallet/routes/object"
+
+// CredentialOfferRequest is the upstream issuer credential offer request with the credential type specific form.
+type CredentialOfferRequest struct {
+	object.GenericCredentialOffer
+	Form any `json:"form"`
+}
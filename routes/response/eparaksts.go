@@ -2,6 +2,8 @@
 
 package response
 
+import "time"
+
 type EparakstsSignResponse struct {
 	RedirectURL string              `json:"redirectUrl"`
 	Sessions    []SimpleSignSession `json:"sessions"`
@@ -31,8 +33,84 @@ type EparakstsSignRedirectResponse struct {
 	RedirectURL string `json:"redirectUrl"`
 }
 
-// TODO: define validate response fields.
-type EparakstsValidateResponse struct{}
+// Validation indications of the signed document, signature and timestamp.
+const (
+	IndicationTotalPassed   = "TOTAL_PASSED"
+	IndicationTotalFailed   = "TOTAL_FAILED"
+	IndicationPassed        = "PASSED"
+	IndicationFailed        = "FAILED"
+	IndicationIndeterminate = "INDETERMINATE"
+)
+
+// Signer certificate statuses.
+const (
+	CertificateStatusGood    = "GOOD"
+	CertificateStatusRevoked = "REVOKED"
+	CertificateStatusUnknown = "UNKNOWN"
+)
+
+// EparakstsValidateResponse defines validation result of the signed file.
+type EparakstsValidateResponse struct {
+	// Indication represents the overall result: `TOTAL_PASSED` if all documents passed validation,
+	// `TOTAL_FAILED` if any document failed validation, otherwise `INDETERMINATE`
+	Indication string `json:"indication"`
+	// Documents represents the validated documents
+	Documents []EparakstsValidatedDocument `json:"documents"`
+}
+
+// EparakstsValidatedDocument defines validation result of the signed document.
+type EparakstsValidatedDocument struct {
+	// FileName represents the file name of the document
+	FileName string `json:"fileName"`
+	// ValidationTime represents the time of the validation
+	ValidationTime *time.Time `json:"validationTime,omitempty"`
+	// Indication represents the document validation result: `TOTAL_PASSED`, `TOTAL_FAILED` or `INDETERMINATE`
+	Indication string `json:"indication"`
+	// SignaturesCount represents the number of signatures in the document
+	SignaturesCount int `json:"signaturesCount"`
+	// ValidSignaturesCount represents the number of signatures that passed validation
+	ValidSignaturesCount int `json:"validSignaturesCount"`
+	// Signatures represents the signatures of the document
+	Signatures []EparakstsSignature `json:"signatures"`
+}
+
+// EparakstsSignature defines validation result of the signature.
+type EparakstsSignature struct {
+	// ID represents the ID of the signature
+	ID string `json:"id"`
+	// SignedBy represents the name of the signer
+	SignedBy string `json:"signedBy"`
+	// SignatureFormat represents the format of the signature, for example, `PAdES-BASELINE-LT`
+	SignatureFormat string `json:"signatureFormat"`
+	// SigningTime represents the claimed signing time
+	SigningTime *time.Time `json:"signingTime,omitempty"`
+	// Indication represents the signature validation result: `TOTAL_PASSED`, `TOTAL_FAILED` or `INDETERMINATE`
+	Indication string `json:"indication"`
+	// Certificate represents the signer certificate
+	Certificate EparakstsCertificate `json:"certificate"`
+	// Timestamps represents the signature timestamps
+	Timestamps []EparakstsTimestamp `json:"timestamps"`
+}
+
+// EparakstsCertificate defines signer certificate.
+type EparakstsCertificate struct {
+	// Subject represents the distinguished name of the certificate subject
+	Subject string `json:"subject"`
+	// Issuer represents the distinguished name of the certificate issuer
+	Issuer string `json:"issuer"`
+	// SerialNumber represents the serial number of the subject
+	SerialNumber string `json:"serialNumber"`
+	// Status represents the revocation status: `GOOD`, `REVOKED` or `UNKNOWN`
+	Status string `json:"status"`
+}
+
+// EparakstsTimestamp defines validation result of the signature timestamp.
+type EparakstsTimestamp struct {
+	// ProductionTime represents the time the timestamp was produced
+	ProductionTime *time.Time `json:"productionTime,omitempty"`
+	// Indication represents the timestamp validation result: `PASSED`, `FAILED` or `INDETERMINATE`
+	Indication string `json:"indication"`
+}
 
 // TODO: define identities response fields.
 type EparakstsIdentitiesResponse struct{}
@@ -28,7 +28,7 @@ func Init(a *wallet.App) error {
 	r := &router{
 		App: a,
 	}
-	r.openapi = oa.NewDefaultOpenAPIHandler(openapi.OpenAPIDefinition, a.App)
+	r.openapi = oa.NewDefaultOpenAPIHandler(openapi.WithPathParamEnum(openapi.OpenAPIDefinition, "requestType", a.Credentials().Types()), a.App)
 
 	a.Get("/healthz", r.healthz)
 
@@ -50,12 +50,18 @@ func Init(a *wallet.App) error {
 	{
 		portal.Use(idauth.Authentication(a.App, a.Config().IDAuth))
 		portal.Post("/{requestType}", idauth.UserHasScope("citizen", r.qrCodeInternal))
+		portal.Get("/rtu/diplomas", idauth.UserHasScope("citizen", r.diplomas))
+		portal.Get("/offers/{offerID}", idauth.UserHasScope("citizen", r.offerStatus))
+		portal.Delete("/offers/{offerID}", idauth.UserHasScope("citizen", r.cancelOffer))
 	}
 
 	v1 := a.Group("/1.0")
 	{
 		v1.Use(idauth.Authentication(a.App, a.Config().IDAuth))
 		v1.Post("/{requestType}", r.qrCode)
+		v1.Get("/rtu/diplomas", r.diplomas)
+		v1.Get("/offers/{offerID}", r.offerStatus)
+		v1.Delete("/offers/{offerID}", r.cancelOffer)
 	}
 
 	return nil
@@ -6,16 +6,84 @@ import (
 	"testing"
 
 	api "git.zzdats.lv/edim/api-wallet"
+	"git.zzdats.lv/edim/api-wallet/mock"
+	"git.zzdats.lv/edim/api-wallet/mock/idauthtest"
 
 	"azugo.io/azugo"
+	"azugo.io/core/http"
 	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
 )
 
-func testApp(t testing.TB) *azugo.TestApp {
-	app := api.TestApp(t)
+func testApp(t testing.TB, opts ...api.TestOption) (*azugo.TestApp, *idauthtest.Server) {
+	idp := idauthtest.New(t)
+
+	app := api.TestApp(t, append([]api.TestOption{api.WithIDAuthURL(idp.URL())}, opts...)...)
 
 	err := Init(app)
 	qt.Assert(t, qt.IsNil(err))
 
-	return azugo.NewTestApp(app.App)
+	return azugo.NewTestApp(app.App), idp
+}
+
+// withUpstreams points test application to the fakes of IDAuth, source registries and issuer.
+// Access token of the fixture person is its person code.
+func withUpstreams(t testing.TB) []api.TestOption {
+	t.Helper()
+
+	srv := mock.New()
+	qt.Assert(t, qt.IsNil(srv.Start("127.0.0.1:0")))
+
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+
+	opts := make([]api.TestOption, 0, len(srv.Env()))
+	for k, v := range srv.Env() {
+		opts = append(opts, api.WithEnv(k, v))
+	}
+
+	return opts
+}
+
+func TestAuthentication(t *testing.T) {
+	app, idp := testApp(t)
+
+	inactive := idp.AddUser("32345678901", idauthtest.Inactive())
+	pending := idp.AddUser("32345678902", idauthtest.WithState("req_role"))
+	noScope := idp.AddUser("32345678903", idauthtest.WithScopes("employee"))
+
+	app.Start(t)
+	defer app.Stop()
+
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		status int
+	}{
+		{name: "anonymous", path: "/1.0/internal/rtu/diplomas", status: fasthttp.StatusUnauthorized},
+		{name: "unknown session", path: "/1.0/internal/rtu/diplomas", token: "unknown", status: fasthttp.StatusUnauthorized},
+		{name: "inactive session", path: "/1.0/internal/rtu/diplomas", token: inactive, status: fasthttp.StatusUnauthorized},
+		{name: "not authorized state", path: "/1.0/internal/rtu/diplomas", token: pending, status: fasthttp.StatusForbidden},
+		{name: "missing citizen scope", path: "/1.0/internal/rtu/diplomas", token: noScope, status: fasthttp.StatusForbidden},
+		{name: "anonymous v1", path: "/1.0/rtu/diplomas", status: fasthttp.StatusUnauthorized},
+		{name: "not authorized state v1", path: "/1.0/rtu/diplomas", token: pending, status: fasthttp.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := app.TestClient()
+
+			var opts []http.RequestOption
+			if tt.token != "" {
+				opts = append(opts, client.WithHeader(fasthttp.HeaderAuthorization, idauthtest.Authorization(tt.token)))
+			}
+
+			resp, err := client.Get(tt.path, opts...)
+			qt.Assert(t, qt.IsNil(err))
+			qt.Check(t, qt.Equals(resp.StatusCode(), tt.status))
+			fasthttp.ReleaseResponse(resp)
+		})
+	}
 }
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+
+	"git.zzdats.lv/edim/api-wallet/credential"
+	"git.zzdats.lv/edim/api-wallet/models"
+
+	"azugo.io/azugo"
+	"azugo.io/core/http"
+	"github.com/valyala/fasthttp"
+)
+
+// @operationId ListDiplomas
+// @title List diplomas
+// @description Returns diplomas of the person that can be issued as `rtu` credential. Diploma ID can be passed as `diploma` query parameter to the credential offer request.
+// @success 200 Diploma []models.Diploma "Diplomas"
+// @failure 401 {empty} "Unauthorized"
+// @failure 403 {empty} "Forbidden"
+// @failure 404 {empty} "Not found"
+// @failure 500 string string "Internal server error"
+// @failure 502 SourceError models.SourceError "Source registry rejected the call"
+// @failure 503 SourceError models.SourceError "Source registry temporarily unavailable"
+// @resource QRCode
+// @route /1.0/rtu/diplomas [get].
+func (r *router) diplomas(ctx *azugo.Context) {
+	if ctx.User().ClaimValue("code") == "" {
+		ctx.StatusCode(fasthttp.StatusUnauthorized)
+
+		return
+	}
+
+	source, ok := r.Credentials().Source("rtu")
+	if !ok {
+		ctx.StatusCode(fasthttp.StatusNotFound)
+
+		return
+	}
+
+	lister, ok := source.(credential.DiplomaLister)
+	if !ok {
+		ctx.StatusCode(fasthttp.StatusNotFound)
+
+		return
+	}
+
+	diplomas, err := lister.ListDiplomas(ctx)
+	if err != nil {
+		if errors.Is(err, http.NotFoundError{}) {
+			ctx.JSON([]*models.Diploma{})
+
+			return
+		}
+
+		if sourceError(ctx, source, err) {
+			return
+		}
+
+		ctx.Error(fmt.Errorf("%s: %w", source.Errors().Failed, err))
+
+		return
+	}
+
+	res := make([]*models.Diploma, 0, len(diplomas))
+	for _, d := range diplomas {
+		res = append(res, &models.Diploma{
+			ID:           d.ID,
+			Type:         d.Type,
+			Title:        d.Title,
+			AwardingBody: d.AwardingBody,
+			AwardingDate: d.AwardingDate,
+		})
+	}
+
+	ctx.JSON(res)
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package routes
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	api "git.zzdats.lv/edim/api-wallet"
+	"git.zzdats.lv/edim/api-wallet/credential"
+	"git.zzdats.lv/edim/api-wallet/mock"
+	"git.zzdats.lv/edim/api-wallet/mock/jsondbtest"
+	"git.zzdats.lv/edim/api-wallet/models"
+
+	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
+)
+
+func TestDiplomas(t *testing.T) {
+	app, _ := testApp(t, append(withUpstreams(t), api.WithStore(jsondbtest.New()))...)
+
+	app.Start(t)
+	defer app.Stop()
+
+	status, _ := testRequest(t, app, fasthttp.MethodGet, "/1.0/rtu/diplomas", "", nil)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusUnauthorized))
+
+	status, body := testRequest(t, app, fasthttp.MethodGet, "/1.0/rtu/diplomas", mock.PersonCodeDiplomas, nil)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK), qt.Commentf("%s", body))
+
+	res := []*models.Diploma{}
+	qt.Assert(t, qt.IsNil(json.Unmarshal(body, &res)))
+	qt.Assert(t, qt.HasLen(res, 2))
+	qt.Check(t, qt.Equals(res[0].ID, "diploma-1"))
+	qt.Check(t, qt.Equals(res[0].Title, "Bachelor of Economics"))
+	qt.Check(t, qt.Not(qt.Equals(res[0].AwardingBody, "")))
+	qt.Check(t, qt.Equals(res[1].ID, "diploma-2"))
+
+	status, body = testRequest(t, app, fasthttp.MethodGet, "/1.0/internal/rtu/diplomas", mock.PersonCodeFull, nil)
+	qt.Assert(t, qt.Equals(status, fasthttp.StatusOK), qt.Commentf("%s", body))
+	qt.Assert(t, qt.IsNil(json.Unmarshal(body, &res)))
+	qt.Check(t, qt.HasLen(res, 1))
+}
+
+func TestDiplomas_Selection(t *testing.T) {
+	app, _ := testApp(t, append(withUpstreams(t), api.WithStore(jsondbtest.New()))...)
+
+	app.Start(t)
+	defer app.Stop()
+
+	// Diploma must be selected if the person has more than one diploma
+	status, body := testRequest(t, app, fasthttp.MethodPost, "/1.0/rtu", mock.PersonCodeDiplomas, nil)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusUnprocessableEntity), qt.Commentf("%s", body))
+
+	// Unknown diploma is an invalid request parameter and not missing person data
+	status, body = testRequest(t, app, fasthttp.MethodPost, "/1.0/rtu?diploma=unknown", mock.PersonCodeDiplomas, nil)
+	qt.Check(t, qt.Equals(status, fasthttp.StatusUnprocessableEntity), qt.Commentf("%s", body))
+	qt.Check(t, qt.StringContains(string(body), "diploma"))
+	qt.Check(t, qt.IsFalse(strings.Contains(string(body), credential.CodePersonNotFound)))
+
+	offer := createOfferPath(t, app, mock.PersonCodeDiplomas, "/1.0/rtu?diploma=diploma-2")
+	qt.Check(t, qt.Not(qt.Equals(offer.ID, "")))
+
+	// Selection can be omitted if the person has only one diploma
+	offer = createOfferPath(t, app, mock.PersonCodeFull, "/1.0/rtu")
+	qt.Check(t, qt.Not(qt.Equals(offer.ID, "")))
+}
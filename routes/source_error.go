@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package routes
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"git.zzdats.lv/edim/api-wallet/credential"
+	"git.zzdats.lv/edim/api-wallet/credential/validation"
+	"git.zzdats.lv/edim/api-wallet/models"
+
+	"azugo.io/azugo"
+	"azugo.io/core/http"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// sourceError responds with the stable error code if the source registry error is known.
+// Returns false if the error must be handled by the caller.
+func sourceError(ctx *azugo.Context, source credential.Source, err error) bool {
+	res := &models.SourceError{
+		Source: source.Type(),
+	}
+
+	if errors.Is(err, http.NotFoundError{}) {
+		res.Code = credential.CodePersonNotFound
+		res.Message = source.Errors().NotFound
+
+		ctx.StatusCode(fasthttp.StatusNotFound)
+		ctx.JSON(res)
+
+		return true
+	}
+
+	var cerr credential.CodedError
+	if !errors.As(err, &cerr) {
+		return false
+	}
+
+	res.Code = cerr.ErrorCode()
+	res.Message = cerr.Error()
+
+	var (
+		ierr credential.PersonInactiveError
+		verr validation.Error
+		uerr credential.UnavailableError
+	)
+
+	switch {
+	case errors.As(err, &ierr):
+		res.Reason = ierr.Reason
+	case errors.As(err, &verr):
+		ctx.Log().Warn("invalid source registry data", zap.Error(err))
+
+		res.Errors = verr.Errors
+	case errors.As(err, &uerr):
+		ctx.Log().Warn("source registry unavailable", zap.Error(err))
+
+		res.Message = source.Errors().Failed
+
+		if uerr.RetryAfter > 0 {
+			ctx.Header.Set(fasthttp.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(uerr.RetryAfter.Seconds()))))
+		}
+	case res.Code == credential.CodeUpstreamUnauthorized:
+		ctx.Log().Error("source registry rejected the call", zap.Error(err))
+
+		res.Message = source.Errors().Failed
+	}
+
+	ctx.StatusCode(cerr.StatusCode())
+	ctx.JSON(res)
+
+	return true
+}
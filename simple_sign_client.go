@@ -182,7 +182,8 @@ func (ssc *SimpleSignClient) GetFile(ctx *azugo.Context, sessionID string) ([]by
 	return file, contentType, contentDisposition, nil
 }
 
-func (ssc *SimpleSignClient) ValidateFile(ctx *azugo.Context, reqJSON *request.ValidateRequest, file *multipart.FileHeader) ([]byte, error) {
+// ValidateFile validates signatures of the file using SimpleSign and returns normalized validation result.
+func (ssc *SimpleSignClient) ValidateFile(ctx *azugo.Context, reqJSON *request.ValidateRequest, file *multipart.FileHeader) (*response.EparakstsValidateResponse, error) {
 	targetURL, err := url.JoinPath(ssc.url, "v2.0/file/validate")
 	if err != nil {
 		return nil, err
@@ -212,7 +213,7 @@ func (ssc *SimpleSignClient) ValidateFile(ctx *azugo.Context, reqJSON *request.V
 		return nil, err
 	}
 
-	return resByte, nil
+	return parseValidationReport(ctx.Log(), resByte)
 }
 
 func (ssc *SimpleSignClient) GetIdentitiesRedirect(redirectURL *string) (*response.EparakstsSignRedirectResponse, error) {
@@ -0,0 +1,342 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/routes/response"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// SimpleSignResponseError is returned when SimpleSign validation response can not be used.
+type SimpleSignResponseError struct {
+	Err error
+}
+
+func (e SimpleSignResponseError) Error() string {
+	return "invalid SimpleSign validation response: " + e.Err.Error()
+}
+
+func (e SimpleSignResponseError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode is 502 as SimpleSign and not the wallet app has sent the invalid data.
+func (SimpleSignResponseError) StatusCode() int {
+	return fasthttp.StatusBadGateway
+}
+
+// ssValidationTimeLayouts are the time layouts accepted in the SimpleSign validation report.
+// Times without time zone are assumed to be in UTC.
+var ssValidationTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+}
+
+// ssTime is the time in SimpleSign validation report that is empty if it can not be parsed.
+type ssTime struct {
+	time *time.Time
+	// invalid is the value that could not be parsed
+	invalid string
+}
+
+func (t *ssTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.invalid = string(data)
+
+		return nil //nolint:nilerr // Invalid value is logged when the report is normalized.
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	for _, layout := range ssValidationTimeLayouts {
+		if v, err := time.Parse(layout, s); err == nil {
+			v = v.UTC()
+			t.time = &v
+
+			return nil
+		}
+	}
+
+	t.invalid = s
+
+	return nil
+}
+
+// value returns the parsed time and logs the value that could not be parsed.
+func (t ssTime) value(log *zap.Logger, field string) *time.Time {
+	if t.invalid != "" {
+		log.Warn("invalid time in SimpleSign validation response", zap.String("field", field), zap.String("value", t.invalid))
+	}
+
+	return t.time
+}
+
+// ssCount is the count in SimpleSign validation report that can be encoded as number or string.
+type ssCount struct {
+	value int
+	set   bool
+	// invalid is the value that could not be parsed
+	invalid string
+}
+
+func (c *ssCount) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `" `)
+	if s == "" || s == "null" {
+		return nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		c.invalid = string(data)
+
+		return nil //nolint:nilerr // Invalid value is logged when the report is normalized.
+	}
+
+	c.value, c.set = v, true
+
+	return nil
+}
+
+// log logs the value that could not be parsed.
+func (c ssCount) log(log *zap.Logger, field string) {
+	if c.invalid != "" {
+		log.Warn("invalid count in SimpleSign validation response", zap.String("field", field), zap.String("value", c.invalid))
+	}
+}
+
+type ssValidationReport struct {
+	Documents []ssValidatedDocument `json:"documents"`
+}
+
+type ssValidatedDocument struct {
+	FileName             string        `json:"fileName"`
+	ValidationTime       ssTime        `json:"validationTime"`
+	Indication           string        `json:"indication"`
+	SignaturesCount      ssCount       `json:"signaturesCount"`
+	ValidSignaturesCount ssCount       `json:"validSignaturesCount"`
+	Signatures           []ssSignature `json:"signatures"`
+}
+
+type ssSignature struct {
+	ID              string        `json:"id"`
+	SignedBy        string        `json:"signedBy"`
+	SignatureFormat string        `json:"signatureFormat"`
+	SigningTime     ssTime        `json:"signingTime"`
+	Indication      string        `json:"indication"`
+	Certificate     ssCertificate `json:"certificate"`
+	Timestamps      []ssTimestamp `json:"timestamps"`
+}
+
+type ssCertificate struct {
+	Subject      string `json:"subject"`
+	Issuer       string `json:"issuer"`
+	SerialNumber string `json:"serialNumber"`
+	Status       string `json:"status"`
+}
+
+type ssTimestamp struct {
+	ProductionTime ssTime `json:"productionTime"`
+	Indication     string `json:"indication"`
+}
+
+// parseValidationReport parses SimpleSign validation report and normalizes it to the stable response schema.
+//
+// Report can be an object with the documents or the list of documents. Report without documents is rejected.
+// Unknown indications and statuses are reported as indeterminate or unknown, signature counts are derived
+// from the signatures if present. Times and counts that can not be parsed are logged and left empty.
+func parseValidationReport(log *zap.Logger, data []byte) (*response.EparakstsValidateResponse, error) {
+	report := ssValidationReport{}
+
+	var err error
+
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &report.Documents)
+	} else {
+		err = json.Unmarshal(data, &report)
+	}
+
+	if err != nil {
+		return nil, SimpleSignResponseError{Err: err}
+	}
+
+	if len(report.Documents) == 0 {
+		return nil, SimpleSignResponseError{Err: errors.New("no validated documents")}
+	}
+
+	res := &response.EparakstsValidateResponse{
+		Documents: make([]response.EparakstsValidatedDocument, 0, len(report.Documents)),
+	}
+
+	for _, d := range report.Documents {
+		res.Documents = append(res.Documents, normalizeValidatedDocument(log, d))
+	}
+
+	res.Indication = overallIndication(res.Documents)
+
+	return res, nil
+}
+
+func normalizeValidatedDocument(log *zap.Logger, d ssValidatedDocument) response.EparakstsValidatedDocument {
+	log = log.With(zap.String("fileName", d.FileName))
+
+	d.SignaturesCount.log(log, "signaturesCount")
+	d.ValidSignaturesCount.log(log, "validSignaturesCount")
+
+	doc := response.EparakstsValidatedDocument{
+		FileName:             d.FileName,
+		ValidationTime:       d.ValidationTime.value(log, "validationTime"),
+		Indication:           normalizeIndication(d.Indication, true),
+		SignaturesCount:      d.SignaturesCount.value,
+		ValidSignaturesCount: d.ValidSignaturesCount.value,
+		Signatures:           make([]response.EparakstsSignature, 0, len(d.Signatures)),
+	}
+
+	for _, s := range d.Signatures {
+		doc.Signatures = append(doc.Signatures, normalizeSignature(log, s))
+	}
+
+	if len(doc.Signatures) > 0 {
+		doc.SignaturesCount = len(doc.Signatures)
+		doc.ValidSignaturesCount = 0
+
+		for _, s := range doc.Signatures {
+			if s.Indication == response.IndicationTotalPassed {
+				doc.ValidSignaturesCount++
+			}
+		}
+	}
+
+	if !d.SignaturesCount.set {
+		doc.SignaturesCount = max(doc.SignaturesCount, doc.ValidSignaturesCount)
+	}
+
+	doc.ValidSignaturesCount = min(doc.ValidSignaturesCount, doc.SignaturesCount)
+
+	if d.Indication == "" {
+		doc.Indication = documentIndication(doc)
+	}
+
+	return doc
+}
+
+func normalizeSignature(log *zap.Logger, s ssSignature) response.EparakstsSignature {
+	sig := response.EparakstsSignature{
+		ID:              s.ID,
+		SignedBy:        strings.TrimSpace(s.SignedBy),
+		SignatureFormat: s.SignatureFormat,
+		SigningTime:     s.SigningTime.value(log, "signingTime"),
+		Indication:      normalizeIndication(s.Indication, true),
+		Certificate: response.EparakstsCertificate{
+			Subject:      s.Certificate.Subject,
+			Issuer:       s.Certificate.Issuer,
+			SerialNumber: s.Certificate.SerialNumber,
+			Status:       normalizeCertificateStatus(s.Certificate.Status),
+		},
+		Timestamps: make([]response.EparakstsTimestamp, 0, len(s.Timestamps)),
+	}
+
+	if sig.SignedBy == "" {
+		sig.SignedBy = commonName(s.Certificate.Subject)
+	}
+
+	for _, t := range s.Timestamps {
+		sig.Timestamps = append(sig.Timestamps, response.EparakstsTimestamp{
+			ProductionTime: t.ProductionTime.value(log, "productionTime"),
+			Indication:     normalizeIndication(t.Indication, false),
+		})
+	}
+
+	return sig
+}
+
+// normalizeIndication returns ETSI EN 319 102-1 indication. Total indications are used for documents and
+// signatures, while timestamps use basic building block indications.
+func normalizeIndication(v string, total bool) string {
+	passed, failed := response.IndicationPassed, response.IndicationFailed
+	if total {
+		passed, failed = response.IndicationTotalPassed, response.IndicationTotalFailed
+	}
+
+	switch strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToUpper(strings.TrimSpace(v))) {
+	case "TOTAL_PASSED", "PASSED", "VALID":
+		return passed
+	case "TOTAL_FAILED", "FAILED", "INVALID":
+		return failed
+	default:
+		return response.IndicationIndeterminate
+	}
+}
+
+func normalizeCertificateStatus(v string) string {
+	switch strings.ToUpper(strings.TrimSpace(v)) {
+	case "GOOD", "VALID":
+		return response.CertificateStatusGood
+	case "REVOKED":
+		return response.CertificateStatusRevoked
+	default:
+		return response.CertificateStatusUnknown
+	}
+}
+
+// documentIndication derives document indication from the signatures if SimpleSign did not return it.
+func documentIndication(doc response.EparakstsValidatedDocument) string {
+	switch {
+	case doc.SignaturesCount == 0:
+		return response.IndicationIndeterminate
+	case doc.ValidSignaturesCount == doc.SignaturesCount:
+		return response.IndicationTotalPassed
+	}
+
+	for _, s := range doc.Signatures {
+		if s.Indication == response.IndicationTotalFailed {
+			return response.IndicationTotalFailed
+		}
+	}
+
+	return response.IndicationIndeterminate
+}
+
+func overallIndication(docs []response.EparakstsValidatedDocument) string {
+	if len(docs) == 0 {
+		return response.IndicationIndeterminate
+	}
+
+	indication := response.IndicationTotalPassed
+
+	for _, d := range docs {
+		switch d.Indication {
+		case response.IndicationTotalFailed:
+			return response.IndicationTotalFailed
+		case response.IndicationIndeterminate:
+			indication = response.IndicationIndeterminate
+		}
+	}
+
+	return indication
+}
+
+// commonName returns CN attribute of the distinguished name.
+func commonName(dn string) string {
+	for _, attr := range strings.Split(dn, ",") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(attr), "="); ok && strings.EqualFold(k, "CN") {
+			return strings.TrimSpace(v)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,301 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package wallet
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/routes/response"
+
+	"github.com/go-quicktest/qt"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseValidationReport(t *testing.T) {
+	signed := time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+
+	res, err := parseValidationReport(zap.NewNop(), []byte(`{
+		"documents": [{
+			"fileName": "file.pdf",
+			"validationTime": "2025-03-10T14:30:00+02:00",
+			"signaturesCount": 1,
+			"validSignaturesCount": 1,
+			"indication": "TOTAL_PASSED",
+			"unknownField": true,
+			"signatures": [{
+				"id": "S-1",
+				"signedBy": "JĀNIS PARAUGS",
+				"signatureFormat": "PAdES-BASELINE-LT",
+				"signingTime": "2025-03-10T12:30:00Z",
+				"indication": "TOTAL_PASSED",
+				"certificate": {
+					"subject": "CN=JĀNIS PARAUGS,SERIALNUMBER=PNOLV-323456-78901,C=LV",
+					"issuer": "CN=eParaksts CA,C=LV",
+					"serialNumber": "PNOLV-323456-78901",
+					"status": "GOOD"
+				},
+				"timestamps": [{"productionTime": "2025-03-10T12:30:00Z", "indication": "PASSED"}]
+			}]
+		}]
+	}`))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.DeepEquals(res, &response.EparakstsValidateResponse{
+		Indication: response.IndicationTotalPassed,
+		Documents: []response.EparakstsValidatedDocument{
+			{
+				FileName:             "file.pdf",
+				ValidationTime:       &signed,
+				Indication:           response.IndicationTotalPassed,
+				SignaturesCount:      1,
+				ValidSignaturesCount: 1,
+				Signatures: []response.EparakstsSignature{
+					{
+						ID:              "S-1",
+						SignedBy:        "JĀNIS PARAUGS",
+						SignatureFormat: "PAdES-BASELINE-LT",
+						SigningTime:     &signed,
+						Indication:      response.IndicationTotalPassed,
+						Certificate: response.EparakstsCertificate{
+							Subject:      "CN=JĀNIS PARAUGS,SERIALNUMBER=PNOLV-323456-78901,C=LV",
+							Issuer:       "CN=eParaksts CA,C=LV",
+							SerialNumber: "PNOLV-323456-78901",
+							Status:       response.CertificateStatusGood,
+						},
+						Timestamps: []response.EparakstsTimestamp{
+							{ProductionTime: &signed, Indication: response.IndicationPassed},
+						},
+					},
+				},
+			},
+		},
+	}))
+}
+
+func TestParseValidationReportNormalization(t *testing.T) {
+	tests := []struct {
+		name        string
+		report      string
+		indication  string
+		document    string
+		signatures  int
+		valid       int
+		signedBy    string
+		certificate string
+		timestamp   string
+		signingTime bool
+	}{
+		{
+			name:        "lowercase and hyphenated indications",
+			report:      `{"documents":[{"indication":"total-passed","signatures":[{"indication":"Total_Passed","certificate":{"status":"valid"},"timestamps":[{"indication":"passed"}]}]}]}`,
+			indication:  response.IndicationTotalPassed,
+			document:    response.IndicationTotalPassed,
+			signatures:  1,
+			valid:       1,
+			certificate: response.CertificateStatusGood,
+			timestamp:   response.IndicationPassed,
+		},
+		{
+			name:        "list of documents without document indication",
+			report:      `[{"signatures":[{"indication":"TOTAL_PASSED"},{"indication":"TOTAL_FAILED","certificate":{"status":"REVOKED"}}]}]`,
+			indication:  response.IndicationTotalFailed,
+			document:    response.IndicationTotalFailed,
+			signatures:  2,
+			valid:       1,
+			certificate: response.CertificateStatusRevoked,
+		},
+		{
+			name:        "counts derived from signatures",
+			report:      `{"documents":[{"indication":"TOTAL_PASSED","signaturesCount":"5","validSignaturesCount":"5","signatures":[{"indication":"TOTAL_PASSED"}]}]}`,
+			indication:  response.IndicationTotalPassed,
+			document:    response.IndicationTotalPassed,
+			signatures:  1,
+			valid:       1,
+			certificate: response.CertificateStatusUnknown,
+		},
+		{
+			name:       "counts as strings without signatures",
+			report:     `{"documents":[{"indication":"TOTAL_PASSED","signaturesCount":"2","validSignaturesCount":3}]}`,
+			indication: response.IndicationTotalPassed,
+			document:   response.IndicationTotalPassed,
+			signatures: 2,
+			valid:      2,
+		},
+		{
+			name:        "unknown values",
+			report:      `{"documents":[{"indication":"NO_SIGNATURE","signaturesCount":null,"signatures":[{"indication":"SUB_INDICATION","signingTime":"yesterday","certificate":{"subject":"C=LV, CN=Jānis Paraugs","status":"SUSPENDED"},"timestamps":[{"indication":"expired"}]}]}]}`,
+			indication:  response.IndicationIndeterminate,
+			document:    response.IndicationIndeterminate,
+			signatures:  1,
+			signedBy:    "Jānis Paraugs",
+			certificate: response.CertificateStatusUnknown,
+			timestamp:   response.IndicationIndeterminate,
+		},
+		{
+			name:        "time without time zone",
+			report:      `{"documents":[{"signatures":[{"indication":"TOTAL_PASSED","signingTime":"2025-03-10 12:30:00"}]}]}`,
+			indication:  response.IndicationTotalPassed,
+			document:    response.IndicationTotalPassed,
+			signatures:  1,
+			valid:       1,
+			certificate: response.CertificateStatusUnknown,
+			signingTime: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := parseValidationReport(zap.NewNop(), []byte(tt.report))
+			qt.Assert(t, qt.IsNil(err))
+			qt.Check(t, qt.Equals(res.Indication, tt.indication))
+			qt.Assert(t, qt.HasLen(res.Documents, 1))
+
+			doc := res.Documents[0]
+			qt.Check(t, qt.Equals(doc.Indication, tt.document))
+			qt.Check(t, qt.Equals(doc.SignaturesCount, tt.signatures))
+			qt.Check(t, qt.Equals(doc.ValidSignaturesCount, tt.valid))
+
+			if len(doc.Signatures) == 0 {
+				return
+			}
+
+			sig := doc.Signatures[len(doc.Signatures)-1]
+			qt.Check(t, qt.Equals(sig.SignedBy, tt.signedBy))
+			qt.Check(t, qt.Equals(sig.Certificate.Status, tt.certificate))
+			qt.Check(t, qt.Equals(sig.SigningTime != nil, tt.signingTime))
+
+			if tt.timestamp != "" {
+				qt.Assert(t, qt.HasLen(sig.Timestamps, 1))
+				qt.Check(t, qt.Equals(sig.Timestamps[0].Indication, tt.timestamp))
+			}
+		})
+	}
+}
+
+func TestParseValidationReportSchema(t *testing.T) {
+	res, err := parseValidationReport(zap.NewNop(), []byte(`{"documents":[{"fileName":"file.edoc"}]}`))
+	qt.Assert(t, qt.IsNil(err))
+
+	buf, err := json.Marshal(res)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.JSONEquals(buf, map[string]any{
+		"indication": response.IndicationIndeterminate,
+		"documents": []any{
+			map[string]any{
+				"fileName":             "file.edoc",
+				"indication":           response.IndicationIndeterminate,
+				"signaturesCount":      0,
+				"validSignaturesCount": 0,
+				"signatures":           []any{},
+			},
+		},
+	}))
+
+	_, err = parseValidationReport(zap.NewNop(), []byte(`<html>Bad Gateway</html>`))
+	qt.Check(t, qt.ErrorMatches(err, "invalid SimpleSign validation response: .*"))
+}
+
+func TestParseValidationReportFixture(t *testing.T) {
+	ptr := func(v time.Time) *time.Time {
+		return &v
+	}
+
+	data, err := os.ReadFile("testdata/simplesign_validate_v2.json")
+	qt.Assert(t, qt.IsNil(err))
+
+	core, logs := observer.New(zapcore.WarnLevel)
+
+	res, err := parseValidationReport(zap.New(core), data)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(res.Indication, response.IndicationTotalFailed))
+	qt.Assert(t, qt.HasLen(res.Documents, 1))
+
+	doc := res.Documents[0]
+	qt.Check(t, qt.Equals(doc.FileName, "līgums.edoc"))
+	qt.Check(t, qt.DeepEquals(doc.ValidationTime, ptr(time.Date(2025, 3, 10, 12, 31, 7, 415000000, time.UTC))))
+	qt.Check(t, qt.Equals(doc.Indication, response.IndicationTotalFailed))
+	qt.Check(t, qt.Equals(doc.SignaturesCount, 2))
+	qt.Check(t, qt.Equals(doc.ValidSignaturesCount, 1))
+	qt.Assert(t, qt.HasLen(doc.Signatures, 2))
+
+	valid := doc.Signatures[0]
+	qt.Check(t, qt.Equals(valid.SignedBy, "JĀNIS PARAUGS"))
+	qt.Check(t, qt.Equals(valid.SignatureFormat, "XAdES-BASELINE-LT"))
+	qt.Check(t, qt.DeepEquals(valid.SigningTime, ptr(time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC))))
+	qt.Check(t, qt.Equals(valid.Indication, response.IndicationTotalPassed))
+	qt.Check(t, qt.Equals(valid.Certificate.Status, response.CertificateStatusGood))
+	qt.Check(t, qt.Equals(valid.Certificate.SerialNumber, "4f2a8c1e9b7d3a05"))
+	qt.Assert(t, qt.HasLen(valid.Timestamps, 1))
+	qt.Check(t, qt.Equals(valid.Timestamps[0].Indication, response.IndicationPassed))
+
+	// Signer name is taken from the certificate subject if missing
+	revoked := doc.Signatures[1]
+	qt.Check(t, qt.Equals(revoked.SignedBy, "ANNA PARAUDZIŅA"))
+	qt.Check(t, qt.DeepEquals(revoked.SigningTime, ptr(time.Date(2025, 3, 10, 12, 45, 13, 0, time.UTC))))
+	qt.Check(t, qt.Equals(revoked.Indication, response.IndicationTotalFailed))
+	qt.Check(t, qt.Equals(revoked.Certificate.Status, response.CertificateStatusRevoked))
+	qt.Assert(t, qt.HasLen(revoked.Timestamps, 1))
+	qt.Check(t, qt.Equals(revoked.Timestamps[0].Indication, response.IndicationIndeterminate))
+
+	qt.Check(t, qt.Equals(logs.Len(), 0))
+}
+
+func TestParseValidationReportNoDocuments(t *testing.T) {
+	for _, report := range []string{`{}`, `{"documents":null}`, `{"documents":[]}`, `[]`, `null`} {
+		_, err := parseValidationReport(zap.NewNop(), []byte(report))
+
+		var serr SimpleSignResponseError
+
+		qt.Assert(t, qt.ErrorAs(err, &serr), qt.Commentf("%s", report))
+		qt.Check(t, qt.Equals(serr.StatusCode(), fasthttp.StatusBadGateway))
+		qt.Check(t, qt.ErrorMatches(err, "invalid SimpleSign validation response: no validated documents"))
+	}
+}
+
+func TestParseValidationReportInvalidValues(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+
+	res, err := parseValidationReport(zap.New(core), []byte(`{"documents":[{
+		"fileName": "file.pdf",
+		"validationTime": 1741609800,
+		"signaturesCount": "two",
+		"validSignaturesCount": -1,
+		"signatures": [{
+			"indication": "TOTAL_PASSED",
+			"signingTime": "yesterday",
+			"timestamps": [{"productionTime": null, "indication": "PASSED"}]
+		}]
+	}]}`))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.HasLen(res.Documents, 1))
+
+	// Invalid values are left empty
+	doc := res.Documents[0]
+	qt.Check(t, qt.IsNil(doc.ValidationTime))
+	qt.Check(t, qt.Equals(doc.SignaturesCount, 1))
+	qt.Check(t, qt.Equals(doc.ValidSignaturesCount, 1))
+	qt.Assert(t, qt.HasLen(doc.Signatures, 1))
+	qt.Check(t, qt.IsNil(doc.Signatures[0].SigningTime))
+
+	// Invalid values are logged, empty values are not
+	fields := map[string]string{}
+
+	for _, entry := range logs.All() {
+		ctx := entry.ContextMap()
+		qt.Check(t, qt.Equals(ctx["fileName"], any("file.pdf")))
+
+		fields[ctx["field"].(string)] = ctx["value"].(string)
+	}
+
+	qt.Check(t, qt.DeepEquals(fields, map[string]string{
+		"validationTime":       "1741609800",
+		"signaturesCount":      `"two"`,
+		"validSignaturesCount": "-1",
+		"signingTime":          "yesterday",
+	}))
+}
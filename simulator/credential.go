@@ -0,0 +1,362 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+//nolint:tagliatelle
+package simulator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/oklog/ulid/v2"
+	"github.com/valyala/fasthttp"
+)
+
+const grantTypePreAuthorizedCode = "urn:ietf:params:oauth:grant-type:pre-authorized_code"
+
+// Offer is the credential offer created for the person.
+type Offer struct {
+	// ID of the credential offer.
+	ID string
+	// URL is the credential offer URL that is shown to the user as QR code.
+	URL string
+	// TXCode is the transaction code the user has to enter or empty if not required.
+	TXCode string
+}
+
+// Credential is the credential issued to the wallet.
+type Credential struct {
+	// ConfigurationID is the credential configuration ID.
+	ConfigurationID string
+	// Credentials are the issued credentials in the configuration format.
+	Credentials []string
+	// TransactionID is set when credential issuance is deferred.
+	TransactionID string
+	// NotificationID is the ID used to notify issuer about the credential being accepted.
+	NotificationID string
+}
+
+// CreateOffer creates credential offer of the credential type for the person identified by the access token.
+func (w *Wallet) CreateOffer(credentialType string) (*Offer, error) {
+	if w.opts.AccessToken == "" {
+		return nil, errors.New("access token is required to create credential offer")
+	}
+
+	res := models.GenerateCredentialOffer{}
+
+	if err := w.do(request{
+		method: fasthttp.MethodPost,
+		url:    w.opts.URL + "/1.0/" + url.PathEscape(credentialType) + "?format=json",
+		header: bearer(w.opts.AccessToken),
+	}, &res); err != nil {
+		return nil, fmt.Errorf("failed to create credential offer: %w", err)
+	}
+
+	offer := &Offer{
+		ID:  res.OfferID,
+		URL: res.URLData,
+	}
+
+	if res.TXCode != nil {
+		offer.TXCode = strconv.Itoa(*res.TXCode)
+	}
+
+	w.logf("Created %s credential offer %s", credentialType, offer.URL)
+
+	return offer, nil
+}
+
+// resolveOffer returns credential offer passed by value or by reference in the credential offer URL.
+func (w *Wallet) resolveOffer(offerURL string) (*models.CredentialOffer, error) {
+	u, err := url.Parse(offerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential offer URL: %w", err)
+	}
+
+	offer := &models.CredentialOffer{}
+
+	if v := u.Query().Get("credential_offer"); v != "" {
+		if err := json.Unmarshal([]byte(v), offer); err != nil {
+			return nil, fmt.Errorf("invalid credential offer: %w", err)
+		}
+
+		return offer, nil
+	}
+
+	uri := u.Query().Get("credential_offer_uri")
+	if uri == "" {
+		return nil, errors.New("credential offer URL must contain credential_offer or credential_offer_uri")
+	}
+
+	if err := w.do(request{
+		method: fasthttp.MethodGet,
+		url:    uri,
+	}, offer); err != nil {
+		return nil, fmt.Errorf("failed to get credential offer: %w", err)
+	}
+
+	return offer, nil
+}
+
+// Redeem redeems credential offer and requests all offered credentials.
+//
+// Transaction code is required if the credential offer requires it. Issued credentials are
+// acknowledged to the issuer using notification endpoint.
+func (w *Wallet) Redeem(offerURL, txCode string) ([]*Credential, error) {
+	offer, err := w.resolveOffer(offerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	grant := offer.Grants.PreAuthorizedCode
+	if grant.PreAuthorizedCode == "" {
+		return nil, errors.New("credential offer does not contain pre-authorized code")
+	}
+
+	if grant.TXCode != nil && txCode == "" {
+		return nil, errors.New("credential offer requires transaction code")
+	}
+
+	ids, err := configurationIDs(offer.CredentialConfigurationIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":          {grantTypePreAuthorizedCode},
+		"client_id":           {w.opts.ClientID},
+		"pre-authorized_code": {grant.PreAuthorizedCode},
+	}
+
+	if txCode != "" {
+		form.Set("tx_code", txCode)
+	}
+
+	tok := struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+	}{}
+
+	header, err := w.clientAttestation()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.do(request{
+		method: fasthttp.MethodPost,
+		url:    w.opts.URL + "/token",
+		header: header,
+		form:   form,
+	}, &tok); err != nil {
+		return nil, fmt.Errorf("failed to redeem pre-authorized code: %w", err)
+	}
+
+	if tok.AccessToken == "" {
+		return nil, errors.New("access token not returned")
+	}
+
+	w.logf("Redeemed pre-authorized code of the credential offer for %v", ids)
+
+	creds := make([]*Credential, 0, len(ids))
+
+	for _, id := range ids {
+		cred, err := w.credential(tok.AccessToken, id)
+		if err != nil {
+			return nil, err
+		}
+
+		creds = append(creds, cred)
+	}
+
+	return creds, nil
+}
+
+// credential requests credential of the configuration with the key proof and notifies issuer when it is issued.
+func (w *Wallet) credential(accessToken, configurationID string) (*Credential, error) {
+	nonce, err := w.Nonce()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := w.proof(key, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	header := bearer(accessToken)
+	if w.instance != nil && w.instance.attestation != "" {
+		header["OAuth-Client-Attestation"] = w.instance.attestation
+	}
+
+	res := struct {
+		Credentials []struct {
+			Credential any `json:"credential"`
+		} `json:"credentials"`
+		TransactionID  string `json:"transaction_id"`
+		NotificationID string `json:"notification_id"`
+	}{}
+
+	if err := w.do(request{
+		method: fasthttp.MethodPost,
+		url:    w.opts.URL + "/credential",
+		header: header,
+		json: map[string]any{
+			"credential_configuration_id": configurationID,
+			"proofs": map[string][]string{
+				"jwt": {proof},
+			},
+		},
+	}, &res); err != nil {
+		return nil, fmt.Errorf("failed to get %s credential: %w", configurationID, err)
+	}
+
+	cred := &Credential{
+		ConfigurationID: configurationID,
+		TransactionID:   res.TransactionID,
+		NotificationID:  res.NotificationID,
+	}
+
+	for _, c := range res.Credentials {
+		switch v := c.Credential.(type) {
+		case string:
+			cred.Credentials = append(cred.Credentials, v)
+		default:
+			buf, _ := json.Marshal(v)
+			cred.Credentials = append(cred.Credentials, string(buf))
+		}
+	}
+
+	if cred.TransactionID != "" {
+		w.logf("Credential %s issuance deferred with transaction %s", configurationID, cred.TransactionID)
+
+		return cred, nil
+	}
+
+	if len(cred.Credentials) == 0 {
+		return nil, fmt.Errorf("no %s credentials returned", configurationID)
+	}
+
+	w.logf("Received %d %s credential(s)", len(cred.Credentials), configurationID)
+
+	if cred.NotificationID == "" {
+		return cred, nil
+	}
+
+	if err := w.do(request{
+		method: fasthttp.MethodPost,
+		url:    w.opts.URL + "/notification",
+		header: bearer(accessToken),
+		json: map[string]string{
+			"notification_id": cred.NotificationID,
+			"event":           "credential_accepted",
+		},
+		status: fasthttp.StatusNoContent,
+	}, nil); err != nil {
+		return nil, fmt.Errorf("failed to notify about accepted %s credential: %w", configurationID, err)
+	}
+
+	return cred, nil
+}
+
+// proof returns key proof JWT for the credential request.
+func (w *Wallet) proof(key *ecdsa.PrivateKey, nonce string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss":   w.opts.ClientID,
+		"aud":   w.opts.PublicURL,
+		"iat":   time.Now().Unix(),
+		"nonce": nonce,
+	})
+	token.Header["typ"] = "openid4vci-proof+jwt"
+	token.Header["jwk"] = publicJWK(&key.PublicKey)
+
+	proof, err := token.SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign key proof: %w", err)
+	}
+
+	return proof, nil
+}
+
+// clientAttestation returns headers to authenticate the wallet instance with the wallet attestation
+// and its proof of possession. No headers are returned if the wallet has no attestation.
+func (w *Wallet) clientAttestation() (map[string]string, error) {
+	if w.instance == nil || w.instance.attestation == "" {
+		return nil, nil
+	}
+
+	now := time.Now()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": w.opts.ClientID,
+		"aud": w.opts.PublicURL,
+		"jti": ulid.Make().String(),
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	})
+	token.Header["typ"] = "oauth-client-attestation-pop+jwt"
+
+	pop, err := token.SignedString(w.instance.attestationKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign client attestation proof of possession: %w", err)
+	}
+
+	return map[string]string{
+		"OAuth-Client-Attestation":     w.instance.attestation,
+		"OAuth-Client-Attestation-PoP": pop,
+	}, nil
+}
+
+// configurationIDs returns credential configuration IDs of the credential offer.
+func configurationIDs(v any) ([]string, error) {
+	list, ok := v.([]any)
+	if !ok || len(list) == 0 {
+		return nil, errors.New("credential offer does not contain credential configurations")
+	}
+
+	ids := make([]string, 0, len(list))
+
+	for _, id := range list {
+		s, ok := id.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid credential configuration ID: %v", id)
+		}
+
+		ids = append(ids, s)
+	}
+
+	return ids, nil
+}
+
+func generateKey() (*ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	return key, nil
+}
+
+// publicJWK returns JWK of the P-256 public key.
+func publicJWK(key *ecdsa.PublicKey) map[string]any {
+	return map[string]any{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+//nolint:tagliatelle
+package simulator
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/attestation/attestationtest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/valyala/fasthttp"
+)
+
+// instance is the registered wallet instance.
+type instance struct {
+	// key is the attested hardware key of the wallet instance
+	key *ecdsa.PrivateKey
+	// tag is the hardware key tag of the attested key
+	tag string
+	// id is the wallet instance ID
+	id string
+	// attestation is the wallet attestation issued by the wallet API
+	attestation string
+	// attestationKey is the key the wallet attestation is bound to
+	attestationKey *ecdsa.PrivateKey
+}
+
+// InstanceID returns wallet instance ID or empty string if wallet is not registered.
+func (w *Wallet) InstanceID() string {
+	if w.instance == nil {
+		return ""
+	}
+
+	return w.instance.id
+}
+
+// Register registers new wallet instance with the synthesized key attestation.
+func (w *Wallet) Register() error {
+	nonce, err := w.Nonce()
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(nonce)
+	if err != nil {
+		return fmt.Errorf("failed to decode nonce: %w", err)
+	}
+
+	// Mobile wallet uses hash of the nonce as attestation challenge
+	challenge := sha256.Sum256(buf)
+
+	var att *attestationtest.Attestation
+
+	switch w.opts.Platform {
+	case PlatformIOS:
+		att, err = w.opts.CA.Apple(attestationtest.AppleOptions{
+			Challenge: challenge[:],
+		})
+	default:
+		att, err = w.opts.CA.Android(attestationtest.AndroidOptions{
+			Challenge: challenge[:],
+		})
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to generate key attestation: %w", err)
+	}
+
+	w.logf("Generated %s key attestation with hardware key tag %s", w.opts.Platform, att.HardwareKeyTag)
+
+	if err := w.do(request{
+		method: fasthttp.MethodPost,
+		url:    w.opts.URL + "/instance",
+		json: struct {
+			Challenge      string `json:"challenge"`
+			KeyAttestation string `json:"key_attestation"`
+			HardwareKeyTag string `json:"hardware_key_tag"`
+		}{
+			Challenge:      nonce,
+			KeyAttestation: att.Attestation,
+			HardwareKeyTag: att.HardwareKeyTag,
+		},
+		status: fasthttp.StatusCreated,
+	}, nil); err != nil {
+		return fmt.Errorf("failed to register wallet instance: %w", err)
+	}
+
+	id, err := url.JoinPath(w.opts.PublicURL, "instance", att.HardwareKeyTag)
+	if err != nil {
+		return fmt.Errorf("failed to generate instance ID: %w", err)
+	}
+
+	w.instance = &instance{
+		key: att.Key,
+		tag: att.HardwareKeyTag,
+		id:  id,
+	}
+
+	w.logf("Registered wallet instance %s", id)
+
+	return nil
+}
+
+// WalletAttestation requests wallet attestation using JWT bearer assertion signed by the wallet instance key.
+// Returned attestation is bound to the new ephemeral key of the wallet.
+func (w *Wallet) WalletAttestation() (string, error) {
+	if w.instance == nil {
+		return "", errors.New("wallet instance is not registered")
+	}
+
+	key, err := generateKey()
+	if err != nil {
+		return "", err
+	}
+
+	tag, err := attestationtest.HardwareKeyTag(&key.PublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"sub":              w.opts.PublicURL,
+		"iss":              w.instance.id,
+		"type":             "WalletInstanceAttestationRequest",
+		"hardware_key_tag": tag,
+		"cnf": map[string]any{
+			"jwk": publicJWK(&key.PublicKey),
+		},
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	})
+	token.Header["typ"] = "var+jwt"
+	token.Header["kid"] = w.instance.tag
+
+	assertion, err := token.SignedString(w.instance.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign assertion: %w", err)
+	}
+
+	res := struct {
+		WalletAttestations []struct {
+			Format            string `json:"format"`
+			WalletAttestation string `json:"wallet_attestation"`
+		} `json:"wallet_attestations"`
+	}{}
+
+	if err := w.do(request{
+		method: fasthttp.MethodPost,
+		url:    w.opts.URL + "/token",
+		form: url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		},
+	}, &res); err != nil {
+		return "", fmt.Errorf("failed to get wallet attestation: %w", err)
+	}
+
+	for _, att := range res.WalletAttestations {
+		if att.Format == "jwt" && att.WalletAttestation != "" {
+			w.instance.attestation = att.WalletAttestation
+			w.instance.attestationKey = key
+
+			w.logf("Received wallet attestation for key %s", tag)
+
+			return att.WalletAttestation, nil
+		}
+	}
+
+	return "", errors.New("wallet attestation in JWT format not returned")
+}
@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package simulator implements software wallet that goes through the wallet instance registration
+// and credential issuance flow against the wallet API the same way as the mobile wallet does.
+//
+// Key attestations are synthesized using the test root certificate authority, so the wallet API
+// must be configured to trust it with `ATTESTATION_TEST_ROOT_CA`.
+package simulator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/attestation/attestationtest"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Supported platforms of the simulated wallet.
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+)
+
+// DefaultClientID is the default OAuth client ID of the simulated wallet.
+const DefaultClientID = "edim-wallet-simulator"
+
+// Options configures the simulated wallet.
+type Options struct {
+	// URL is the base URL of the wallet API.
+	URL string
+	// PublicURL is the public URL of the wallet API as configured by `WALLET_API_PUBLIC_URL`.
+	// It is used as token audience and to build wallet instance ID. Defaults to URL.
+	PublicURL string
+	// CA is the test root certificate authority trusted by the wallet API. Required.
+	CA *attestationtest.CA
+	// Platform of the simulated device, PlatformAndroid or PlatformIOS. Defaults to PlatformAndroid.
+	Platform string
+	// AccessToken is IDAuth access token of the person. Wallet instance is registered anonymously
+	// and credential offers can not be created if empty.
+	AccessToken string
+	// ClientID is the OAuth client ID used for the token request. Defaults to DefaultClientID.
+	ClientID string
+	// Timeout of a single request. Defaults to 30 seconds.
+	Timeout time.Duration
+	// Logf logs progress of the simulation steps if set.
+	Logf func(format string, args ...any)
+}
+
+// StatusError is returned when the wallet API responds with unexpected status code.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status code %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// Wallet is the simulated wallet.
+type Wallet struct {
+	opts   Options
+	client *fasthttp.Client
+
+	instance *instance
+}
+
+// New creates new simulated wallet.
+func New(opts Options) (*Wallet, error) {
+	if opts.URL == "" {
+		return nil, errors.New("wallet API URL is required")
+	}
+
+	if opts.CA == nil {
+		return nil, errors.New("test root CA is required")
+	}
+
+	opts.URL = strings.TrimSuffix(opts.URL, "/")
+
+	if opts.PublicURL == "" {
+		opts.PublicURL = opts.URL
+	}
+
+	opts.PublicURL = strings.TrimSuffix(opts.PublicURL, "/")
+
+	switch opts.Platform {
+	case "":
+		opts.Platform = PlatformAndroid
+	case PlatformAndroid, PlatformIOS:
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", opts.Platform)
+	}
+
+	if opts.ClientID == "" {
+		opts.ClientID = DefaultClientID
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	return &Wallet{
+		opts: opts,
+		client: &fasthttp.Client{
+			Name: "edim-wallet-simulator",
+		},
+	}, nil
+}
+
+func (w *Wallet) logf(format string, args ...any) {
+	if w.opts.Logf != nil {
+		w.opts.Logf(format, args...)
+	}
+}
+
+// request describes the wallet API request.
+type request struct {
+	method string
+	url    string
+	header map[string]string
+	json   any
+	form   url.Values
+	// status is the expected response status code
+	status int
+}
+
+// do sends request to the wallet API and decodes JSON response into the result if not nil.
+func (w *Wallet) do(r request, result any) error {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(r.url)
+	req.Header.SetMethod(r.method)
+	req.Header.Set(fasthttp.HeaderAccept, "application/json")
+
+	for k, v := range r.header {
+		req.Header.Set(k, v)
+	}
+
+	switch {
+	case r.json != nil:
+		buf, err := json.Marshal(r.json)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+
+		req.Header.SetContentType("application/json")
+		req.SetBody(buf)
+	case r.form != nil:
+		req.Header.SetContentType("application/x-www-form-urlencoded")
+		req.SetBodyString(r.form.Encode())
+	}
+
+	if err := w.client.DoTimeout(req, resp, w.opts.Timeout); err != nil {
+		return fmt.Errorf("%s %s: %w", r.method, r.url, err)
+	}
+
+	status := r.status
+	if status == 0 {
+		status = fasthttp.StatusOK
+	}
+
+	if resp.StatusCode() != status {
+		return StatusError{
+			Method:     r.method,
+			URL:        r.url,
+			StatusCode: resp.StatusCode(),
+			Body:       string(resp.Body()),
+		}
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(resp.Body(), result); err != nil {
+		return fmt.Errorf("%s %s: failed to decode response: %w", r.method, r.url, err)
+	}
+
+	return nil
+}
+
+// bearer returns authorization header with the bearer token.
+func bearer(token string) map[string]string {
+	return map[string]string{
+		fasthttp.HeaderAuthorization: "Bearer " + token,
+	}
+}
+
+// Nonce requests new nonce from the wallet API. Nonce is bound to the person if access token is set.
+func (w *Wallet) Nonce() (string, error) {
+	r := request{
+		method: fasthttp.MethodPost,
+		url:    w.opts.URL + "/nonce",
+	}
+
+	if w.opts.AccessToken != "" {
+		r.header = bearer(w.opts.AccessToken)
+	}
+
+	res := struct {
+		Nonce string `json:"c_nonce"` //nolint:tagliatelle
+	}{}
+
+	if err := w.do(r, &res); err != nil {
+		return "", err
+	}
+
+	if res.Nonce == "" {
+		return "", errors.New("empty nonce returned")
+	}
+
+	return res.Nonce, nil
+}
@@ -0,0 +1,394 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+//nolint:tagliatelle
+package simulator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"git.zzdats.lv/edim/api-wallet/attestation"
+	"git.zzdats.lv/edim/api-wallet/attestation/attestationtest"
+
+	"azugo.io/azugo"
+	"github.com/go-quicktest/qt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/valyala/fasthttp"
+)
+
+// walletAPI is the minimal fake of the wallet API that verifies requests of the simulated wallet.
+type walletAPI struct {
+	t   *testing.T
+	url string
+	att *attestation.Service
+
+	mu        sync.Mutex
+	counter   int
+	nonces    map[string]bool
+	keys      map[string]string
+	notified  []string
+	offerURI  string
+	txCode    string
+	preAuth   string
+	accessTok string
+}
+
+func newWalletAPI(t *testing.T, ca *attestationtest.CA) *walletAPI {
+	t.Helper()
+
+	att, err := attestation.New(azugo.NewTestApp().App,
+		attestation.WithAndroidRootCA(ca.CertificatePEM()),
+		attestation.WithAppleRootCA(ca.CertificatePEM()))
+	qt.Assert(t, qt.IsNil(err))
+
+	api := &walletAPI{
+		t:         t,
+		att:       att,
+		nonces:    make(map[string]bool),
+		keys:      make(map[string]string),
+		txCode:    "123456",
+		preAuth:   "pre-auth-code",
+		accessTok: "access-token",
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	qt.Assert(t, qt.IsNil(err))
+
+	srv := &fasthttp.Server{Handler: api.handle}
+
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+
+	t.Cleanup(func() {
+		_ = srv.Shutdown()
+	})
+
+	api.url = "http://" + ln.Addr().String()
+	api.offerURI = api.url + "/credential-offer/offer-1"
+
+	return api
+}
+
+func (a *walletAPI) fail(ctx *fasthttp.RequestCtx, err error) {
+	a.t.Errorf("%s %s: %v", ctx.Method(), ctx.Path(), err)
+	ctx.SetStatusCode(fasthttp.StatusBadRequest)
+}
+
+func (a *walletAPI) json(ctx *fasthttp.RequestCtx, v any) {
+	buf, _ := json.Marshal(v)
+
+	ctx.SetContentType("application/json")
+	ctx.SetBody(buf)
+}
+
+func (a *walletAPI) handle(ctx *fasthttp.RequestCtx) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch string(ctx.Path()) {
+	case "/nonce":
+		a.counter++
+
+		nonce := base64.RawURLEncoding.EncodeToString([]byte("nonce-" + strconv.Itoa(a.counter)))
+		a.nonces[nonce] = true
+
+		a.json(ctx, map[string]string{"c_nonce": nonce})
+	case "/instance":
+		a.instance(ctx)
+	case "/token":
+		a.token(ctx)
+	case "/1.0/pid":
+		if string(ctx.Request.Header.Peek(fasthttp.HeaderAuthorization)) != "Bearer 32345678901" {
+			ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+
+			return
+		}
+
+		a.json(ctx, map[string]any{
+			"offerId": "offer-1",
+			"tx_code": 123456,
+			"urlData": "openid-credential-offer://?" + url.Values{"credential_offer_uri": {a.offerURI}}.Encode(),
+		})
+	case "/credential-offer/offer-1":
+		a.json(ctx, map[string]any{
+			"credential_issuer":            a.url,
+			"credential_configuration_ids": []string{"eu.europa.ec.eudi.pid_mdoc"},
+			"grants": map[string]any{
+				"urn:ietf:params:oauth:grant-type:pre-authorized_code": map[string]any{
+					"pre-authorized_code": a.preAuth,
+					"tx_code":             map[string]any{"length": 6, "input_mode": "numeric"},
+				},
+			},
+		})
+	case "/credential":
+		a.credential(ctx)
+	case "/notification":
+		req := struct {
+			NotificationID string `json:"notification_id"`
+			Event          string `json:"event"`
+		}{}
+
+		if err := json.Unmarshal(ctx.PostBody(), &req); err != nil || req.Event != "credential_accepted" {
+			a.fail(ctx, errors.New("invalid notification"))
+
+			return
+		}
+
+		a.notified = append(a.notified, req.NotificationID)
+
+		ctx.SetStatusCode(fasthttp.StatusNoContent)
+	default:
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+	}
+}
+
+func (a *walletAPI) useNonce(nonce string) error {
+	if !a.nonces[nonce] {
+		return errors.New("unknown nonce")
+	}
+
+	delete(a.nonces, nonce)
+
+	return nil
+}
+
+func (a *walletAPI) instance(ctx *fasthttp.RequestCtx) {
+	req := struct {
+		Challenge      string `json:"challenge"`
+		KeyAttestation string `json:"key_attestation"`
+		HardwareKeyTag string `json:"hardware_key_tag"`
+	}{}
+
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		a.fail(ctx, err)
+
+		return
+	}
+
+	if err := a.useNonce(req.Challenge); err != nil {
+		a.fail(ctx, err)
+
+		return
+	}
+
+	buf, _ := base64.RawURLEncoding.DecodeString(req.Challenge)
+	challenge := sha256.Sum256(buf)
+
+	res, err := a.att.Verify(req.KeyAttestation, challenge[:], req.HardwareKeyTag)
+	if err != nil {
+		a.fail(ctx, err)
+
+		return
+	}
+
+	a.keys[res.HardwareKeyTag] = res.PublicKey
+
+	ctx.SetStatusCode(fasthttp.StatusCreated)
+}
+
+func (a *walletAPI) token(ctx *fasthttp.RequestCtx) {
+	args := ctx.PostArgs()
+
+	switch string(args.Peek("grant_type")) {
+	case "urn:ietf:params:oauth:grant-type:jwt-bearer":
+		tok, err := jwt.Parse(string(args.Peek("assertion")), func(t *jwt.Token) (any, error) {
+			kid, _ := t.Header["kid"].(string)
+
+			block, _ := pem.Decode([]byte(a.keys[kid]))
+			if block == nil {
+				return nil, errors.New("public key not found")
+			}
+
+			return x509.ParsePKIXPublicKey(block.Bytes)
+		}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithExpirationRequired(), jwt.WithSubject(a.url))
+		if err != nil {
+			a.fail(ctx, err)
+
+			return
+		}
+
+		if iss, _ := tok.Claims.GetIssuer(); !strings.HasPrefix(iss, a.url+"/instance/") {
+			a.fail(ctx, errors.New("invalid issuer: "+iss))
+
+			return
+		}
+
+		a.json(ctx, map[string]any{
+			"wallet_attestations": []map[string]string{
+				{"format": "jwt", "wallet_attestation": "wallet-attestation"},
+			},
+		})
+	case "urn:ietf:params:oauth:grant-type:pre-authorized_code":
+		if string(args.Peek("pre-authorized_code")) != a.preAuth || string(args.Peek("tx_code")) != a.txCode {
+			a.fail(ctx, errors.New("invalid grant"))
+
+			return
+		}
+
+		if string(ctx.Request.Header.Peek("OAuth-Client-Attestation")) != "wallet-attestation" ||
+			len(ctx.Request.Header.Peek("OAuth-Client-Attestation-PoP")) == 0 {
+			a.fail(ctx, errors.New("missing client attestation"))
+
+			return
+		}
+
+		a.json(ctx, map[string]any{
+			"access_token": a.accessTok,
+			"token_type":   "Bearer",
+		})
+	default:
+		a.fail(ctx, errors.New("unsupported grant type"))
+	}
+}
+
+func (a *walletAPI) credential(ctx *fasthttp.RequestCtx) {
+	if string(ctx.Request.Header.Peek(fasthttp.HeaderAuthorization)) != "Bearer "+a.accessTok {
+		ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+
+		return
+	}
+
+	if string(ctx.Request.Header.Peek("OAuth-Client-Attestation")) != "wallet-attestation" {
+		a.fail(ctx, errors.New("missing client attestation"))
+
+		return
+	}
+
+	req := struct {
+		CredentialConfigurationID string              `json:"credential_configuration_id"`
+		Proofs                    map[string][]string `json:"proofs"`
+	}{}
+
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil || len(req.Proofs["jwt"]) != 1 {
+		a.fail(ctx, errors.New("invalid credential request"))
+
+		return
+	}
+
+	tok, err := jwt.Parse(req.Proofs["jwt"][0], func(t *jwt.Token) (any, error) {
+		jwk, _ := t.Header["jwk"].(map[string]any)
+
+		return jwkPublicKey(jwk)
+	}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(a.url), jwt.WithIssuedAt())
+	if err != nil {
+		a.fail(ctx, err)
+
+		return
+	}
+
+	claims, _ := tok.Claims.(jwt.MapClaims)
+	nonce, _ := claims["nonce"].(string)
+
+	if err := a.useNonce(nonce); err != nil {
+		a.fail(ctx, err)
+
+		return
+	}
+
+	a.json(ctx, map[string]any{
+		"credentials":     []map[string]string{{"credential": "credential-" + req.CredentialConfigurationID}},
+		"notification_id": "notification-1",
+	})
+}
+
+func jwkPublicKey(jwk map[string]any) (*ecdsa.PublicKey, error) {
+	x, _ := jwk["x"].(string)
+	y, _ := jwk["y"].(string)
+
+	xb, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil {
+		return nil, err
+	}
+
+	yb, err := base64.RawURLEncoding.DecodeString(y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(xb),
+		Y:     new(big.Int).SetBytes(yb),
+	}, nil
+}
+
+func TestWallet(t *testing.T) {
+	ca, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	for _, platform := range []string{PlatformAndroid, PlatformIOS} {
+		t.Run(platform, func(t *testing.T) {
+			api := newWalletAPI(t, ca)
+
+			w, err := New(Options{
+				URL:         api.url,
+				CA:          ca,
+				Platform:    platform,
+				AccessToken: "32345678901",
+				Logf:        t.Logf,
+			})
+			qt.Assert(t, qt.IsNil(err))
+
+			qt.Assert(t, qt.IsNil(w.Register()))
+			qt.Check(t, qt.StringContains(w.InstanceID(), api.url+"/instance/"))
+
+			att, err := w.WalletAttestation()
+			qt.Assert(t, qt.IsNil(err))
+			qt.Check(t, qt.Equals(att, "wallet-attestation"))
+
+			offer, err := w.CreateOffer("pid")
+			qt.Assert(t, qt.IsNil(err))
+			qt.Check(t, qt.Equals(offer.ID, "offer-1"))
+			qt.Check(t, qt.Equals(offer.TXCode, "123456"))
+
+			_, err = w.Redeem(offer.URL, "")
+			qt.Check(t, qt.ErrorMatches(err, "credential offer requires transaction code"))
+
+			creds, err := w.Redeem(offer.URL, offer.TXCode)
+			qt.Assert(t, qt.IsNil(err))
+			qt.Assert(t, qt.HasLen(creds, 1))
+			qt.Check(t, qt.Equals(creds[0].ConfigurationID, "eu.europa.ec.eudi.pid_mdoc"))
+			qt.Check(t, qt.DeepEquals(creds[0].Credentials, []string{"credential-eu.europa.ec.eudi.pid_mdoc"}))
+			qt.Check(t, qt.DeepEquals(api.notified, []string{"notification-1"}))
+		})
+	}
+}
+
+func TestWalletStatusError(t *testing.T) {
+	ca, err := attestationtest.NewCA()
+	qt.Assert(t, qt.IsNil(err))
+
+	api := newWalletAPI(t, ca)
+
+	w, err := New(Options{
+		URL: api.url,
+		CA:  ca,
+	})
+	qt.Assert(t, qt.IsNil(err))
+
+	_, err = w.WalletAttestation()
+	qt.Check(t, qt.ErrorMatches(err, "wallet instance is not registered"))
+
+	w.opts.AccessToken = "unknown"
+
+	_, err = w.CreateOffer("pid")
+
+	var serr StatusError
+
+	qt.Assert(t, qt.IsTrue(errors.As(err, &serr)))
+	qt.Check(t, qt.Equals(serr.StatusCode, fasthttp.StatusUnauthorized))
+}
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package tasks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git.zzdats.lv/edim/api-wallet/mock/jsondbtest"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestInstanceCleanup(t *testing.T) {
+	store := jsondbtest.New()
+
+	now := time.Now()
+	store.Now = func() time.Time { return now }
+
+	store.AddInstance(jsondbtest.Instance{
+		HardwareKeyTag: "anonymous",
+		Person:         &jsondbtest.Person{Code: "anonymous"},
+		CreatedAt:      now.Add(-2 * time.Hour),
+	})
+	store.AddInstance(jsondbtest.Instance{
+		HardwareKeyTag: "person",
+		Person:         &jsondbtest.Person{Code: "32345678901"},
+		CreatedAt:      now.Add(-2 * time.Hour),
+	})
+
+	task := &walletInstanceCleanupTask{
+		db:        store,
+		olderThan: time.Hour,
+	}
+
+	err := task.instanceCleanup(context.Background())
+	qt.Assert(t, qt.IsNil(err))
+
+	_, ok := store.Instance("anonymous")
+	qt.Check(t, qt.IsFalse(ok))
+
+	_, ok = store.Instance("person")
+	qt.Check(t, qt.IsTrue(ok))
+}
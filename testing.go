@@ -3,13 +3,67 @@
 package wallet
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/go-quicktest/qt"
+	jsondb "github.com/nobid-lsp-latvia/lx-go-jsondb"
 )
 
+type testOptions struct {
+	store jsondb.Store
+}
+
+// TestOption configures the test application.
+type TestOption func(tb testing.TB, o *testOptions)
+
+// WithEnv overrides environment variable of the test application.
+func WithEnv(key, value string) TestOption {
+	return func(tb testing.TB, _ *testOptions) {
+		tb.Setenv(key, value)
+	}
+}
+
+// WithStore replaces PostgreSQL store of the test application, for example, with the in-memory store.
+func WithStore(store jsondb.Store) TestOption {
+	return func(_ testing.TB, o *testOptions) {
+		o.store = store
+	}
+}
+
+// WithIDAuthURL points test application to the fake IDAuth server.
+func WithIDAuthURL(url string) TestOption {
+	return WithEnv("IDAUTH_URL", url)
+}
+
 // TestApp for unit testing.
-func TestApp(tb testing.TB) *App {
+func TestApp(tb testing.TB, opts ...TestOption) *App {
+	tb.Helper()
+
+	setTestEnv(tb)
+
+	o := &testOptions{}
+
+	for _, opt := range opts {
+		opt(tb, o)
+	}
+
+	app, err := newApp(nil, "1.0.0-test", o.store)
+	qt.Assert(tb, qt.IsNil(err))
+
+	return app
+}
+
+// setTestEnv sets required configuration of the test application.
+func setTestEnv(tb testing.TB) {
 	tb.Helper()
 
 	tb.Setenv("METRICS_ENABLED", "false")
@@ -18,14 +72,55 @@ func TestApp(tb testing.TB) *App {
 	tb.Setenv("IDAUTH_CLIENT_ID", "edim.self-service.portal")
 	tb.Setenv("IDAUTH_CLIENT_SECRET", "secret")
 
+	tb.Setenv("POSTGRES_HOST", "localhost")
+	tb.Setenv("POSTGRES_PORT", "5432")
+	tb.Setenv("POSTGRES_USER", "wallet_public")
+	tb.Setenv("POSTGRES_PASSWORD", "secret")
+	tb.Setenv("POSTGRES_DB", "edim")
+
 	tb.Setenv("ISSUER_NONCE_SHARED_SECRET", "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI=")
+	tb.Setenv("ISSUER_TX_CODE_SECRET", "MjEwOTg3NjU0MzIxMDk4NzY1NDMyMTA5ODc2NTQzMjE=")
+	tb.Setenv("ISSUER_CERTIFICATE", testCertificate(tb))
 	tb.Setenv("ISSUER_API_URL", "http://issuer:5000")
 	tb.Setenv("MDL_API_URL", "http://mdl:5000")
 	tb.Setenv("RTU_API_URL", "http://rtu:5000")
 	tb.Setenv("FPRIS_API_URL", "http://fpris:5000")
 
-	app, err := New(nil, "1.0.0-test")
+	tb.Setenv("QR_API_DEEP_LINK", "openid-credential-offer")
+	tb.Setenv("WALLET_API_PUBLIC_URL", "http://wallet:8080")
+	tb.Setenv("SIMPLE_SIGN_SERVICE", "http://simple-sign:8080")
+	tb.Setenv("SIMPLE_SIGN_PUBLIC_URL", "http://simple-sign:8080")
+	tb.Setenv("SIMPLE_SIGN_API_KEY", "secret")
+}
+
+// testCertificate returns self-signed issuer signing certificate and its private key in PEM format.
+func testCertificate(tb testing.TB) string {
+	tb.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	qt.Assert(tb, qt.IsNil(err))
 
-	return app
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName: "EDIM Test Issuer",
+			Country:    []string{"LV"},
+		},
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter:  time.Now().Add(24 * time.Hour),
+		KeyUsage:  x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	qt.Assert(tb, qt.IsNil(err))
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	qt.Assert(tb, qt.IsNil(err))
+
+	var buf bytes.Buffer
+
+	qt.Assert(tb, qt.IsNil(pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	qt.Assert(tb, qt.IsNil(pem.Encode(&buf, &pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})))
+
+	return buf.String()
 }
@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package util
+
+import "testing"
+
+func FuzzDateUnmarshalJSON(f *testing.F) {
+	for _, seed := range []string{
+		`"2025-02-20"`,
+		`"2025-02-20T10:00:00Z"`,
+		`"2025-02-20T23:30:00.123456789-05:00"`,
+		`null`,
+		`""`,
+		`"2025-13-40"`,
+		`2025-02-20`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var d Date
+		if err := d.UnmarshalJSON(data); err != nil {
+			return
+		}
+
+		buf, err := d.MarshalJSON()
+		if err != nil {
+			t.Fatalf("failed to marshal date %s: %v", d.String(), err)
+		}
+
+		var rt Date
+		if err := rt.UnmarshalJSON(buf); err != nil {
+			t.Fatalf("failed to unmarshal marshaled date %s: %v", buf, err)
+		}
+
+		if rt.String() != d.String() {
+			t.Fatalf("date changed after round trip: %s != %s", rt.String(), d.String())
+		}
+	})
+}